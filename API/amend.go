@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	audit "go-receipt-processor/Audit"
+	receipt "go-receipt-processor/Receipt"
+	store "go-receipt-processor/Store"
+	"net/http"
+	systime "time"
+
+	"github.com/gorilla/mux"
+)
+
+type revisionResponse struct {
+	Receipt   receiptResponse `json:"receipt"`
+	Deleted   bool            `json:"deleted"`   // the version was deleted, rather than replaced by a newer one
+	RevisedAt string          `json:"revisedAt"` // when the version was replaced or deleted, in RFC 3339 format
+}
+
+type historyResponse struct {
+	Id        string             `json:"id"`
+	Current   *receiptResponse   `json:"current,omitempty"` // omitted once the receipt has been deleted
+	Revisions []revisionResponse `json:"revisions"`         // the earlier versions, oldest first
+}
+
+// Writes the response for a receipt that could not be amended, deleted or looked up in the store
+func writeStoreError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, store.ErrReceiptNotFound) {
+		http.Error(w, "No receipt found for that id", http.StatusNotFound)
+		return
+	}
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// Checks the receipt for the id can be changed by the user the request was made by, which is only the user who submitted it, writing the error response and returning false if it can not.
+// Receipts submitted without a user can be changed by anyone.
+func (s *Server) authorizeReceiptChange(w http.ResponseWriter, r *http.Request, id string) bool {
+	userId, err := requestUserId(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	storedReceipt, err := s.store.Get(id)
+	if err != nil {
+		writeStoreError(w, err, "The receipt could not be retrieved")
+		return false
+	}
+	owner, err := s.receiptOwner(storedReceipt)
+	if err != nil {
+		http.Error(w, "The receipt could not be retrieved", http.StatusInternalServerError)
+		return false
+	}
+	if owner != "" && owner != userId {
+		http.Error(w, "The receipt was submitted by a different user", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// On PUT HTTP Request, replaces the stored receipt for the ID given within the request with the corrected receipt in the body.
+// Only the user who submitted the receipt can amend it.
+// The corrected receipt is validated and scored the same way as a newly processed one, and the version it replaces is kept in the receipt's history.
+// The points credited to the user who submitted the receipt are adjusted to its new points.
+// It then outputs the amended receipt, along with its new points and version, in JSON format.
+func (s *Server) amendReceipt(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if !s.authorizeReceiptChange(w, r, id) {
+		return
+	}
+	var unparsedReceipt receipt.UnparsedReceipt
+	err := json.NewDecoder(r.Body).Decode(&unparsedReceipt)
+	if err != nil {
+		writeValidationError(w, collectDecodingProblems(err))
+		return
+	}
+	scoredReceipt, problems, err := s.scoreReceipt(id, unparsedReceipt)
+	if err != nil {
+		http.Error(w, getInternalErrorMessage(err), http.StatusInternalServerError)
+		return
+	}
+	if len(problems) > 0 {
+		writeValidationError(w, problems)
+		return
+	}
+	previous, amended, err := s.store.Replace(scoredReceipt)
+	if err != nil {
+		writeStoreError(w, err, "The receipt could not be stored")
+		return
+	}
+	s.rollups.Remove(previous)
+	s.rollups.Add(amended)
+	err = s.recordAudit(requestOrigin(r), audit.ActionAmend, id, audit.ReceiptHash(previous), audit.ReceiptHash(amended))
+	if err != nil {
+		http.Error(w, "The receipt was amended but could not be audited", http.StatusInternalServerError)
+		return
+	}
+	err = s.adjustReceiptPoints(id, amended.Points, "receipt amended")
+	if err != nil {
+		http.Error(w, "The receipt was amended but its points could not be adjusted", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(newReceiptResponse(amended))
+	if err != nil {
+		http.Error(w, "The receipt could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+}
+
+// On DELETE HTTP Request, removes the stored receipt for the ID given within the request, keeping it in the receipt's history.
+// Only the user who submitted the receipt can delete it, and the points credited to them are taken back.
+func (s *Server) deleteReceipt(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if !s.authorizeReceiptChange(w, r, id) {
+		return
+	}
+	deleted, err := s.store.Delete(id)
+	if err != nil {
+		writeStoreError(w, err, "The receipt could not be deleted")
+		return
+	}
+	s.rollups.Remove(deleted)
+	err = s.recordAudit(requestOrigin(r), audit.ActionDelete, id, audit.ReceiptHash(deleted), "")
+	if err != nil {
+		http.Error(w, "The receipt was deleted but could not be audited", http.StatusInternalServerError)
+		return
+	}
+	err = s.adjustReceiptPoints(id, 0, "receipt deleted")
+	if err != nil {
+		http.Error(w, "The receipt was deleted but its points could not be adjusted", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// On GET HTTP Request, outputs the current version of the receipt for the ID given within the request along with every earlier version, in JSON format.
+// The history of a deleted receipt can still be retrieved, ending with the version that was deleted.
+func (s *Server) getReceiptHistory(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	revisions, err := s.store.History(id)
+	if err != nil {
+		writeStoreError(w, err, "The receipt history could not be retrieved")
+		return
+	}
+	response := historyResponse{Id: id, Revisions: make([]revisionResponse, 0, len(revisions))}
+	for _, revision := range revisions {
+		response.Revisions = append(response.Revisions, revisionResponse{
+			Receipt:   newReceiptResponse(revision.StoredReceipt),
+			Deleted:   revision.Deleted,
+			RevisedAt: revision.RevisedAt.Format(systime.RFC3339Nano),
+		})
+	}
+	current, err := s.store.Get(id)
+	if err == nil {
+		currentResponse := newReceiptResponse(current)
+		response.Current = &currentResponse
+	} else if !errors.Is(err, store.ErrReceiptNotFound) {
+		http.Error(w, "The receipt history could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		http.Error(w, "The receipt history could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	utils "go-receipt-processor/TestingUtils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	originalReceipt  = `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "6.49", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}]}`
+	correctedReceipt = `{"retailer": "Walgreens", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "6.49", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}]}`
+)
+
+func sendReceiptRequest(server *Server, method string, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(method, "http://localhost:8080/receipts/"+path, strings.NewReader(body))
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	server.ServeHTTP(w, r)
+	return w
+}
+
+func getHistory(t *testing.T, server *Server, id string) historyResponse {
+	w := sendReceiptRequest(server, "GET", id+"/history", "", nil)
+	if statusCode := w.Result().StatusCode; statusCode != 200 {
+		t.Fatalf("get history ( %s ): expected status code 200 got %d", id, statusCode)
+	}
+	var history historyResponse
+	json.NewDecoder(w.Body).Decode(&history)
+	return history
+}
+
+func TestAmendReceipt(t *testing.T) {
+	server := NewServer()
+	w := sendReceiptRequest(server, "POST", "process", originalReceipt, map[string]string{idempotencyKeyHeader: "key-1"})
+	var submitted idResponse
+	json.NewDecoder(w.Body).Decode(&submitted)
+
+	w = sendReceiptRequest(server, "PUT", submitted.Id, correctedReceipt, nil)
+	if statusCode := w.Result().StatusCode; statusCode != 200 {
+		t.Fatalf("amend receipt: expected status code 200 got %d ( %s )", statusCode, w.Body.String())
+	}
+	var amended receiptResponse
+	json.NewDecoder(w.Body).Decode(&amended)
+	amendedTestCase := utils.CreationTestingData[string, receiptResponse]{Argument: correctedReceipt, ExpectedResult: receiptResponse{
+		Id: submitted.Id, Retailer: "Walgreens", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Currency: "USD", Total: "6.49",
+		Items: []receiptItemResponse{{ShortDescription: "Mountain Dew 12PK", Price: "6.49"}}, Points: 15, RulesetVersion: 1, Version: 2,
+	}}
+	errCheck := amendedTestCase.CheckTestCase("amend receipt", amended, nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+
+	history := getHistory(t, server, submitted.Id)
+	if history.Current == nil || history.Current.Version != 2 || len(history.Revisions) != 1 {
+		t.Fatalf("get history: expected version 2 as current with a single earlier version got ( %+v )", history)
+	}
+	if original := history.Revisions[0]; original.Deleted || original.Receipt.Retailer != "Target" || original.Receipt.Points != 12 || original.Receipt.Version != 1 || original.RevisedAt == "" {
+		t.Fatalf("get history: expected the original receipt as version 1 got ( %+v )", original)
+	}
+
+	// the analytics only count the amended receipt
+	var retailers struct {
+		Retailers []analyticsRow `json:"retailers"`
+	}
+	getAnalytics(t, server, "retailers", &retailers)
+	if len(retailers.Retailers) != 1 || retailers.Retailers[0].Retailer != "Walgreens" || retailers.Retailers[0].Points != 15 {
+		t.Fatalf("retailer analytics: expected only the amended receipt got ( %+v )", retailers.Retailers)
+	}
+
+	// a retry of the original submission still finds the receipt, but the key can not be used for anything else
+	var testCases []utils.CreationTestingData[string, int] = []utils.CreationTestingData[string, int]{
+		{Argument: originalReceipt, ExpectedResult: 200},
+		{Argument: correctedReceipt, ExpectedResult: 200},
+		{Argument: analyticsReceipts[1], ExpectedResult: 422},
+	}
+	for _, testCase := range testCases {
+		w = sendReceiptRequest(server, "POST", "process", testCase.Argument, map[string]string{idempotencyKeyHeader: "key-1"})
+		var retried idResponse
+		json.NewDecoder(w.Body).Decode(&retried)
+		errCheck := testCase.CheckTestCase("retry amended receipt", w.Result().StatusCode, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+		if testCase.ExpectedResult == 200 && (retried.Id != submitted.Id || !retried.Duplicate) {
+			t.Fatalf("retry amended receipt: expected receipt ( %s ) as a duplicate got ( %+v )", submitted.Id, retried)
+		}
+	}
+}
+
+func TestAmendReceiptErrors(t *testing.T) {
+	server := NewServer()
+	w := sendReceiptRequest(server, "POST", "process", originalReceipt, nil)
+	var submitted idResponse
+	json.NewDecoder(w.Body).Decode(&submitted)
+
+	var testCases []utils.CreationTestingData[[2]string, int] = []utils.CreationTestingData[[2]string, int]{
+		{Argument: [2]string{submitted.Id, `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "7.00", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}]}`}, ExpectedResult: 400},
+		{Argument: [2]string{submitted.Id, `{"retailer": `}, ExpectedResult: 400},
+		{Argument: [2]string{"unknown", correctedReceipt}, ExpectedResult: 404},
+	}
+	for _, testCase := range testCases {
+		w = sendReceiptRequest(server, "PUT", testCase.Argument[0], testCase.Argument[1], nil)
+		errCheck := testCase.CheckTestCase("amend receipt", w.Result().StatusCode, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+	// a rejected amendment leaves the receipt as it was
+	if history := getHistory(t, server, submitted.Id); history.Current == nil || history.Current.Version != 1 || len(history.Revisions) != 0 {
+		t.Fatalf("get history: expected the receipt to be unchanged got ( %+v )", history)
+	}
+}
+
+func TestDeleteReceipt(t *testing.T) {
+	server := NewServer()
+	w := sendReceiptRequest(server, "POST", "process", originalReceipt, nil)
+	var submitted idResponse
+	json.NewDecoder(w.Body).Decode(&submitted)
+	sendReceiptRequest(server, "PUT", submitted.Id, correctedReceipt, nil)
+
+	var testCases []utils.CreationTestingData[[2]string, int] = []utils.CreationTestingData[[2]string, int]{
+		{Argument: [2]string{"DELETE", submitted.Id}, ExpectedResult: 204},
+		{Argument: [2]string{"GET", submitted.Id}, ExpectedResult: 404},
+		{Argument: [2]string{"DELETE", submitted.Id}, ExpectedResult: 404},
+		{Argument: [2]string{"PUT", submitted.Id}, ExpectedResult: 404},
+		{Argument: [2]string{"DELETE", "unknown"}, ExpectedResult: 404},
+		{Argument: [2]string{"GET", "unknown/history"}, ExpectedResult: 404},
+	}
+	for _, testCase := range testCases {
+		w = sendReceiptRequest(server, testCase.Argument[0], testCase.Argument[1], correctedReceipt, nil)
+		errCheck := testCase.CheckTestCase("delete receipt", w.Result().StatusCode, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	history := getHistory(t, server, submitted.Id)
+	if history.Current != nil || len(history.Revisions) != 2 || history.Revisions[0].Deleted || !history.Revisions[1].Deleted || history.Revisions[1].Receipt.Version != 2 {
+		t.Fatalf("get history: expected both versions ending with the deleted one got ( %+v )", history)
+	}
+	var days struct {
+		Days []analyticsRow `json:"days"`
+	}
+	getAnalytics(t, server, "days", &days)
+	if len(days.Days) != 0 {
+		t.Fatalf("day analytics: expected no receipts after the delete got ( %+v )", days.Days)
+	}
+
+	// once deleted the same content can be processed again as a new receipt
+	w = sendReceiptRequest(server, "POST", "process", originalReceipt, nil)
+	var resubmitted idResponse
+	json.NewDecoder(w.Body).Decode(&resubmitted)
+	if resubmitted.Duplicate || resubmitted.Id == submitted.Id {
+		t.Fatalf("process receipt: expected a new receipt after the delete got ( %+v )", resubmitted)
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	analytics "go-receipt-processor/Analytics"
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	store "go-receipt-processor/Store"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultTopItemsLimit = 10
+	maxTopItemsLimit     = 100
+)
+
+var (
+	ErrInvalidDateRange = errors.New("invalid date range")
+)
+
+// Sentinel errors found in analytics parameters, checked before the codes of submitted receipts so dates are reported the same way as they are on receipts
+var analyticsValidationCodes = []validationCode{
+	{"ErrInvalidDateRange", ErrInvalidDateRange},
+	{"ErrInvalidLimit", ErrInvalidLimit},
+}
+
+// Builds the rollups from the receipts already in the store, once when the server starts. Every receipt stored after that is added to them as it is processed.
+func buildRollups(receiptStore store.ReceiptStore) (*analytics.Rollups, error) {
+	rollups := analytics.NewRollups()
+	storedReceipts, err := receiptStore.List()
+	if err != nil {
+		return rollups, err
+	}
+	for _, storedReceipt := range storedReceipts {
+		rollups.Add(storedReceipt)
+	}
+	return rollups, nil
+}
+
+// Aggregates shared by every analytics response, with the spend in each currency formatted with as many decimal places as the currency has
+type totalsResponse struct {
+	Receipts int64                  `json:"receipts"`
+	Points   int64                  `json:"points"`
+	Items    int64                  `json:"items"`
+	Spend    map[string]json.Number `json:"spend"`
+}
+
+type retailerTotalsResponse struct {
+	Retailer string `json:"retailer"`
+	totalsResponse
+}
+
+type dayTotalsResponse struct {
+	Date string `json:"date"`
+	totalsResponse
+}
+
+type hourTotalsResponse struct {
+	Hour int `json:"hour"`
+	totalsResponse
+}
+
+type itemTotalsResponse struct {
+	Description string                 `json:"description"`
+	Quantity    int64                  `json:"quantity"`
+	Spend       map[string]json.Number `json:"spend"`
+}
+
+type basketResponse struct {
+	Receipts      int64                  `json:"receipts"`
+	AverageItems  float64                `json:"averageItems"`
+	AveragePoints float64                `json:"averagePoints"`
+	AverageSpend  map[string]json.Number `json:"averageSpend"`
+}
+
+func newSpendResponse(spend map[money.Currency]analytics.CurrencySpend) map[string]json.Number {
+	response := make(map[string]json.Number, len(spend))
+	for currency, currencySpend := range spend {
+		response[currency.Code()] = json.Number(currencySpend.Amount.Format(currency))
+	}
+	return response
+}
+
+func newTotalsResponse(totals analytics.Totals) totalsResponse {
+	return totalsResponse{Receipts: totals.Receipts, Points: totals.Points, Items: totals.Items, Spend: newSpendResponse(totals.Spend)}
+}
+
+// Reads the optional from and to dates the analytics are limited to, both of which are included in the range
+func parseDateRange(parameters url.Values) (*date.Date, *date.Date, []validationProblem) {
+	problems := []validationProblem{}
+	var dates [2]*date.Date
+	for i, name := range []string{"from", "to"} {
+		value := parameters.Get(name)
+		if value == "" {
+			continue
+		}
+		parsedDate, err := date.ParseDate(value, true)
+		if err != nil {
+			problems = append(problems, validationProblem{Field: name, Code: getValidationCode(err, analyticsValidationCodes...), Message: err.Error()})
+			continue
+		}
+		dates[i] = &parsedDate
+	}
+	from, to := dates[0], dates[1]
+	if from != nil && to != nil && to.Before(*from) {
+		err := fmt.Errorf("%w given \"%s\" to \"%s\" ( the end is before the start )", ErrInvalidDateRange, from, to)
+		problems = append(problems, validationProblem{Field: "to", Code: getValidationCode(err, analyticsValidationCodes...), Message: err.Error()})
+	}
+	return from, to, problems
+}
+
+// Checks the rollups were built from the store when the server started, and reads the date range, writing the error response and returning false if either failed
+func (s *Server) prepareAnalytics(w http.ResponseWriter, r *http.Request) (*date.Date, *date.Date, bool) {
+	if s.rollupsErr != nil {
+		http.Error(w, "The analytics could not be calculated", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	from, to, problems := parseDateRange(r.URL.Query())
+	if len(problems) > 0 {
+		writeProblems(w, "The analytics request is invalid", problems)
+		return nil, nil, false
+	}
+	return from, to, true
+}
+
+func writeAnalytics(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		http.Error(w, "The analytics could not be calculated", http.StatusInternalServerError)
+		return
+	}
+}
+
+// On GET HTTP Request, outputs the points and spend of each retailer over the receipts purchased within the date range, ordered from the most points to the least, in JSON format.
+func (s *Server) getRetailerAnalytics(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := s.prepareAnalytics(w, r)
+	if !ok {
+		return
+	}
+	retailers := s.rollups.Retailers(from, to)
+	response := struct {
+		Retailers []retailerTotalsResponse `json:"retailers"`
+	}{Retailers: make([]retailerTotalsResponse, 0, len(retailers))}
+	for _, retailer := range retailers {
+		response.Retailers = append(response.Retailers, retailerTotalsResponse{Retailer: retailer.Retailer, totalsResponse: newTotalsResponse(retailer.Totals)})
+	}
+	writeAnalytics(w, response)
+}
+
+// On GET HTTP Request, outputs the points and spend of each day with receipts within the date range, in order, in JSON format.
+func (s *Server) getDayAnalytics(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := s.prepareAnalytics(w, r)
+	if !ok {
+		return
+	}
+	days := s.rollups.Days(from, to)
+	response := struct {
+		Days []dayTotalsResponse `json:"days"`
+	}{Days: make([]dayTotalsResponse, 0, len(days))}
+	for _, day := range days {
+		response.Days = append(response.Days, dayTotalsResponse{Date: day.Date.String(), totalsResponse: newTotalsResponse(day.Totals)})
+	}
+	writeAnalytics(w, response)
+}
+
+// On GET HTTP Request, outputs the points and spend of every hour of the day over the receipts purchased within the date range, in JSON format.
+func (s *Server) getHourAnalytics(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := s.prepareAnalytics(w, r)
+	if !ok {
+		return
+	}
+	hours := s.rollups.Hours(from, to)
+	response := struct {
+		Hours []hourTotalsResponse `json:"hours"`
+	}{Hours: make([]hourTotalsResponse, 0, len(hours))}
+	for _, hour := range hours {
+		response.Hours = append(response.Hours, hourTotalsResponse{Hour: hour.Hour, totalsResponse: newTotalsResponse(hour.Totals)})
+	}
+	writeAnalytics(w, response)
+}
+
+// On GET HTTP Request, outputs the average number of items, points and spend of the receipts purchased within the date range, in JSON format.
+func (s *Server) getBasketAnalytics(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := s.prepareAnalytics(w, r)
+	if !ok {
+		return
+	}
+	basket := s.rollups.Basket(from, to)
+	response := basketResponse{
+		Receipts:      basket.Receipts,
+		AverageItems:  basket.AverageItems,
+		AveragePoints: basket.AveragePoints,
+		AverageSpend:  make(map[string]json.Number, len(basket.AverageSpend)),
+	}
+	for currency, amount := range basket.AverageSpend {
+		response.AverageSpend[currency.Code()] = json.Number(amount.Format(currency))
+	}
+	writeAnalytics(w, response)
+}
+
+// On GET HTTP Request, outputs the most bought items within the date range, up to the limit, in JSON format.
+// Items are grouped by their description ignoring case and extra spaces.
+func (s *Server) getItemAnalytics(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := s.prepareAnalytics(w, r)
+	if !ok {
+		return
+	}
+	limit := defaultTopItemsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsedLimit, err := strconv.Atoi(value)
+		if err != nil || parsedLimit < 1 || parsedLimit > maxTopItemsLimit {
+			err := fmt.Errorf("%w given \"%s\" ( expected a whole number from 1 to %d )", ErrInvalidLimit, value, maxTopItemsLimit)
+			writeProblems(w, "The analytics request is invalid", []validationProblem{{Field: "limit", Code: getValidationCode(err, analyticsValidationCodes...), Message: err.Error()}})
+			return
+		}
+		limit = parsedLimit
+	}
+	items := s.rollups.TopItems(from, to, limit)
+	response := struct {
+		Items []itemTotalsResponse `json:"items"`
+	}{Items: make([]itemTotalsResponse, 0, len(items))}
+	for _, item := range items {
+		response.Items = append(response.Items, itemTotalsResponse{Description: item.Description, Quantity: item.Quantity, Spend: newSpendResponse(item.Spend)})
+	}
+	writeAnalytics(w, response)
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	store "go-receipt-processor/Store"
+	utils "go-receipt-processor/TestingUtils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func getAnalytics(t *testing.T, server *Server, path string, response any) {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "http://localhost:8080/analytics/"+path, nil)
+	server.ServeHTTP(w, r)
+	if statusCode := w.Result().StatusCode; statusCode != 200 {
+		t.Fatalf("get analytics ( %s ): expected status code 200 got %d ( %s )", path, statusCode, w.Body.String())
+	}
+	err := json.NewDecoder(w.Body).Decode(response)
+	if err != nil {
+		t.Fatalf("get analytics ( %s ): could not decode response ... %s", path, err.Error())
+	}
+}
+
+func submitReceipts(t *testing.T, server *Server, bodies []string) {
+	for _, body := range bodies {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/process", strings.NewReader(body))
+		server.ServeHTTP(w, r)
+		if statusCode := w.Result().StatusCode; statusCode != 200 {
+			t.Fatalf("submit receipt: expected status code 200 submitting ( %s ) got %d", body, statusCode)
+		}
+	}
+}
+
+// A single row of the retailer, day or hour analytics, flattened so the responses can be compared field by field
+type analyticsRow struct {
+	Retailer string                 `json:"retailer"`
+	Date     string                 `json:"date"`
+	Receipts int64                  `json:"receipts"`
+	Points   int64                  `json:"points"`
+	Items    int64                  `json:"items"`
+	Spend    map[string]json.Number `json:"spend"`
+}
+
+var analyticsReceipts = []string{
+	`{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "6.49", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}]}`,
+	`{"retailer": "Target", "purchaseDate": "2022-01-02", "purchaseTime": "23:30", "total": "12.25", "items": [{"shortDescription": "Emils Cheese Pizza", "price": "12.25"}]}`,
+	`{"retailer": "Walgreens", "purchaseDate": "2022-03-20", "purchaseTime": "14:33", "total": "9.00", "items": [{"shortDescription": "Gatorade", "price": "4.50"}, {"shortDescription": "gatorade", "price": "4.50"}]}`,
+	`{"retailer": "Walgreens", "purchaseDate": "2022-03-21", "purchaseTime": "14:33", "total": "900", "currency": "JPY", "items": [{"shortDescription": "Gatorade", "price": "900"}]}`,
+}
+
+func TestAnalytics(t *testing.T) {
+	server := NewServer()
+	submitReceipts(t, server, analyticsReceipts)
+	submitReceipts(t, server, analyticsReceipts[:1]) // a duplicate is not counted again
+
+	var retailers struct {
+		Retailers []analyticsRow `json:"retailers"`
+	}
+	getAnalytics(t, server, "retailers", &retailers)
+	retailersTestCase := utils.CreationTestingData[string, []analyticsRow]{Argument: "retailers", ExpectedResult: []analyticsRow{
+		{Retailer: "Walgreens", Receipts: 2, Points: 99 + 25, Items: 3, Spend: map[string]json.Number{"USD": "9.00", "JPY": "900"}},
+		{Retailer: "Target", Receipts: 2, Points: 46, Items: 2, Spend: map[string]json.Number{"USD": "18.74"}},
+	}}
+	errCheck := retailersTestCase.CheckTestCase("retailer analytics", retailers.Retailers, nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+
+	var days struct {
+		Days []analyticsRow `json:"days"`
+	}
+	getAnalytics(t, server, "days?from=2022-01-02&to=2022-03-20", &days)
+	daysTestCase := utils.CreationTestingData[string, []analyticsRow]{Argument: "days", ExpectedResult: []analyticsRow{
+		{Date: "2022-01-02", Receipts: 1, Points: 34, Items: 1, Spend: map[string]json.Number{"USD": "12.25"}},
+		{Date: "2022-03-20", Receipts: 1, Points: 99, Items: 2, Spend: map[string]json.Number{"USD": "9.00"}},
+	}}
+	errCheck = daysTestCase.CheckTestCase("day analytics", days.Days, nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+
+	var hours struct {
+		Hours []hourTotalsResponse `json:"hours"`
+	}
+	getAnalytics(t, server, "hours", &hours)
+	if len(hours.Hours) != 24 || hours.Hours[14].Receipts != 2 || hours.Hours[13].Receipts != 1 || hours.Hours[0].Receipts != 0 {
+		t.Fatalf("hour analytics: expected 24 hours with 2 receipts at 14:00 and 1 at 13:00 got %+v", hours.Hours)
+	}
+
+	var basket basketResponse
+	getAnalytics(t, server, "basket?to=2022-03-20", &basket)
+	basketTestCase := utils.CreationTestingData[string, basketResponse]{Argument: "basket", ExpectedResult: basketResponse{Receipts: 3, AverageItems: 4.0 / 3, AveragePoints: 145.0 / 3, AverageSpend: map[string]json.Number{"USD": "9.25"}}}
+	errCheck = basketTestCase.CheckTestCase("basket analytics", basket, nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+
+	var items struct {
+		Items []itemTotalsResponse `json:"items"`
+	}
+	getAnalytics(t, server, "items?limit=2", &items)
+	itemsTestCase := utils.CreationTestingData[string, []itemTotalsResponse]{Argument: "items", ExpectedResult: []itemTotalsResponse{
+		{Description: "gatorade", Quantity: 3, Spend: map[string]json.Number{"USD": "9.00", "JPY": "900"}},
+		{Description: "emils cheese pizza", Quantity: 1, Spend: map[string]json.Number{"USD": "12.25"}},
+	}}
+	errCheck = itemsTestCase.CheckTestCase("item analytics", items.Items, nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+}
+
+// Receipts stored before the server started are included, without being read from the store on every request
+func TestAnalyticsIncludesExistingReceipts(t *testing.T) {
+	receiptStore := store.NewMemoryStore()
+	submitReceipts(t, NewServer(WithStore(receiptStore)), analyticsReceipts[:2])
+	server := NewServer(WithStore(receiptStore))
+	submitReceipts(t, server, analyticsReceipts[2:3])
+
+	var retailers struct {
+		Retailers []analyticsRow `json:"retailers"`
+	}
+	getAnalytics(t, server, "retailers", &retailers)
+	testCase := utils.CreationTestingData[string, []analyticsRow]{Argument: "retailers", ExpectedResult: []analyticsRow{
+		{Retailer: "Walgreens", Receipts: 1, Points: 99, Items: 2, Spend: map[string]json.Number{"USD": "9.00"}},
+		{Retailer: "Target", Receipts: 2, Points: 46, Items: 2, Spend: map[string]json.Number{"USD": "18.74"}},
+	}}
+	errCheck := testCase.CheckTestCase("retailer analytics", retailers.Retailers, nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+}
+
+func TestAnalyticsValidationErrors(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, []validationProblem] = []utils.CreationTestingData[string, []validationProblem]{
+		{Argument: "days?from=2022-02-30", ExpectedResult: []validationProblem{{Field: "from", Code: "ErrInvalidDate"}}},
+		{Argument: "retailers?from=2022-03-01&to=2022-02-01", ExpectedResult: []validationProblem{{Field: "to", Code: "ErrInvalidDateRange"}}},
+		{Argument: "items?limit=101", ExpectedResult: []validationProblem{{Field: "limit", Code: "ErrInvalidLimit"}}},
+		{Argument: "basket?to=tomorrow", ExpectedResult: []validationProblem{{Field: "to", Code: "ErrInvalidDateSyntax"}}},
+	}
+	server := NewServer()
+	for _, testCase := range testCases {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "http://localhost:8080/analytics/"+testCase.Argument, nil)
+		server.ServeHTTP(w, r)
+		if statusCode := w.Result().StatusCode; statusCode != 400 {
+			t.Fatalf("analytics ( %s ): expected status code 400 got %d", testCase.Argument, statusCode)
+		}
+		var response validationErrorResponse
+		json.NewDecoder(w.Body).Decode(&response)
+		problems := []validationProblem{}
+		for _, problem := range response.Problems {
+			problems = append(problems, validationProblem{Field: problem.Field, Code: problem.Code})
+		}
+		errCheck := testCase.CheckTestCase("analytics validation", problems, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
@@ -1,28 +1,130 @@
 package api
 
 import (
+	analytics "go-receipt-processor/Analytics"
+	audit "go-receipt-processor/Audit"
+	ledger "go-receipt-processor/Ledger"
+	money "go-receipt-processor/Money"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
 	points "go-receipt-processor/Points"
 	receipt "go-receipt-processor/Receipt"
+	store "go-receipt-processor/Store"
 
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"runtime"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+const (
+	// Header clients can set to a unique value for each receipt, so a retried submission returns the original receipt instead of storing it again
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+)
+
+var (
+	ErrScoringReceipt       = errors.New("scoring receipt")
+	ErrStoringReceipt       = errors.New("storing receipt")
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different receipt")
+	ErrSubmittedByOtherUser = errors.New("receipt was already submitted by a different user")
+)
+
 type Server struct {
 	*mux.Router
-	receiptMap map[string]receipt.Receipt
-	pointsMap  map[string]int64
+	store         store.ReceiptStore
+	rulesets      *points.RulesetHistory
+	exchangeRates *exchangerate.Table
+	formats       receipt.Formats
+	batchWorkers  int
+	rollups       *analytics.Rollups
+	rollupsErr    error // set when the receipts already in the store could not be read to build the rollups
+	auditLog      audit.Log
+	ledger        ledger.Ledger
 }
 
-func NewServer() *Server {
+// How a receipt was submitted, which decides whether it is a retry and who it is recorded against
+type submission struct {
+	idempotencyKey string
+	origin         audit.Origin
+	userId         string // the user credited with the receipt's points, empty if they are not credited to anyone
+}
+
+// Optional configuration applied when creating a new Server
+type ServerOption func(*Server)
+
+// Sets the backing store that processed receipts and their points are kept in. Defaults to an in-memory store.
+func WithStore(receiptStore store.ReceiptStore) ServerOption {
+	return func(s *Server) {
+		s.store = receiptStore
+	}
+}
+
+// Sets the log every change to the stored receipts is recorded in. Defaults to an in-memory log.
+func WithAuditLog(auditLog audit.Log) ServerOption {
+	return func(s *Server) {
+		s.auditLog = auditLog
+	}
+}
+
+// Sets the ledger the points of receipts submitted by users are credited to. Defaults to an in-memory ledger.
+func WithLedger(pointsLedger ledger.Ledger) ServerOption {
+	return func(s *Server) {
+		s.ledger = pointsLedger
+	}
+}
+
+// Sets the versions of the points rules available to the server. New receipts are scored with the current version, and previously stored receipts are explained with the version they were scored with.
+// Defaults to the built-in rulesets.
+func WithRulesets(rulesets *points.RulesetHistory) ServerOption {
+	return func(s *Server) {
+		s.rulesets = rulesets
+	}
+}
+
+// Sets the exchange rates used to convert receipts into a single base currency before they are scored, so the points rules treat every currency the same.
+// Defaults to none, in which case receipts are scored in their own currency.
+func WithExchangeRates(exchangeRates *exchangerate.Table) ServerOption {
+	return func(s *Server) {
+		s.exchangeRates = exchangeRates
+	}
+}
+
+// Sets the layouts the purchase date and time of submitted receipts are accepted in, and the day order used for dates that could be read either way.
+// Defaults to every layout with no day order, so dates like 03/04/2023 are rejected as ambiguous.
+func WithFormats(formats receipt.Formats) ServerOption {
+	return func(s *Server) {
+		s.formats = formats
+	}
+}
+
+// Sets how many receipts from a single batch are parsed, scored and stored at once. Defaults to the number of CPUs the server can use.
+func WithBatchWorkers(workers int) ServerOption {
+	return func(s *Server) {
+		if workers > 0 {
+			s.batchWorkers = workers
+		}
+	}
+}
+
+func NewServer(options ...ServerOption) *Server {
 	server := &Server{
-		Router:     mux.NewRouter(),
-		receiptMap: make(map[string]receipt.Receipt),
-		pointsMap:  make(map[string]int64),
+		Router:       mux.NewRouter(),
+		store:        store.NewMemoryStore(),
+		rulesets:     points.BuiltInRulesets(),
+		formats:      receipt.DefaultFormats,
+		batchWorkers: runtime.GOMAXPROCS(0),
+		auditLog:     audit.NewMemoryLog(),
+		ledger:       ledger.NewMemoryLedger(),
+	}
+	for _, option := range options {
+		option(server)
 	}
+	server.rollups, server.rollupsErr = buildRollups(server.store)
 	server.routes()
 	return server
 }
@@ -30,27 +132,66 @@ func NewServer() *Server {
 // All possible ways of interacting with the server
 // API Routes
 func (s *Server) routes() {
+	s.Use(withOrigin)
+	s.HandleFunc("/receipts", s.searchReceipts).Methods("GET")
 	s.HandleFunc("/receipts/process", s.processReceipt).Methods("POST")
-	s.HandleFunc("/receipts/{id}", s.getReceiptPoints).Methods("GET")
+	s.HandleFunc("/receipts/batch", s.processReceiptBatch).Methods("POST")
+	s.HandleFunc("/receipts/ingest", s.ingestReceipts).Methods("POST")
+	s.HandleFunc("/receipts/{id}", s.getReceipt).Methods("GET")
+	s.HandleFunc("/receipts/{id}", s.amendReceipt).Methods("PUT")
+	s.HandleFunc("/receipts/{id}", s.deleteReceipt).Methods("DELETE")
+	s.HandleFunc("/receipts/{id}/history", s.getReceiptHistory).Methods("GET")
+	s.HandleFunc("/receipts/{id}/audit", s.getReceiptAudit).Methods("GET")
+	s.HandleFunc("/receipts/{id}/points", s.getReceiptPoints).Methods("GET")
+	s.HandleFunc("/receipts/{id}/points/breakdown", s.getReceiptPointsBreakdown).Methods("GET")
+	s.HandleFunc("/users/{id}/balance", s.getUserBalance).Methods("GET")
+	s.HandleFunc("/users/{id}/ledger", s.getUserLedger).Methods("GET")
+
+	analyticsRouter := s.PathPrefix("/analytics").Subrouter()
+	analyticsRouter.HandleFunc("/retailers", s.getRetailerAnalytics).Methods("GET")
+	analyticsRouter.HandleFunc("/days", s.getDayAnalytics).Methods("GET")
+	analyticsRouter.HandleFunc("/hours", s.getHourAnalytics).Methods("GET")
+	analyticsRouter.HandleFunc("/basket", s.getBasketAnalytics).Methods("GET")
+	analyticsRouter.HandleFunc("/items", s.getItemAnalytics).Methods("GET")
 }
 
 type idResponse struct {
-	Id string `json:"id"`
+	Id        string `json:"id"`
+	Duplicate bool   `json:"duplicate"` // the receipt had already been submitted, and the id is the one it was stored under then
 }
 
 func (s *Server) processReceipt(w http.ResponseWriter, r *http.Request) {
+	idempotencyKey := r.Header.Get(idempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		http.Error(w, fmt.Sprintf("The idempotency key must be at most %d characters", maxIdempotencyKeyLength), http.StatusBadRequest)
+		return
+	}
+	userId, err := requestUserId(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	var unparsedReceipt receipt.UnparsedReceipt
-	err := json.NewDecoder(r.Body).Decode(&unparsedReceipt)
+	err = json.NewDecoder(r.Body).Decode(&unparsedReceipt)
 	if err != nil {
-		http.Error(w, "The receipt is invalid", http.StatusBadRequest)
+		writeValidationError(w, collectDecodingProblems(err))
+		return
+	}
+	idOutput, problems, err := s.submitReceipt(unparsedReceipt, submission{idempotencyKey: idempotencyKey, origin: requestOrigin(r), userId: userId})
+	if errors.Is(err, ErrIdempotencyKeyReused) {
+		http.Error(w, "The idempotency key was already used for a different receipt", http.StatusUnprocessableEntity)
+		return
+	} else if errors.Is(err, ErrSubmittedByOtherUser) {
+		http.Error(w, "The receipt was already submitted by a different user", http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, getInternalErrorMessage(err), http.StatusInternalServerError)
+		return
+	}
+	if len(problems) > 0 {
+		writeValidationError(w, problems)
 		return
 	}
-	id := uuid.New().String()
-	receipt, _ := receipt.ParseReceipt(id, unparsedReceipt, true)
-	points := points.CalculatePoints(receipt)
-	s.receiptMap[id] = receipt
-	s.pointsMap[id] = points
-	idOutput := idResponse{Id: id}
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(idOutput)
 	if err != nil {
@@ -60,22 +201,174 @@ func (s *Server) processReceipt(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// Parses, scores and stores a single receipt, returning its new id, or the problems found with it when it is invalid.
+// A receipt with the same idempotency key or the same content as one already stored is not stored again, and the id of the original is returned as a duplicate instead,
+// as long as the original was submitted by the same user, since one user must never be given the id of another user's receipt.
+// Storing the receipt, or finding it already stored, is recorded in the audit log under the origin of the request it was submitted with,
+// and the points of the receipt are credited to the user who submitted it, unless they already were, so retrying a submission that failed part way through finishes it.
+// An error means the receipt was valid but could not be scored, stored, audited or credited, that its idempotency key belongs to a different receipt, or that it was already submitted by a different user.
+func (s *Server) submitReceipt(unparsedReceipt receipt.UnparsedReceipt, submitted submission) (idResponse, []validationProblem, error) {
+	idempotencyKey := submitted.idempotencyKey
+	scoredReceipt, problems, err := s.scoreReceipt(uuid.New().String(), unparsedReceipt)
+	if len(problems) > 0 || err != nil {
+		return idResponse{}, problems, err
+	}
+	scoredReceipt.IdempotencyKey = idempotencyKey
+	scoredReceipt.UserId = submitted.userId // stored in the same write as the receipt, so a retry always finds who submitted it even if crediting them failed
+	scoredReceipt.Version = 1
+	storedReceipt, duplicate, err := s.store.PutIfAbsent(scoredReceipt)
+	if err != nil {
+		return idResponse{}, nil, fmt.Errorf("%w ... %w", ErrStoringReceipt, err)
+	}
+	if duplicate {
+		owner, err := s.receiptOwner(storedReceipt)
+		if err != nil {
+			return idResponse{}, nil, fmt.Errorf("%w ... %w", ErrStoringReceipt, err)
+		}
+		if owner != submitted.userId {
+			return idResponse{}, nil, ErrSubmittedByOtherUser
+		}
+	}
+	if duplicate && idempotencyKey != "" && storedReceipt.IdempotencyKey == idempotencyKey && !s.hadContent(storedReceipt, scoredReceipt.ContentHash) {
+		return idResponse{}, nil, fmt.Errorf("%w given \"%s\"", ErrIdempotencyKeyReused, idempotencyKey)
+	}
+	receiptHash := audit.ReceiptHash(storedReceipt)
+	if duplicate {
+		err = s.recordAudit(submitted.origin, audit.ActionResubmit, storedReceipt.Receipt.Id, receiptHash, receiptHash)
+	} else {
+		s.rollups.Add(storedReceipt)
+		err = s.recordAudit(submitted.origin, audit.ActionProcess, storedReceipt.Receipt.Id, "", receiptHash)
+	}
+	if err != nil {
+		return idResponse{}, nil, err
+	}
+	if submitted.userId != "" {
+		err = s.creditReceipt(submitted.userId, storedReceipt)
+		if err != nil {
+			return idResponse{}, nil, err
+		}
+	}
+	return idResponse{Id: storedReceipt.Receipt.Id, Duplicate: duplicate}, nil, nil
+}
+
+// Returns the user who submitted the receipt, or an empty id if it was submitted without one.
+// Receipts stored before their user was stored with them only have their user in the ledger.
+func (s *Server) receiptOwner(storedReceipt store.StoredReceipt) (string, error) {
+	if storedReceipt.UserId != "" {
+		return storedReceipt.UserId, nil
+	}
+	owner, _, err := s.ledger.Owner(storedReceipt.Receipt.Id)
+	return owner, err
+}
+
+// Credits the points of the receipt to the user unless they already were, either when it was first stored or by a concurrent retry of its submission
+func (s *Server) creditReceipt(userId string, storedReceipt store.StoredReceipt) error {
+	_, credited, err := s.ledger.Owner(storedReceipt.Receipt.Id)
+	if err == nil && !credited {
+		_, err = s.ledger.Credit(userId, storedReceipt.Receipt.Id, storedReceipt.Points)
+	}
+	if err != nil && !errors.Is(err, ledger.ErrReceiptAlreadyCredited) {
+		return fmt.Errorf("%w given \"%s\" ... %w", ErrCreditingPoints, storedReceipt.Receipt.Id, err)
+	}
+	return nil
+}
+
+// Reports whether the stored receipt has the content hash, or had it before it was amended, so a retry of the original submission is still recognized as one
+func (s *Server) hadContent(storedReceipt store.StoredReceipt, contentHash string) bool {
+	if storedReceipt.ContentHash == contentHash {
+		return true
+	}
+	if storedReceipt.EffectiveVersion() == 1 {
+		return false
+	}
+	revisions, err := s.store.History(storedReceipt.Receipt.Id)
+	if err != nil {
+		return false
+	}
+	for _, revision := range revisions {
+		if revision.ContentHash == contentHash {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses and validates the receipt under the id, then scores it with the current points rules, returning it ready to be stored, or the problems found with it when it is invalid.
+// An error means the receipt was valid but could not be scored.
+func (s *Server) scoreReceipt(id string, unparsedReceipt receipt.UnparsedReceipt) (store.StoredReceipt, []validationProblem, error) {
+	parsedReceipt, err := receipt.ParseReceiptWith(id, unparsedReceipt, s.formats, true)
+	if err != nil {
+		return store.StoredReceipt{}, collectValidationProblems(err), nil
+	}
+	rate, err := s.lookupExchangeRate(parsedReceipt)
+	if err != nil {
+		return store.StoredReceipt{}, []validationProblem{{Field: "currency", Code: getValidationCode(err), Message: err.Error()}}, nil
+	}
+	ruleset := s.rulesets.Current()
+	breakdown, err := ruleset.CalculateWithRate(parsedReceipt, rate)
+	if err != nil {
+		return store.StoredReceipt{}, nil, fmt.Errorf("%w ... %w", ErrScoringReceipt, err)
+	}
+	return store.StoredReceipt{Receipt: parsedReceipt, Points: breakdown.Points, RulesetVersion: ruleset.Version, ExchangeRate: rate, ContentHash: parsedReceipt.ContentHash()}, nil, nil
+}
+
+// The message reported to clients for a receipt that was valid but could not be scored or stored
+func getInternalErrorMessage(err error) string {
+	if errors.Is(err, ErrScoringReceipt) {
+		return "The receipt could not be scored"
+	} else if errors.Is(err, ErrAuditingReceipt) {
+		return "The receipt was stored but could not be audited"
+	} else if errors.Is(err, ErrCreditingPoints) {
+		return "The receipt was stored but its points could not be credited"
+	}
+	return "The receipt could not be stored"
+}
+
 type pointsResponse struct {
-	Points int64 `json:"points"`
+	Points         int64              `json:"points"`
+	RulesetVersion int                `json:"rulesetVersion"`
+	ExchangeRate   *exchangerate.Rate `json:"exchangeRate,omitempty"`
 }
 
-// On GET HTTP Request, tries to parse the ID given within the request and iterates over the list of ReceiptPoints objects until it finds it or finishes iterating over the list.
-// It then outputs the receipt points value in JSON format.
-func (s *Server) getReceiptPoints(w http.ResponseWriter, r *http.Request) {
+// Finds the rate to convert the receipt into the base currency with, or nil if there are no exchange rates configured or the receipt is already in the base currency
+func (s *Server) lookupExchangeRate(r receipt.Receipt) (*exchangerate.Rate, error) {
+	if s.exchangeRates == nil {
+		return nil, nil
+	}
+	return s.exchangeRates.Lookup(r.Currency, r.PurchaseDate)
+}
+
+// Receipts stored before ruleset versions were recorded have no version, but were scored with the original rules
+func getRulesetVersion(storedReceipt store.StoredReceipt) int {
+	if storedReceipt.RulesetVersion == 0 {
+		return points.OriginalRulesetVersion
+	}
+	return storedReceipt.RulesetVersion
+}
+
+// Looks up the stored receipt for the ID given within the request, writing an error response and returning false if it cannot be found.
+func (s *Server) lookupReceipt(w http.ResponseWriter, r *http.Request) (store.StoredReceipt, bool) {
 	id := mux.Vars(r)["id"]
 
-	points, containsKey := s.pointsMap[id]
-	if !containsKey {
+	storedReceipt, err := s.store.Get(id)
+	if errors.Is(err, store.ErrReceiptNotFound) {
 		http.Error(w, "No receipt found for that id", http.StatusNotFound)
+		return storedReceipt, false
+	} else if err != nil {
+		http.Error(w, "The receipt could not be retrieved", http.StatusInternalServerError)
+		return storedReceipt, false
+	}
+	return storedReceipt, true
+}
+
+// On GET HTTP Request, tries to parse the ID given within the request and looks up the stored receipt for it.
+// It then outputs the receipt points value in JSON format.
+func (s *Server) getReceiptPoints(w http.ResponseWriter, r *http.Request) {
+	storedReceipt, found := s.lookupReceipt(w, r)
+	if !found {
 		return
 	}
-	//pointsOutput := map[string]int64{"points": points}
-	pointsOutput := pointsResponse{Points: points}
+	pointsOutput := pointsResponse{Points: storedReceipt.Points, RulesetVersion: getRulesetVersion(storedReceipt), ExchangeRate: storedReceipt.ExchangeRate}
 
 	w.Header().Set("Content-Type", "application/json")
 	err := json.NewEncoder(w).Encode(pointsOutput)
@@ -84,3 +377,130 @@ func (s *Server) getReceiptPoints(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+type receiptItemResponse struct {
+	ShortDescription string      `json:"shortDescription"`
+	Price            json.Number `json:"price"`
+}
+
+type receiptResponse struct {
+	Id             string                `json:"id"`
+	Retailer       string                `json:"retailer"`
+	PurchaseDate   string                `json:"purchaseDate"`
+	PurchaseTime   string                `json:"purchaseTime"`
+	Items          []receiptItemResponse `json:"items"`
+	Total          json.Number           `json:"total"`
+	Currency       string                `json:"currency"`
+	TimeZone       string                `json:"timeZone,omitempty"`
+	StoreTimeZone  string                `json:"storeTimeZone,omitempty"`
+	Points         int64                 `json:"points"`
+	RulesetVersion int                   `json:"rulesetVersion"`
+	Version        int                   `json:"version"` // 1 when the receipt was first processed, counting up each time it is amended
+}
+
+// Converts a stored receipt into the normalized form returned to clients, with the date as YYYY-MM-DD, the time as HH:MM, and prices as numbers with as many decimal places as the currency has
+func newReceiptResponse(storedReceipt store.StoredReceipt) receiptResponse {
+	r := storedReceipt.Receipt
+	items := make([]receiptItemResponse, 0, len(r.Items))
+	for _, item := range r.Items {
+		items = append(items, receiptItemResponse{ShortDescription: item.ShortDescription, Price: json.Number(item.Price.Format(r.Currency))})
+	}
+	return receiptResponse{
+		Id:             r.Id,
+		Retailer:       r.Retailer,
+		PurchaseDate:   r.PurchaseDate.String(),
+		PurchaseTime:   r.PurchaseTime.String(),
+		Items:          items,
+		Total:          json.Number(r.Total.Format(r.Currency)),
+		Currency:       r.Currency.Code(),
+		TimeZone:       r.TimeZone.String(),
+		StoreTimeZone:  r.StoreTimeZone.String(),
+		Points:         storedReceipt.Points,
+		RulesetVersion: getRulesetVersion(storedReceipt),
+		Version:        storedReceipt.EffectiveVersion(),
+	}
+}
+
+// On GET HTTP Request, tries to parse the ID given within the request and looks up the stored receipt for it.
+// It then outputs the receipt as it was parsed, along with its points, in JSON format.
+func (s *Server) getReceipt(w http.ResponseWriter, r *http.Request) {
+	storedReceipt, found := s.lookupReceipt(w, r)
+	if !found {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(newReceiptResponse(storedReceipt))
+	if err != nil {
+		http.Error(w, "The receipt could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+}
+
+type awardedRuleResponse struct {
+	Rule        string               `json:"rule"`
+	Description string               `json:"description"`
+	Points      int64                `json:"points"`
+	ItemIndex   *int                 `json:"itemIndex,omitempty"`
+	Item        *receiptItemResponse `json:"item,omitempty"`
+}
+
+// The points breakdown of a receipt as it is shown to clients, by the breakdown route and by the score command
+type BreakdownResponse struct {
+	Points         int64                 `json:"points"`
+	RulesetVersion int                   `json:"rulesetVersion"`
+	ExchangeRate   *exchangerate.Rate    `json:"exchangeRate,omitempty"`
+	Rules          []awardedRuleResponse `json:"rules"`
+}
+
+// Converts a breakdown into the form returned to clients, with item prices as numbers with as many decimal places as the currency the receipt was scored in has
+func NewBreakdownResponse(breakdown points.Breakdown, currency money.Currency) BreakdownResponse {
+	if breakdown.ExchangeRate != nil {
+		currency = breakdown.ExchangeRate.To // the items were converted before being scored
+	}
+	rules := make([]awardedRuleResponse, 0, len(breakdown.Rules))
+	for _, awarded := range breakdown.Rules {
+		rule := awardedRuleResponse{Rule: awarded.Rule, Description: awarded.Description, Points: awarded.Points, ItemIndex: awarded.ItemIndex}
+		if awarded.Item != nil {
+			rule.Item = &receiptItemResponse{ShortDescription: awarded.Item.ShortDescription, Price: json.Number(awarded.Item.Price.Format(currency))}
+		}
+		rules = append(rules, rule)
+	}
+	return BreakdownResponse{Points: breakdown.Points, RulesetVersion: breakdown.RulesetVersion, ExchangeRate: breakdown.ExchangeRate, Rules: rules}
+}
+
+// On GET HTTP Request, tries to parse the ID given within the request and looks up the stored receipt for it.
+// It then outputs each rule that awarded the receipt points, and the item that triggered it if any, in JSON format.
+// The breakdown uses the ruleset version the receipt was scored with, unless a different version is given with the "rulesetVersion" query parameter to compare what the receipt would be worth under other rules.
+func (s *Server) getReceiptPointsBreakdown(w http.ResponseWriter, r *http.Request) {
+	storedReceipt, found := s.lookupReceipt(w, r)
+	if !found {
+		return
+	}
+	version := getRulesetVersion(storedReceipt)
+	if versionParameter := r.URL.Query().Get("rulesetVersion"); versionParameter != "" {
+		parsedVersion, err := strconv.Atoi(versionParameter)
+		if err != nil {
+			http.Error(w, "The ruleset version must be a whole number", http.StatusBadRequest)
+			return
+		}
+		version = parsedVersion
+	}
+	ruleset, err := s.rulesets.Version(version)
+	if err != nil {
+		http.Error(w, "No ruleset found for that version", http.StatusNotFound)
+		return
+	}
+	breakdown, err := ruleset.CalculateWithRate(storedReceipt.Receipt, storedReceipt.ExchangeRate) // the recorded rate, so the breakdown matches the stored points even after the rates change
+	if err != nil {
+		http.Error(w, "The receipt could not be scored", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(NewBreakdownResponse(breakdown, storedReceipt.Receipt.Currency))
+	if err != nil {
+		http.Error(w, "The receipt could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+}
@@ -1,13 +1,26 @@
 package api
 
 import (
-	receipt "go-receipt-processor/Receipt"
-	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
 	"bytes"
 	"encoding/json"
+	"fmt"
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
+	points "go-receipt-processor/Points"
+	receipt "go-receipt-processor/Receipt"
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	store "go-receipt-processor/Store"
+	utils "go-receipt-processor/TestingUtils"
+	time "go-receipt-processor/Time"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 type ServerTestCase struct {
@@ -96,3 +109,671 @@ func TestServer(t *testing.T) {
 		}
 	}
 }
+
+func TestServerWithStore(t *testing.T) {
+	receiptStore := store.NewMemoryStore()
+	server := NewServer(WithStore(receiptStore))
+
+	unparsedReceiptJson, _ := json.Marshal(receipt.UnparsedReceipt{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        "6.49",
+		Items: []receiptitem.UnparsedReceiptItem{
+			{ShortDescription: "Mountain Dew 12PK", Price: "6.49"},
+		},
+	})
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/process", bytes.NewReader(unparsedReceiptJson))
+	server.Router.ServeHTTP(w, r)
+	if statusCode := w.Result().StatusCode; statusCode != 200 {
+		t.Fatalf("test server with store:\n    expected status code: \"%d\"\n    actual status code: \"%d\"\n", 200, statusCode)
+	}
+	var id idResponse
+	json.NewDecoder(w.Body).Decode(&id)
+
+	storedReceipt, err := receiptStore.Get(id.Id)
+	if err != nil {
+		t.Fatalf("test server with store: expected receipt ( %s ) to be in the provided store ... %v", id.Id, err)
+	}
+	if storedReceipt.Receipt.Retailer != "Target" || storedReceipt.Points != 12 {
+		t.Fatalf("test server with store: unexpected stored receipt ( %+v )", storedReceipt)
+	}
+}
+
+// Hammers the server with POST and GET requests from many goroutines at once, run with "go test -race" to catch unsynchronized access to the store
+func TestServerConcurrentRequests(t *testing.T) {
+	const workerCount = 16
+	const requestsPerWorker = 50
+
+	server := NewServer()
+	unparsedReceipt := receipt.UnparsedReceipt{
+		Retailer:     "M&M Corner Market",
+		PurchaseDate: "2022-03-20",
+		PurchaseTime: "14:33",
+		Total:        "9.00",
+		Items: []receiptitem.UnparsedReceiptItem{
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+		},
+	}
+	url := "http://localhost:8080/receipts/"
+
+	ids := make(chan string, workerCount*requestsPerWorker)
+	errs := make(chan error, workerCount*requestsPerWorker*2)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workerCount; worker++ {
+		wg.Add(2)
+		go func(worker int) { // posts receipts and immediately reads them back
+			defer wg.Done()
+			for i := 0; i < requestsPerWorker; i++ {
+				distinctReceipt := unparsedReceipt
+				distinctReceipt.PurchaseDate = fmt.Sprintf("%d-03-20", 1000+worker*requestsPerWorker+i) // the year does not change the points, but keeps each receipt from being a duplicate of another
+				unparsedReceiptJson, _ := json.Marshal(distinctReceipt)
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("POST", url+"process", bytes.NewReader(unparsedReceiptJson))
+				server.ServeHTTP(w, r)
+				if statusCode := w.Result().StatusCode; statusCode != 200 {
+					errs <- fmt.Errorf("POST: expected status code 200 got %d", statusCode)
+					continue
+				}
+				var id idResponse
+				json.NewDecoder(w.Body).Decode(&id)
+				ids <- id.Id
+
+				w = httptest.NewRecorder()
+				r, _ = http.NewRequest("GET", url+id.Id+"/points", nil)
+				server.ServeHTTP(w, r)
+				var points pointsResponse
+				json.NewDecoder(w.Body).Decode(&points)
+				if points.Points != 109 {
+					errs <- fmt.Errorf("GET ( %s ): expected 109 points got %d", id.Id, points.Points)
+				}
+			}
+		}(worker)
+		go func() { // reads unknown ids while the other goroutines are writing
+			defer wg.Done()
+			for i := 0; i < requestsPerWorker; i++ {
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", url+"unknown-"+strconv.Itoa(i), nil)
+				server.ServeHTTP(w, r)
+				if statusCode := w.Result().StatusCode; statusCode != 404 {
+					errs <- fmt.Errorf("GET unknown: expected status code 404 got %d", statusCode)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+	for err := range errs {
+		t.Fatalf("test server concurrent requests: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("test server concurrent requests: id ( %s ) was returned twice", id)
+		}
+		seen[id] = true
+	}
+	storedReceipts, _ := server.store.List()
+	if len(storedReceipts) != workerCount*requestsPerWorker {
+		t.Fatalf("test server concurrent requests: expected %d stored receipts got %d", workerCount*requestsPerWorker, len(storedReceipts))
+	}
+}
+
+func TestGetReceipt(t *testing.T) {
+	server := NewServer()
+	unparsedReceiptJson, _ := json.Marshal(receipt.UnparsedReceipt{
+		Retailer:     "M&M Corner Market",
+		PurchaseDate: "2022-03-20",
+		PurchaseTime: "9:05",
+		Total:        "9.00",
+		Items: []receiptitem.UnparsedReceiptItem{
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+		},
+	})
+	url := "http://localhost:8080/receipts/"
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", url+"process", bytes.NewReader(unparsedReceiptJson))
+	server.ServeHTTP(w, r)
+	var id idResponse
+	json.NewDecoder(w.Body).Decode(&id)
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", url+id.Id, nil)
+	server.ServeHTTP(w, r)
+	if statusCode := w.Result().StatusCode; statusCode != 200 {
+		t.Fatalf("get receipt:\n    expected status code: \"%d\"\n    actual status code: \"%d\"\n", 200, statusCode)
+	}
+	var actual receiptResponse
+	json.NewDecoder(w.Body).Decode(&actual)
+	expected := receiptResponse{
+		Id:           id.Id,
+		Retailer:     "M&M Corner Market",
+		PurchaseDate: "2022-03-20",
+		PurchaseTime: "09:05",
+		Items: []receiptItemResponse{
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+		},
+		Total:          "9.00",
+		Currency:       "USD",
+		Points:         99,
+		RulesetVersion: 1,
+		Version:        1,
+	}
+	if !cmp.Equal(expected, actual) {
+		t.Fatalf("get receipt:\n    expected: %+v\n    got: %+v\n", expected, actual)
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", url+id.Id+"/points", nil)
+	server.ServeHTTP(w, r)
+	var points pointsResponse
+	json.NewDecoder(w.Body).Decode(&points)
+	if points.Points != 99 {
+		t.Fatalf("get receipt points:\n    expected: %d\n    got: %d\n", 99, points.Points)
+	}
+
+	for _, path := range []string{"unknown", "unknown/points"} {
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest("GET", url+path, nil)
+		server.ServeHTTP(w, r)
+		if statusCode := w.Result().StatusCode; statusCode != 404 {
+			t.Fatalf("get receipt ( %s ):\n    expected status code: \"%d\"\n    actual status code: \"%d\"\n", path, 404, statusCode)
+		}
+	}
+}
+
+// Amounts are returned with as many decimal places as the receipt's currency has
+func TestServerWithFormats(t *testing.T) {
+	monthFirst := receipt.DefaultFormats
+	monthFirst.Date.Order = date.MonthFirst
+	var testCases []utils.CreationTestingData[receipt.Formats, receiptResponse] = []utils.CreationTestingData[receipt.Formats, receiptResponse]{
+		{
+			Argument:       monthFirst,
+			ExpectedResult: receiptResponse{Retailer: "Target", PurchaseDate: "2023-03-04", PurchaseTime: "15:30:12", Currency: "USD", Total: "1.01", Items: []receiptItemResponse{{ShortDescription: "Gatorade", Price: "1.01"}}, Points: 6 + 10, RulesetVersion: 1, Version: 1},
+		},
+		{ //Tests that only the configured layouts are accepted
+			Argument:       receipt.Formats{Date: date.Formats{Layouts: []date.Layout{date.LayoutISO}}, Time: time.DefaultFormats},
+			ExpectedResult: receiptResponse{},
+		},
+	}
+	for _, testCase := range testCases {
+		server := NewServer(WithFormats(testCase.Argument))
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/process", strings.NewReader(`{"retailer": "Target", "purchaseDate": "03/04/2023", "purchaseTime": "3:30:12 PM", "total": "1.01", "items": [{"shortDescription": "Gatorade", "price": "1.01"}]}`))
+		server.ServeHTTP(w, r)
+		var id idResponse
+		json.NewDecoder(w.Body).Decode(&id)
+
+		var actual receiptResponse
+		if id.Id != "" {
+			w = httptest.NewRecorder()
+			r, _ = http.NewRequest("GET", "http://localhost:8080/receipts/"+id.Id, nil)
+			server.ServeHTTP(w, r)
+			json.NewDecoder(w.Body).Decode(&actual)
+			testCase.ExpectedResult.Id = id.Id
+		}
+		errCheck := testCase.CheckTestCase("server with formats", actual, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func TestGetReceiptInCurrency(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, receiptResponse] = []utils.CreationTestingData[string, receiptResponse]{
+		{
+			Argument:       `{"retailer": "Lidl", "purchaseDate": "2023-05-11", "purchaseTime": "10:15", "currency": "EUR", "total": "12,50", "items": [{"shortDescription": "Coffee", "price": "12,50"}]}`,
+			ExpectedResult: receiptResponse{Retailer: "Lidl", PurchaseDate: "2023-05-11", PurchaseTime: "10:15", Currency: "EUR", Total: "12.50", Items: []receiptItemResponse{{ShortDescription: "Coffee", Price: "12.50"}}, Points: 4 + 25 + 3 + 6, RulesetVersion: 1, Version: 1},
+		},
+		{
+			Argument:       `{"retailer": "Lawson", "purchaseDate": "2023-05-12", "purchaseTime": "10:15", "currency": "jpy", "total": "1500", "items": [{"shortDescription": "Bento", "price": "1500"}]}`,
+			ExpectedResult: receiptResponse{Retailer: "Lawson", PurchaseDate: "2023-05-12", PurchaseTime: "10:15", Currency: "JPY", Total: "1500", Items: []receiptItemResponse{{ShortDescription: "Bento", Price: "1500"}}, Points: 6, RulesetVersion: 1, Version: 1},
+		},
+		{ //Tests that the purchase date and time are returned as they were written, with the zones they are in, but are scored in the store's zone
+			Argument:       `{"retailer": "Target", "purchaseDate": "2023-10-15", "purchaseTime": "20:30", "timeZone": "UTC", "storeTimeZone": "America/Chicago", "total": "1.01", "items": [{"shortDescription": "Gatorade", "price": "1.01"}]}`,
+			ExpectedResult: receiptResponse{Retailer: "Target", PurchaseDate: "2023-10-15", PurchaseTime: "20:30", TimeZone: "UTC", StoreTimeZone: "America/Chicago", Currency: "USD", Total: "1.01", Items: []receiptItemResponse{{ShortDescription: "Gatorade", Price: "1.01"}}, Points: 6 + 6 + 10, RulesetVersion: 1, Version: 1},
+		},
+	}
+	for _, testCase := range testCases {
+		server := NewServer()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/process", strings.NewReader(testCase.Argument))
+		server.ServeHTTP(w, r)
+		var id idResponse
+		json.NewDecoder(w.Body).Decode(&id)
+
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest("GET", "http://localhost:8080/receipts/"+id.Id, nil)
+		server.ServeHTTP(w, r)
+		var actual receiptResponse
+		json.NewDecoder(w.Body).Decode(&actual)
+		testCase.ExpectedResult.Id = id.Id
+		errCheck := testCase.CheckTestCase("get receipt in currency", actual, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func TestGetReceiptPointsBreakdown(t *testing.T) {
+	server := NewServer()
+	unparsedReceiptJson, _ := json.Marshal(receipt.UnparsedReceipt{
+		Retailer:     "M&M Corner Market",
+		PurchaseDate: "2022-03-20",
+		PurchaseTime: "14:33",
+		Total:        "9.00",
+		Items: []receiptitem.UnparsedReceiptItem{
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+			{ShortDescription: "Gatorade", Price: "2.25"},
+		},
+	})
+	url := "http://localhost:8080/receipts/"
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", url+"process", bytes.NewReader(unparsedReceiptJson))
+	server.ServeHTTP(w, r)
+	var id idResponse
+	json.NewDecoder(w.Body).Decode(&id)
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", url+id.Id+"/points/breakdown", nil)
+	server.ServeHTTP(w, r)
+	if statusCode := w.Result().StatusCode; statusCode != 200 {
+		t.Fatalf("get receipt points breakdown:\n    expected status code: \"%d\"\n    actual status code: \"%d\"\n", 200, statusCode)
+	}
+	var breakdown points.Breakdown
+	json.NewDecoder(w.Body).Decode(&breakdown)
+	if breakdown.Points != 109 {
+		t.Fatalf("get receipt points breakdown:\n    expected: %d\n    got: %d\n", 109, breakdown.Points)
+	}
+	rulePoints := map[string]int64{}
+	for _, rule := range breakdown.Rules {
+		rulePoints[rule.Rule] += rule.Points
+	}
+	expectedRulePoints := map[string]int64{
+		points.RuleAlphanumericRetailer:  14,
+		points.RuleRoundDollarTotal:      50,
+		points.RuleMultipleOf25Cents:     25,
+		points.RuleItemPairs:             10,
+		points.RuleAfternoonPurchaseTime: 10,
+	}
+	if !cmp.Equal(expectedRulePoints, rulePoints) {
+		t.Fatalf("get receipt points breakdown:\n    expected: %+v\n    got: %+v\n", expectedRulePoints, rulePoints)
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", url+"unknown/points/breakdown", nil)
+	server.ServeHTTP(w, r)
+	if statusCode := w.Result().StatusCode; statusCode != 404 {
+		t.Fatalf("get receipt points breakdown:\n    expected status code: \"%d\"\n    actual status code: \"%d\"\n", 404, statusCode)
+	}
+}
+
+func TestGetReceiptPointsBreakdownItemPrices(t *testing.T) {
+	rates, err := exchangerate.ParseTable([]byte(`{"base": "USD", "rates": [{"currency": "EUR", "date": "2024-01-01", "rate": 1.08}]}`), exchangerate.FormatJSON)
+	if err != nil {
+		t.Fatalf("get receipt points breakdown item prices: unexpected error ( %v )", err)
+	}
+	// the default rules only score item prices in currencies like the dollar, so yen are scored by a rule of their own
+	yenRuleset, err := points.ParseRuleset([]byte(`{"name": "yen", "version": 2, "rules": [{"name": "itemPrice", "type": "itemDescriptionLength", "lengthMultiple": 1, "priceMultiplier": 0.002, "currencies": ["JPY"]}]}`), points.FormatJSON)
+	if err != nil {
+		t.Fatalf("get receipt points breakdown item prices: unexpected error ( %v )", err)
+	}
+	yenRulesets, _ := points.NewRulesetHistory(points.DefaultRuleset(), yenRuleset)
+	type ItemPriceTestCase struct {
+		server       *Server
+		body         string
+		expectedItem receiptItemResponse
+	}
+	for _, testCase := range []ItemPriceTestCase{
+		{server: NewServer(WithRulesets(yenRulesets)), body: `{"retailer": "Lawson", "purchaseDate": "2024-02-10", "purchaseTime": "10:15", "currency": "JPY", "total": "1500", "items": [{"shortDescription": "Bento Box", "price": "1500"}]}`, expectedItem: receiptItemResponse{ShortDescription: "Bento Box", Price: "1500"}},
+		{server: NewServer(), body: `{"retailer": "Target", "purchaseDate": "2024-02-10", "purchaseTime": "10:15", "total": "12.25", "items": [{"shortDescription": "Emils Cheese Pizza", "price": "12.25"}]}`, expectedItem: receiptItemResponse{ShortDescription: "Emils Cheese Pizza", Price: "12.25"}},
+		//the items of a converted receipt are scored, and shown, in the base currency
+		{server: NewServer(WithExchangeRates(rates)), body: `{"retailer": "Lidl", "purchaseDate": "2024-02-10", "purchaseTime": "10:15", "currency": "EUR", "total": "10,00", "items": [{"shortDescription": "Coffee Beans", "price": "10,00"}]}`, expectedItem: receiptItemResponse{ShortDescription: "Coffee Beans", Price: "10.80"}},
+	} {
+		server := testCase.server
+		w := sendReceiptRequest(server, "POST", "process", testCase.body, nil)
+		var id idResponse
+		json.NewDecoder(w.Body).Decode(&id)
+		w = sendReceiptRequest(server, "GET", id.Id+"/points/breakdown", "", nil)
+		var breakdown BreakdownResponse
+		json.NewDecoder(w.Body).Decode(&breakdown)
+		var item receiptItemResponse
+		for _, rule := range breakdown.Rules {
+			if rule.Item != nil {
+				item = *rule.Item
+			}
+		}
+		if !cmp.Equal(item, testCase.expectedItem) {
+			t.Fatalf("get receipt points breakdown item prices ( %s ):\n    expected: %+v\n    got: %+v\n", testCase.body, testCase.expectedItem, item)
+		}
+	}
+}
+
+type ValidationTestCase struct {
+	body             string
+	expectedProblems []validationProblem
+}
+
+func TestProcessReceiptValidationErrors(t *testing.T) {
+	validItems := `[{"shortDescription": "Gatorade", "price": "2.25"}, {"shortDescription": "Gatorade", "price": "2.25"}]`
+	var testCases []ValidationTestCase = []ValidationTestCase{
+		{
+			body:             `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "4.00", "items": ` + validItems + `}`,
+			expectedProblems: []validationProblem{{Field: "total", Code: "ErrInvalidTotal"}},
+		},
+		{
+			body: `{"retailer": "Target", "purchaseDate": "2022/01/01", "purchaseTime": "1301", "total": "4.50", "items": [{"shortDescription": "Gatorade", "price": "2.25"}, {"shortDescription": "Gatorade", "price": "2.2a5"}, {"shortDescription": "Gatorade", "price": ""}]}`,
+			expectedProblems: []validationProblem{
+				{Field: "purchaseDate", Code: "ErrInvalidDateSyntax"},
+				{Field: "purchaseTime", Code: "ErrInvalidTimeSyntax"},
+				{Field: "items[1].price", Code: "ErrParsingPrice"},
+				{Field: "items[2].price", Code: "ErrEmptyPriceString"},
+			},
+		},
+		{
+			body: `{"retailer": "Target", "purchaseDate": "2022-02-30", "purchaseTime": "13:61", "total": "4.50", "items": ` + validItems + `}`,
+			expectedProblems: []validationProblem{
+				{Field: "purchaseDate", Code: "ErrInvalidDate"},
+				{Field: "purchaseTime", Code: "ErrInvalidMinute"},
+			},
+		},
+		{
+			body:             `{"retailer": "Target", "purchaseDate": "", "purchaseTime": "13:01", "total": "four", "items": ` + validItems + `}`,
+			expectedProblems: []validationProblem{{Field: "purchaseDate", Code: "ErrEmptyDateString"}, {Field: "total", Code: "ErrParsingTotal"}},
+		},
+		{
+			body:             `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "4.50", "currency": "dollars", "items": ` + validItems + `}`,
+			expectedProblems: []validationProblem{{Field: "currency", Code: "ErrParsingCurrency"}},
+		},
+		{
+			body:             `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "4.50", "timeZone": "Mars/Olympus_Mons", "items": ` + validItems + `}`,
+			expectedProblems: []validationProblem{{Field: "timeZone", Code: "ErrParsingTimeZone"}},
+		},
+		{
+			body:             `{"retailer": "Target", "purchaseDate": "03/04/2022", "purchaseTime": "1:01 PM", "total": "4.50", "items": ` + validItems + `}`,
+			expectedProblems: []validationProblem{{Field: "purchaseDate", Code: "ErrAmbiguousDate"}},
+		},
+		{
+			body:             `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "4.505", "items": ` + validItems + `}`,
+			expectedProblems: []validationProblem{{Field: "total", Code: "ErrParsingTotal"}},
+		},
+		{
+			body:             `{"retailer": "Target", "total": 4.50}`,
+			expectedProblems: []validationProblem{{Field: "total", Code: "ErrInvalidJSON"}},
+		},
+		{
+			body:             `{"retailer": "Target",`,
+			expectedProblems: []validationProblem{{Field: "", Code: "ErrInvalidJSON"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		server := NewServer()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/process", bytes.NewReader([]byte(testCase.body)))
+		server.ServeHTTP(w, r)
+		if statusCode := w.Result().StatusCode; statusCode != 400 {
+			t.Fatalf("process receipt validation ( %s ):\n    expected status code: \"%d\"\n    actual status code: \"%d\"\n", testCase.body, 400, statusCode)
+		}
+		var response validationErrorResponse
+		json.NewDecoder(w.Body).Decode(&response)
+		for i := range response.Problems {
+			if response.Problems[i].Message == "" {
+				t.Fatalf("process receipt validation ( %s ): problem ( %+v ) is missing a message", testCase.body, response.Problems[i])
+			}
+			response.Problems[i].Message = ""
+		}
+		if !cmp.Equal(testCase.expectedProblems, response.Problems) {
+			t.Fatalf("process receipt validation ( %s ):\n    expected: %+v\n    got: %+v\n", testCase.body, testCase.expectedProblems, response.Problems)
+		}
+		if storedReceipts, _ := server.store.List(); len(storedReceipts) != 0 {
+			t.Fatalf("process receipt validation ( %s ): invalid receipt was stored", testCase.body)
+		}
+	}
+}
+
+func TestValidationCodesAreUnique(t *testing.T) {
+	for name, featureCodes := range map[string][]validationCode{"receipts": nil, "search": searchValidationCodes, "analytics": analyticsValidationCodes} {
+		seen := map[string]bool{}
+		for _, code := range append(append([]validationCode{}, featureCodes...), validationCodes...) {
+			if seen[code.Code] {
+				t.Fatalf("validation codes ( %s ): code ( %s ) is listed more than once", name, code.Code)
+			}
+			seen[code.Code] = true
+		}
+	}
+}
+
+func TestServerWithRulesets(t *testing.T) {
+	flat, err := points.ParseRuleset([]byte(`{"name": "flat", "version": 2, "rules": [{"name": "flat", "type": "itemCount", "groupSize": 1, "points": 100}]}`), points.FormatJSON)
+	if err != nil {
+		t.Fatalf("test server with rulesets: unexpected error ( %v )", err)
+	}
+	rulesets, _ := points.NewRulesetHistory(points.DefaultRuleset(), flat)
+	server := NewServer(WithRulesets(rulesets))
+	unparsedReceiptJson, _ := json.Marshal(receipt.UnparsedReceipt{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        "6.49",
+		Items: []receiptitem.UnparsedReceiptItem{
+			{ShortDescription: "Mountain Dew 12PK", Price: "6.49"},
+		},
+	})
+	url := "http://localhost:8080/receipts/"
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", url+"process", bytes.NewReader(unparsedReceiptJson))
+	server.ServeHTTP(w, r)
+	var id idResponse
+	json.NewDecoder(w.Body).Decode(&id)
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", url+id.Id+"/points", nil)
+	server.ServeHTTP(w, r)
+	var pointsOutput pointsResponse
+	json.NewDecoder(w.Body).Decode(&pointsOutput)
+	if !cmp.Equal(pointsOutput, pointsResponse{Points: 100, RulesetVersion: 2}) {
+		t.Fatalf("test server with rulesets:\n    expected: %+v\n    got: %+v\n", pointsResponse{Points: 100, RulesetVersion: 2}, pointsOutput)
+	}
+
+	type BreakdownTestCase struct {
+		query              string
+		expectedStatusCode int
+		expectedPoints     int64
+		expectedVersion    int
+	}
+	for _, testCase := range []BreakdownTestCase{
+		{query: "", expectedStatusCode: 200, expectedPoints: 100, expectedVersion: 2},
+		{query: "?rulesetVersion=2", expectedStatusCode: 200, expectedPoints: 100, expectedVersion: 2},
+		{query: "?rulesetVersion=1", expectedStatusCode: 200, expectedPoints: 12, expectedVersion: 1},
+		{query: "?rulesetVersion=9", expectedStatusCode: 404},
+		{query: "?rulesetVersion=latest", expectedStatusCode: 400},
+	} {
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest("GET", url+id.Id+"/points/breakdown"+testCase.query, nil)
+		server.ServeHTTP(w, r)
+		if statusCode := w.Result().StatusCode; statusCode != testCase.expectedStatusCode {
+			t.Fatalf("test server with rulesets ( %s ):\n    expected status code: \"%d\"\n    actual status code: \"%d\"\n", testCase.query, testCase.expectedStatusCode, statusCode)
+		}
+		if testCase.expectedStatusCode != 200 {
+			continue
+		}
+		var breakdown points.Breakdown
+		json.NewDecoder(w.Body).Decode(&breakdown)
+		if breakdown.Points != testCase.expectedPoints || breakdown.RulesetVersion != testCase.expectedVersion {
+			t.Fatalf("test server with rulesets ( %s ):\n    expected: %d points from version %d\n    got: %d points from version %d\n", testCase.query, testCase.expectedPoints, testCase.expectedVersion, breakdown.Points, breakdown.RulesetVersion)
+		}
+	}
+}
+
+func TestServerWithExchangeRates(t *testing.T) {
+	rates, err := exchangerate.ParseTable([]byte(`{"base": "USD", "rates": [{"currency": "EUR", "date": "2024-01-01", "rate": 1.08}]}`), exchangerate.FormatJSON)
+	if err != nil {
+		t.Fatalf("test server with exchange rates: unexpected error ( %v )", err)
+	}
+	server := NewServer(WithExchangeRates(rates))
+	url := "http://localhost:8080/receipts/"
+	eurRate := &exchangerate.Rate{From: money.EUR, To: money.USD, EffectiveDate: "2024-01-01", Rate: "1.08"}
+
+	type ExchangeRateTestCase struct {
+		body           string
+		expectedPoints pointsResponse
+	}
+	for _, testCase := range []ExchangeRateTestCase{
+		{ //10.00 EUR is 10.80 USD, which is neither a round amount nor a multiple of 0.25
+			body:           `{"retailer": "Lidl", "purchaseDate": "2024-02-10", "purchaseTime": "10:15", "currency": "EUR", "total": "10,00", "items": [{"shortDescription": "Coffee Beans", "price": "10,00"}]}`,
+			expectedPoints: pointsResponse{Points: 4 + 3, RulesetVersion: 1, ExchangeRate: eurRate},
+		},
+		{
+			body:           `{"retailer": "Lidl", "purchaseDate": "2024-02-10", "purchaseTime": "10:15", "total": "10.00", "items": [{"shortDescription": "Coffee Beans", "price": "10.00"}]}`,
+			expectedPoints: pointsResponse{Points: 4 + 50 + 25 + 2, RulesetVersion: 1},
+		},
+	} {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", url+"process", strings.NewReader(testCase.body))
+		server.ServeHTTP(w, r)
+		var id idResponse
+		json.NewDecoder(w.Body).Decode(&id)
+
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest("GET", url+id.Id+"/points", nil)
+		server.ServeHTTP(w, r)
+		var pointsOutput pointsResponse
+		json.NewDecoder(w.Body).Decode(&pointsOutput)
+		if !cmp.Equal(pointsOutput, testCase.expectedPoints) {
+			t.Fatalf("test server with exchange rates ( %s ):\n    expected: %+v\n    got: %+v\n", testCase.body, testCase.expectedPoints, pointsOutput)
+		}
+
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest("GET", url+id.Id+"/points/breakdown", nil)
+		server.ServeHTTP(w, r)
+		var breakdown points.Breakdown
+		json.NewDecoder(w.Body).Decode(&breakdown)
+		if breakdown.Points != testCase.expectedPoints.Points || !cmp.Equal(breakdown.ExchangeRate, testCase.expectedPoints.ExchangeRate) {
+			t.Fatalf("test server with exchange rates breakdown ( %s ):\n    expected: %d points with rate %+v\n    got: %d points with rate %+v\n", testCase.body, testCase.expectedPoints.Points, testCase.expectedPoints.ExchangeRate, breakdown.Points, breakdown.ExchangeRate)
+		}
+	}
+
+	// receipts in a currency, or on a date, without a rate can't be scored
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", url+"process", strings.NewReader(`{"retailer": "Tesco", "purchaseDate": "2024-02-10", "purchaseTime": "10:15", "currency": "GBP", "total": "10.00", "items": [{"shortDescription": "Tea", "price": "10.00"}]}`))
+	server.ServeHTTP(w, r)
+	var response validationErrorResponse
+	json.NewDecoder(w.Body).Decode(&response)
+	if statusCode := w.Result().StatusCode; statusCode != 400 || len(response.Problems) != 1 || response.Problems[0].Field != "currency" || response.Problems[0].Code != "ErrRateNotFound" {
+		t.Fatalf("test server with exchange rates ( GBP ):\n    expected status code 400 with an ErrRateNotFound problem\n    got status code %d with %+v\n", statusCode, response.Problems)
+	}
+}
+
+// A receipt posted to the process route with an optional idempotency key
+type submissionRequest struct {
+	Body           string
+	IdempotencyKey string
+}
+
+// The status code of a submission and the response it got, with the original id standing in for the random one
+type submissionOutcome struct {
+	StatusCode int
+	Original   int // the index of the submission the returned id was first returned for, or -1 when no id was returned
+	Duplicate  bool
+}
+
+func TestProcessReceiptDuplicates(t *testing.T) {
+	const targetReceipt = `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "6.49", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}]}`
+	const rewrittenTargetReceipt = `{"retailer": "Target", "purchaseDate": "Jan 01 2022", "purchaseTime": "1:01 PM", "total": "6.490", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}]}`
+	const walgreensReceipt = `{"retailer": "Walgreens", "purchaseDate": "2022-01-02", "purchaseTime": "08:13", "total": "2.65", "items": [{"shortDescription": "Pepsi - 12-oz", "price": "1.25"}, {"shortDescription": "Dasani", "price": "1.40"}]}`
+	const otherWalgreensReceipt = `{"retailer": "Walgreens", "purchaseDate": "2022-01-03", "purchaseTime": "08:13", "total": "2.65", "items": [{"shortDescription": "Pepsi - 12-oz", "price": "1.25"}, {"shortDescription": "Dasani", "price": "1.40"}]}`
+
+	// submitted in order to the same server, each checked against everything submitted before it
+	var testCases []utils.CreationTestingData[submissionRequest, submissionOutcome] = []utils.CreationTestingData[submissionRequest, submissionOutcome]{
+		{Argument: submissionRequest{Body: targetReceipt}, ExpectedResult: submissionOutcome{StatusCode: 200, Original: 0}},
+		{Argument: submissionRequest{Body: targetReceipt}, ExpectedResult: submissionOutcome{StatusCode: 200, Original: 0, Duplicate: true}},
+		{Argument: submissionRequest{Body: rewrittenTargetReceipt}, ExpectedResult: submissionOutcome{StatusCode: 200, Original: 0, Duplicate: true}},
+		{Argument: submissionRequest{Body: walgreensReceipt, IdempotencyKey: "retry-1"}, ExpectedResult: submissionOutcome{StatusCode: 200, Original: 3}},
+		{Argument: submissionRequest{Body: walgreensReceipt, IdempotencyKey: "retry-1"}, ExpectedResult: submissionOutcome{StatusCode: 200, Original: 3, Duplicate: true}},
+		{Argument: submissionRequest{Body: walgreensReceipt, IdempotencyKey: "retry-2"}, ExpectedResult: submissionOutcome{StatusCode: 200, Original: 3, Duplicate: true}}, // the content matches under a different key
+		{Argument: submissionRequest{Body: otherWalgreensReceipt, IdempotencyKey: "retry-1"}, ExpectedResult: submissionOutcome{StatusCode: 422, Original: -1}},
+		{Argument: submissionRequest{Body: otherWalgreensReceipt, IdempotencyKey: strings.Repeat("k", maxIdempotencyKeyLength+1)}, ExpectedResult: submissionOutcome{StatusCode: 400, Original: -1}},
+		{Argument: submissionRequest{Body: otherWalgreensReceipt}, ExpectedResult: submissionOutcome{StatusCode: 200, Original: 8}},
+	}
+	server := NewServer()
+	firstIndexes := map[string]int{}
+	for index, testCase := range testCases {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/process", strings.NewReader(testCase.Argument.Body))
+		if testCase.Argument.IdempotencyKey != "" {
+			r.Header.Set(idempotencyKeyHeader, testCase.Argument.IdempotencyKey)
+		}
+		server.ServeHTTP(w, r)
+		outcome := submissionOutcome{StatusCode: w.Result().StatusCode, Original: -1}
+		if outcome.StatusCode == 200 {
+			var id idResponse
+			json.NewDecoder(w.Body).Decode(&id)
+			if _, seen := firstIndexes[id.Id]; !seen {
+				firstIndexes[id.Id] = index
+			}
+			outcome.Original = firstIndexes[id.Id]
+			outcome.Duplicate = id.Duplicate
+		}
+		errCheck := testCase.CheckTestCase("process receipt duplicates", outcome, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+	storedReceipts, _ := server.store.List()
+	if len(storedReceipts) != 3 {
+		t.Fatalf("process receipt duplicates: expected 3 stored receipts got %d", len(storedReceipts))
+	}
+}
+
+// Retries racing each other, as when a client times out while the first request is still being processed, store the receipt once
+func TestProcessReceiptConcurrentRetries(t *testing.T) {
+	const retryCount = 16
+	server := NewServer()
+	ids := make(chan idResponse, retryCount)
+	var wg sync.WaitGroup
+	for retry := 0; retry < retryCount; retry++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/process", strings.NewReader(validBatchReceipt))
+			r.Header.Set(idempotencyKeyHeader, "retried")
+			server.ServeHTTP(w, r)
+			var id idResponse
+			json.NewDecoder(w.Body).Decode(&id)
+			ids <- id
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	originals := map[string]int{}
+	duplicates := 0
+	for id := range ids {
+		originals[id.Id]++
+		if id.Duplicate {
+			duplicates++
+		}
+	}
+	if len(originals) != 1 || duplicates != retryCount-1 {
+		t.Fatalf("process receipt concurrent retries: expected a single id with %d duplicates got ids ( %v ) with %d duplicates", retryCount-1, originals, duplicates)
+	}
+	if storedReceipts, _ := server.store.List(); len(storedReceipts) != 1 {
+		t.Fatalf("process receipt concurrent retries: expected 1 stored receipt got %d", len(storedReceipts))
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	audit "go-receipt-processor/Audit"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Header clients can set to who is making the request, which is recorded against every change it makes to the stored receipts
+	actorHeader = "X-Actor"
+	// Header clients can set to their own id for the request, which is generated when it is missing and always echoed back in the response
+	requestIdHeader       = "X-Request-Id"
+	maxOriginHeaderLength = 255
+	anonymousActor        = "anonymous"
+)
+
+var (
+	ErrAuditingReceipt = errors.New("auditing receipt")
+)
+
+// Key the origin of a request is kept under in its context
+type originContextKey struct{}
+
+type auditResponse struct {
+	Id     string        `json:"id"`
+	Events []audit.Event `json:"events"` // every change made to the receipt, oldest first
+}
+
+// Reads who made the request and the id it was made under before passing it on, so the changes it makes can be audited
+func withOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := audit.Origin{Actor: r.Header.Get(actorHeader), RequestId: r.Header.Get(requestIdHeader)}
+		if len(origin.Actor) > maxOriginHeaderLength || len(origin.RequestId) > maxOriginHeaderLength {
+			http.Error(w, fmt.Sprintf("The %s and %s headers must be at most %d characters", actorHeader, requestIdHeader, maxOriginHeaderLength), http.StatusBadRequest)
+			return
+		}
+		if origin.Actor == "" {
+			origin.Actor = anonymousActor
+		}
+		if origin.RequestId == "" {
+			origin.RequestId = uuid.New().String()
+		}
+		w.Header().Set(requestIdHeader, origin.RequestId)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), originContextKey{}, origin)))
+	})
+}
+
+// Returns who made the request and the id it was made under
+func requestOrigin(r *http.Request) audit.Origin {
+	origin, _ := r.Context().Value(originContextKey{}).(audit.Origin)
+	return origin
+}
+
+// Records a change to a stored receipt in the audit log
+func (s *Server) recordAudit(origin audit.Origin, action audit.Action, receiptId string, beforeHash string, afterHash string) error {
+	_, err := s.auditLog.Append(audit.Entry{Origin: origin, Action: action, ReceiptId: receiptId, BeforeHash: beforeHash, AfterHash: afterHash})
+	if err != nil {
+		return fmt.Errorf("%w given \"%s\" ... %w", ErrAuditingReceipt, receiptId, err)
+	}
+	return nil
+}
+
+// On GET HTTP Request, outputs every recorded change to the receipt for the ID given within the request, oldest first, in JSON format.
+// The events of a deleted receipt can still be retrieved, ending with its deletion.
+func (s *Server) getReceiptAudit(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	events, err := s.auditLog.History(id)
+	if err != nil {
+		http.Error(w, "The receipt audit could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+	if len(events) == 0 {
+		http.Error(w, "No receipt found for that id", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(auditResponse{Id: id, Events: events})
+	if err != nil {
+		http.Error(w, "The receipt audit could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+}
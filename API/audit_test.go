@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	audit "go-receipt-processor/Audit"
+	utils "go-receipt-processor/TestingUtils"
+	"strings"
+	"testing"
+)
+
+// The parts of an audit event that are checked, leaving out its timestamp and hashes
+type auditOutcome struct {
+	Action    audit.Action
+	Actor     string
+	RequestId string
+}
+
+func getReceiptAudit(t *testing.T, server *Server, id string) []audit.Event {
+	w := sendReceiptRequest(server, "GET", id+"/audit", "", nil)
+	if statusCode := w.Result().StatusCode; statusCode != 200 {
+		t.Fatalf("get audit ( %s ): expected status code 200 got %d", id, statusCode)
+	}
+	var response auditResponse
+	json.NewDecoder(w.Body).Decode(&response)
+	return response.Events
+}
+
+func TestReceiptAudit(t *testing.T) {
+	server := NewServer()
+	w := sendReceiptRequest(server, "POST", "process", originalReceipt, map[string]string{actorHeader: "alice", requestIdHeader: "request-1"})
+	if requestId := w.Result().Header.Get(requestIdHeader); requestId != "request-1" {
+		t.Fatalf("process receipt: expected the request id to be echoed back got ( %s )", requestId)
+	}
+	var submitted idResponse
+	json.NewDecoder(w.Body).Decode(&submitted)
+
+	w = sendReceiptRequest(server, "POST", "process", originalReceipt, map[string]string{actorHeader: "bob"})
+	generatedRequestId := w.Result().Header.Get(requestIdHeader)
+	if generatedRequestId == "" {
+		t.Fatalf("process receipt: expected a request id to be generated")
+	}
+	sendReceiptRequest(server, "PUT", submitted.Id, correctedReceipt, map[string]string{actorHeader: "carol", requestIdHeader: "request-3"})
+	sendReceiptRequest(server, "DELETE", submitted.Id, "", map[string]string{requestIdHeader: "request-4"})
+
+	events := getReceiptAudit(t, server, submitted.Id)
+	outcomes := []auditOutcome{}
+	for _, event := range events {
+		outcomes = append(outcomes, auditOutcome{Action: event.Action, Actor: event.Actor, RequestId: event.RequestId})
+	}
+	testCase := utils.CreationTestingData[string, []auditOutcome]{Argument: submitted.Id, ExpectedResult: []auditOutcome{
+		{Action: audit.ActionProcess, Actor: "alice", RequestId: "request-1"},
+		{Action: audit.ActionResubmit, Actor: "bob", RequestId: generatedRequestId},
+		{Action: audit.ActionAmend, Actor: "carol", RequestId: "request-3"},
+		{Action: audit.ActionDelete, Actor: anonymousActor, RequestId: "request-4"},
+	}}
+	errCheck := testCase.CheckTestCase("get audit", outcomes, nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+
+	// each event starts from the state the event before it left the receipt in
+	if events[0].BeforeHash != "" || events[1].BeforeHash != events[0].AfterHash || events[1].AfterHash != events[0].AfterHash ||
+		events[2].BeforeHash != events[1].AfterHash || events[2].AfterHash == events[2].BeforeHash ||
+		events[3].BeforeHash != events[2].AfterHash || events[3].AfterHash != "" {
+		t.Fatalf("get audit: expected the before and after hashes to follow on from each other got ( %+v )", events)
+	}
+	allEvents, _ := server.auditLog.Events()
+	if err := audit.Verify(allEvents); err != nil {
+		t.Fatalf("verify audit: unexpected error ( %v )", err)
+	}
+}
+
+func TestBatchReceiptAudit(t *testing.T) {
+	server := NewServer()
+	body := "[" + strings.Join(analyticsReceipts[:2], ", ") + "]"
+	w := sendReceiptRequest(server, "POST", "batch", body, map[string]string{actorHeader: "importer", requestIdHeader: "import-1"})
+	var response batchResponse
+	json.NewDecoder(w.Body).Decode(&response)
+	if response.Accepted != 2 {
+		t.Fatalf("process batch: expected both receipts to be accepted got ( %+v )", response)
+	}
+	for _, result := range response.Results {
+		events := getReceiptAudit(t, server, result.Id)
+		if len(events) != 1 || events[0].Action != audit.ActionProcess || events[0].Actor != "importer" || events[0].RequestId != "import-1" {
+			t.Fatalf("get audit ( %s ): expected a single event for the batch request got ( %+v )", result.Id, events)
+		}
+	}
+}
+
+func TestReceiptAuditErrors(t *testing.T) {
+	server := NewServer()
+	tooLong := strings.Repeat("a", maxOriginHeaderLength+1)
+	var testCases []utils.CreationTestingData[map[string]string, int] = []utils.CreationTestingData[map[string]string, int]{
+		{Argument: map[string]string{actorHeader: tooLong}, ExpectedResult: 400},
+		{Argument: map[string]string{requestIdHeader: tooLong}, ExpectedResult: 400},
+		{Argument: map[string]string{actorHeader: "alice"}, ExpectedResult: 200},
+	}
+	for _, testCase := range testCases {
+		w := sendReceiptRequest(server, "POST", "process", originalReceipt, testCase.Argument)
+		errCheck := testCase.CheckTestCase("receipt audit", w.Result().StatusCode, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+	if events, _ := server.auditLog.Events(); len(events) != 1 || events[0].Actor != "alice" {
+		t.Fatalf("process receipt: expected only the accepted request to be audited got ( %+v )", events)
+	}
+	if w := sendReceiptRequest(server, "GET", "unknown/audit", "", nil); w.Result().StatusCode != 404 {
+		t.Fatalf("get audit ( unknown ): expected status code 404 got %d", w.Result().StatusCode)
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	receipt "go-receipt-processor/Receipt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Lines longer than this are rejected without being read into memory, so a single malformed line can not exhaust the server's memory
+const maxReceiptLineBytes = 1 << 20
+
+var (
+	ErrReceiptTooLarge = errors.New("receipt too large")
+)
+
+// A single receipt read from a batch, or the reason it could not be read
+type batchItem struct {
+	index           int
+	line            int // the 1-based line the receipt was on, when it was read from newline delimited json
+	unparsedReceipt receipt.UnparsedReceipt
+	decodeErr       error
+}
+
+// The outcome of a single receipt in a batch, with either the id it was stored under or why it was not stored
+type batchResult struct {
+	line      int
+	Index     int                 `json:"index"`
+	Id        string              `json:"id,omitempty"`
+	Duplicate bool                `json:"duplicate,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	Problems  []validationProblem `json:"problems,omitempty"`
+}
+
+type batchResponse struct {
+	Accepted int           `json:"accepted"`
+	Rejected int           `json:"rejected"`
+	Results  []batchResult `json:"results"`
+}
+
+// Reads the receipts of a batch one at a time, from either a json array or newline delimited json with one receipt per line, sending each to the channel in the order they were submitted.
+// A receipt that is not valid json is sent with the reason it could not be read. A json array that is malformed stops the batch at that receipt, since nothing after it can be read.
+func decodeBatch(body io.Reader, items chan<- batchItem) {
+	defer close(items)
+	reader := bufio.NewReader(body)
+	firstByte, err := peekFirstNonSpace(reader)
+	if err != nil {
+		return // an empty batch
+	}
+	if firstByte == '[' {
+		decodeJSONArrayBatch(reader, items)
+		return
+	}
+	decodeNDJSONBatch(reader, items)
+}
+
+func peekFirstNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		next, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch next[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.ReadByte()
+		default:
+			return next[0], nil
+		}
+	}
+}
+
+func decodeJSONArrayBatch(reader io.Reader, items chan<- batchItem) {
+	decoder := json.NewDecoder(reader)
+	if _, err := decoder.Token(); err != nil { // the opening bracket
+		items <- batchItem{index: 0, decodeErr: err}
+		return
+	}
+	for index := 0; decoder.More(); index++ {
+		var unparsedReceipt receipt.UnparsedReceipt
+		err := decoder.Decode(&unparsedReceipt)
+		items <- batchItem{index: index, unparsedReceipt: unparsedReceipt, decodeErr: err}
+		var typeErr *json.UnmarshalTypeError
+		if err != nil && !errors.As(err, &typeErr) { // a value of the wrong type is skipped over, but the decoder can not recover from a syntax error
+			return
+		}
+	}
+}
+
+// Decodes each non-blank line as a separate receipt, so a bad line only rejects that receipt.
+// Only one line is held in memory at a time.
+func decodeNDJSONBatch(reader *bufio.Reader, items chan<- batchItem) {
+	index := 0
+	buffer := make([]byte, 0, reader.Size())
+	for line := 1; ; line++ {
+		text, tooLong, err := readLine(reader, buffer, maxReceiptLineBytes)
+		if tooLong {
+			items <- batchItem{index: index, line: line, decodeErr: fmt.Errorf("%w on line %d ( receipts can be at most %d bytes )", ErrReceiptTooLarge, line, maxReceiptLineBytes)}
+			index++
+		} else if trimmed := bytes.TrimSpace(text); len(trimmed) > 0 {
+			var unparsedReceipt receipt.UnparsedReceipt
+			decodeErr := json.Unmarshal(trimmed, &unparsedReceipt)
+			items <- batchItem{index: index, line: line, unparsedReceipt: unparsedReceipt, decodeErr: decodeErr}
+			index++
+		}
+		if err != nil {
+			return // io.EOF once every line has been read
+		}
+		buffer = text[:0]
+	}
+}
+
+// Reads the next line into the buffer, skipping over the rest of any line longer than the limit instead of keeping it
+func readLine(reader *bufio.Reader, buffer []byte, limit int) ([]byte, bool, error) {
+	line := buffer[:0]
+	tooLong := false
+	for {
+		fragment, err := reader.ReadSlice('\n')
+		if !tooLong && len(line)+len(fragment) > limit {
+			tooLong = true
+			line = line[:0]
+		} else if !tooLong {
+			line = append(line, fragment...)
+		}
+		if err != bufio.ErrBufferFull {
+			return line, tooLong, err
+		}
+	}
+}
+
+// Parses, scores and stores a single receipt from a batch, recording it against the origin and user of the request the batch was sent with
+func (s *Server) processBatchItem(item batchItem, submitted submission) batchResult {
+	result := batchResult{Index: item.index, line: item.line}
+	if item.decodeErr != nil {
+		result.Error = "The receipt is invalid"
+		result.Problems = collectDecodingProblems(item.decodeErr)
+		return result
+	}
+	stored, problems, err := s.submitReceipt(item.unparsedReceipt, submitted) // a batch has no idempotency key, so only resubmitted content is recognized
+	switch {
+	case errors.Is(err, ErrSubmittedByOtherUser):
+		result.Error = "The receipt was already submitted by a different user"
+	case err != nil:
+		result.Error = getInternalErrorMessage(err)
+	case len(problems) > 0:
+		result.Error = "The receipt is invalid"
+		result.Problems = problems
+	default:
+		result.Id = stored.Id
+		result.Duplicate = stored.Duplicate
+	}
+	return result
+}
+
+// Processes every item sent to the channel with the server's pool of batch workers, passing each result to the handler as soon as it is ready.
+// The handler is called from multiple goroutines.
+func (s *Server) processBatchItems(items <-chan batchItem, submitted submission, handle func(batchResult)) {
+	var wg sync.WaitGroup
+	for worker := 0; worker < s.batchWorkers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				handle(s.processBatchItem(item, submitted))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// On POST HTTP Request, reads a json array or newline delimited json stream of receipts and processes them concurrently.
+// Each receipt is processed the same way as on the "/receipts/process" route, and invalid receipts do not stop the rest of the batch.
+// It then outputs the id or the validation problems of every receipt, in the order they were submitted, in JSON format.
+func (s *Server) processReceiptBatch(w http.ResponseWriter, r *http.Request) {
+	userId, err := requestUserId(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	items := make(chan batchItem, s.batchWorkers)
+	go decodeBatch(r.Body, items)
+
+	response := batchResponse{Results: []batchResult{}}
+	var mutex sync.Mutex
+	s.processBatchItems(items, submission{origin: requestOrigin(r), userId: userId}, func(result batchResult) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		response.Results = append(response.Results, result)
+	})
+	sort.Slice(response.Results, func(i, j int) bool { return response.Results[i].Index < response.Results[j].Index })
+	for _, result := range response.Results {
+		if result.Id != "" {
+			response.Accepted++
+		} else {
+			response.Rejected++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		http.Error(w, "The batch results could not be written", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	utils "go-receipt-processor/TestingUtils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	validBatchReceipt   = `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "6.49", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}]}`
+	invalidBatchReceipt = `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "6.49", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.4a9"}]}`
+)
+
+// The parts of a batch result that do not change between runs, since ids are random
+type batchOutcome struct {
+	Index    int
+	Accepted bool
+	Codes    []string
+}
+
+func summarizeBatch(response batchResponse) []batchOutcome {
+	outcomes := []batchOutcome{}
+	for _, result := range response.Results {
+		outcome := batchOutcome{Index: result.Index, Accepted: result.Id != ""}
+		for _, problem := range result.Problems {
+			outcome.Codes = append(outcome.Codes, problem.Code)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+func TestProcessReceiptBatch(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, []batchOutcome] = []utils.CreationTestingData[string, []batchOutcome]{
+		{
+			Argument: "[" + validBatchReceipt + ", " + invalidBatchReceipt + `, {"retailer": 5}, ` + validBatchReceipt + "]",
+			ExpectedResult: []batchOutcome{
+				{Index: 0, Accepted: true},
+				{Index: 1, Codes: []string{"ErrParsingPrice"}},
+				{Index: 2, Codes: []string{"ErrInvalidJSON"}},
+				{Index: 3, Accepted: true},
+			},
+		},
+		{ //Tests newline delimited json, where blank lines are skipped and a malformed line only rejects that receipt
+			Argument: validBatchReceipt + "\n\n" + `{"retailer": ` + "\n" + invalidBatchReceipt + "\r\n" + validBatchReceipt,
+			ExpectedResult: []batchOutcome{
+				{Index: 0, Accepted: true},
+				{Index: 1, Codes: []string{"ErrInvalidJSON"}},
+				{Index: 2, Codes: []string{"ErrParsingPrice"}},
+				{Index: 3, Accepted: true},
+			},
+		},
+		{ //Tests that a malformed json array stops the batch at the receipt that could not be read
+			Argument: "[" + validBatchReceipt + `, {"retailer": ]` + validBatchReceipt,
+			ExpectedResult: []batchOutcome{
+				{Index: 0, Accepted: true},
+				{Index: 1, Codes: []string{"ErrInvalidJSON"}},
+			},
+		},
+		{Argument: "", ExpectedResult: []batchOutcome{}},
+		{Argument: " [ ] ", ExpectedResult: []batchOutcome{}},
+	}
+	for _, testCase := range testCases {
+		server := NewServer()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/batch", strings.NewReader(testCase.Argument))
+		server.ServeHTTP(w, r)
+		if statusCode := w.Result().StatusCode; statusCode != 200 {
+			t.Fatalf("process receipt batch ( %s ): expected status code 200 got %d", testCase.Argument, statusCode)
+		}
+		var response batchResponse
+		json.NewDecoder(w.Body).Decode(&response)
+		errCheck := testCase.CheckTestCase("process receipt batch", summarizeBatch(response), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+
+		accepted := 0
+		for _, result := range response.Results {
+			if result.Id == "" {
+				continue
+			}
+			accepted++
+			if storedReceipt, err := server.store.Get(result.Id); err != nil || storedReceipt.Points != 12 {
+				t.Fatalf("process receipt batch: expected receipt ( %s ) to be stored with 12 points got ( %+v ) with error ( %v )", result.Id, storedReceipt, err)
+			}
+		}
+		if response.Accepted != accepted || response.Rejected != len(response.Results)-accepted {
+			t.Fatalf("process receipt batch: expected %d accepted and %d rejected got ( %+v )", accepted, len(response.Results)-accepted, response)
+		}
+	}
+}
+
+func TestProcessLargeReceiptBatch(t *testing.T) {
+	const batchSize = 2000
+	server := NewServer(WithBatchWorkers(8))
+	var body strings.Builder
+	for i := 0; i < batchSize; i++ {
+		if i%100 == 99 {
+			body.WriteString(invalidBatchReceipt + "\n")
+		} else {
+			body.WriteString(strings.Replace(validBatchReceipt, "2022-01-01", fmt.Sprintf("%d-01-01", 1000+i), 1) + "\n") // a different year for each, so none are duplicates
+		}
+	}
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/batch", strings.NewReader(body.String()))
+	r.Header.Set("Content-Type", "application/x-ndjson")
+	server.ServeHTTP(w, r)
+
+	var response batchResponse
+	json.NewDecoder(w.Body).Decode(&response)
+	if len(response.Results) != batchSize || response.Accepted != batchSize-batchSize/100 || response.Rejected != batchSize/100 {
+		t.Fatalf("process large receipt batch: expected %d results with %d rejected got %d results with %d rejected", batchSize, batchSize/100, len(response.Results), response.Rejected)
+	}
+	for i, result := range response.Results {
+		if result.Index != i || (result.Id == "") != (i%100 == 99) {
+			t.Fatalf("process large receipt batch: result %d is out of order or has the wrong outcome ( %+v )", i, result)
+		}
+	}
+	storedReceipts, _ := server.store.List()
+	if len(storedReceipts) != response.Accepted {
+		t.Fatalf("process large receipt batch: expected %d stored receipts got %d", response.Accepted, len(storedReceipts))
+	}
+}
+
+func TestProcessReceiptBatchDuplicates(t *testing.T) {
+	server := NewServer(WithBatchWorkers(1)) // one worker, so the first copy is always the one stored
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/batch", strings.NewReader("["+validBatchReceipt+", "+validBatchReceipt+"]"))
+	server.ServeHTTP(w, r)
+	var response batchResponse
+	json.NewDecoder(w.Body).Decode(&response)
+	if len(response.Results) != 2 || response.Results[0].Duplicate || !response.Results[1].Duplicate || response.Results[0].Id != response.Results[1].Id {
+		t.Fatalf("process receipt batch duplicates: expected the second receipt to be a duplicate of the first got ( %+v )", response.Results)
+	}
+	if storedReceipts, _ := server.store.List(); len(storedReceipts) != 1 {
+		t.Fatalf("process receipt batch duplicates: expected 1 stored receipt got %d", len(storedReceipts))
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+// The outcome of a single line of an ingested stream, with either the id the receipt was stored under or why it was not stored
+type ingestRecord struct {
+	Line      int                 `json:"line"`
+	Id        string              `json:"id,omitempty"`
+	Duplicate bool                `json:"duplicate,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	Problems  []validationProblem `json:"problems,omitempty"`
+}
+
+// On POST HTTP Request, reads a newline delimited json stream of receipts, one per line, and processes them concurrently as they arrive.
+// Only a bounded number of receipts are held in memory at once, so streams of any size can be ingested.
+// It outputs a newline delimited json record for every receipt as soon as it has been processed, with the line it was on and either its id or its validation problems, so the records are not necessarily in the order of the lines.
+func (s *Server) ingestReceipts(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-ndjson" {
+		http.Error(w, "Receipts must be sent as application/x-ndjson, with one receipt per line", http.StatusUnsupportedMediaType)
+		return
+	}
+	userId, err := requestUserId(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	controller := http.NewResponseController(w)
+	controller.EnableFullDuplex() // lets records be written while the rest of the stream is still being read, where the protocol supports it
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	items := make(chan batchItem, s.batchWorkers)
+	go func() {
+		defer close(items)
+		decodeNDJSONBatch(bufio.NewReader(r.Body), items)
+	}()
+
+	encoder := json.NewEncoder(w)
+	var mutex sync.Mutex
+	s.processBatchItems(items, submission{origin: requestOrigin(r), userId: userId}, func(result batchResult) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		encoder.Encode(ingestRecord{Line: result.line, Id: result.Id, Duplicate: result.Duplicate, Error: result.Error, Problems: result.Problems})
+		controller.Flush()
+	})
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	utils "go-receipt-processor/TestingUtils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// The parts of an ingest record that do not change between runs, since ids are random
+type ingestOutcome struct {
+	Line     int
+	Accepted bool
+	Codes    []string
+}
+
+func TestIngestReceipts(t *testing.T) {
+	tooLong := `{"retailer": "` + strings.Repeat("a", maxReceiptLineBytes) + `"}`
+	var testCases []utils.CreationTestingData[string, []ingestOutcome] = []utils.CreationTestingData[string, []ingestOutcome]{
+		{
+			Argument: validBatchReceipt + "\n\n" + invalidBatchReceipt + "\n" + `{"retailer": ` + "\n" + tooLong + "\n" + validBatchReceipt,
+			ExpectedResult: []ingestOutcome{
+				{Line: 1, Accepted: true},
+				{Line: 3, Codes: []string{"ErrParsingPrice"}},
+				{Line: 4, Codes: []string{"ErrInvalidJSON"}},
+				{Line: 5, Codes: []string{"ErrReceiptTooLarge"}},
+				{Line: 6, Accepted: true},
+			},
+		},
+		{Argument: "", ExpectedResult: []ingestOutcome{}},
+	}
+	for _, testCase := range testCases {
+		server := NewServer(WithBatchWorkers(3))
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/ingest", strings.NewReader(testCase.Argument))
+		r.Header.Set("Content-Type", "application/x-ndjson; charset=utf-8")
+		server.ServeHTTP(w, r)
+		if contentType := w.Result().Header.Get("Content-Type"); w.Result().StatusCode != 200 || contentType != "application/x-ndjson" {
+			t.Fatalf("ingest receipts: expected status code 200 with newline delimited json got %d with %s", w.Result().StatusCode, contentType)
+		}
+
+		outcomes := []ingestOutcome{}
+		decoder := json.NewDecoder(w.Body)
+		for decoder.More() {
+			var record ingestRecord
+			if err := decoder.Decode(&record); err != nil {
+				t.Fatalf("ingest receipts: unexpected error decoding a record ( %v )", err)
+			}
+			outcome := ingestOutcome{Line: record.Line, Accepted: record.Id != ""}
+			for _, problem := range record.Problems {
+				outcome.Codes = append(outcome.Codes, problem.Code)
+			}
+			outcomes = append(outcomes, outcome)
+		}
+		sort.Slice(outcomes, func(i, j int) bool { return outcomes[i].Line < outcomes[j].Line }) // records are written in the order they finish
+		errCheck := testCase.CheckTestCase("ingest receipts", outcomes, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func TestIngestReceiptsRequiresNDJSON(t *testing.T) {
+	server := NewServer()
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/ingest", strings.NewReader(validBatchReceipt))
+	r.Header.Set("Content-Type", "application/json")
+	server.ServeHTTP(w, r)
+	if statusCode := w.Result().StatusCode; statusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("ingest receipts: expected status code %d got %d", http.StatusUnsupportedMediaType, statusCode)
+	}
+}
+
+// Writes one receipt at a time and waits for its record before writing the next, which only works if records are streamed back while the request is still being sent
+func TestIngestReceiptsStreamsRecords(t *testing.T) {
+	httpServer := httptest.NewServer(NewServer(WithBatchWorkers(1)))
+	defer httpServer.Close()
+
+	bodyReader, bodyWriter := io.Pipe()
+	r, _ := http.NewRequest("POST", httpServer.URL+"/receipts/ingest", bodyReader)
+	r.Header.Set("Content-Type", "application/x-ndjson")
+
+	responses := make(chan *http.Response, 1)
+	errs := make(chan error, 1)
+	go func() {
+		response, err := http.DefaultClient.Do(r)
+		if err != nil {
+			errs <- err
+			return
+		}
+		responses <- response
+	}()
+
+	io.WriteString(bodyWriter, validBatchReceipt+"\n")
+	var response *http.Response
+	select {
+	case response = <-responses:
+	case err := <-errs:
+		t.Fatalf("ingest receipts stream: unexpected error ( %v )", err)
+	}
+	defer response.Body.Close()
+	records := bufio.NewReader(response.Body)
+	for line := 1; line <= 3; line++ {
+		if line > 1 {
+			io.WriteString(bodyWriter, validBatchReceipt+"\n")
+		}
+		text, err := records.ReadBytes('\n')
+		var record ingestRecord
+		if err != nil || json.Unmarshal(text, &record) != nil || record.Line != line || record.Id == "" {
+			t.Fatalf("ingest receipts stream: expected a record for line %d got ( %s ) with error ( %v )", line, text, err)
+		}
+	}
+	bodyWriter.Close()
+	if rest, _ := io.ReadAll(records); len(rest) != 0 {
+		t.Fatalf("ingest receipts stream: unexpected records after the stream ended ( %s )", rest)
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	store "go-receipt-processor/Store"
+	time "go-receipt-processor/Time"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 500
+)
+
+var (
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrInvalidLimit        = errors.New("invalid limit")
+	ErrInvalidPoints       = errors.New("invalid points")
+	ErrInvalidIgnoreCase   = errors.New("invalid ignore case")
+	ErrInvalidSearchAmount = errors.New("invalid amount")
+)
+
+// Sentinel errors found in search parameters, checked before the codes of submitted receipts so dates are reported the same way as they are on receipts
+var searchValidationCodes = []validationCode{
+	{"ErrUnknownCurrency", money.ErrUnknownCurrency},
+	{"ErrUnknownRetailerMatch", store.ErrUnknownRetailerMatch},
+	{"ErrInvalidIgnoreCase", ErrInvalidIgnoreCase},
+	{"ErrInvalidSearchAmount", ErrInvalidSearchAmount},
+	{"ErrInvalidPoints", ErrInvalidPoints},
+	{"ErrInvalidCursor", ErrInvalidCursor},
+	{"ErrInvalidLimit", ErrInvalidLimit},
+}
+
+type searchResponse struct {
+	Receipts   []receiptResponse `json:"receipts"`
+	NextCursor string            `json:"nextCursor,omitempty"` // passed back as the "cursor" parameter to get the next page, omitted on the last page
+}
+
+// Cursors are opaque to clients, so the way pages are keyed can change without breaking them
+func encodeCursor(afterId string) string {
+	if afterId == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(afterId))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	afterId, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", fmt.Errorf("%w given \"%s\" ... %w", ErrInvalidCursor, cursor, err)
+	}
+	return string(afterId), nil
+}
+
+// Reads the search filters from the query parameters, collecting a problem for every parameter that could not be read rather than stopping at the first
+func parseSearchQuery(parameters url.Values) (store.Query, []validationProblem) {
+	query := store.Query{
+		Retailer:        parameters.Get("retailer"),
+		ItemDescription: parameters.Get("item"),
+		Limit:           defaultSearchLimit,
+	}
+	problems := []validationProblem{}
+	addProblem := func(parameter string, err error) {
+		problems = append(problems, validationProblem{Field: parameter, Code: getValidationCode(err, searchValidationCodes...), Message: err.Error()})
+	}
+
+	var err error
+	if query.RetailerMatch, err = store.ParseRetailerMatch(parameters.Get("retailerMatch")); err != nil {
+		addProblem("retailerMatch", err)
+	}
+	if ignoreCase := parameters.Get("retailerIgnoreCase"); ignoreCase != "" {
+		if query.RetailerIgnoreCase, err = strconv.ParseBool(ignoreCase); err != nil {
+			addProblem("retailerIgnoreCase", fmt.Errorf("%w given \"%s\" ( expected true or false )", ErrInvalidIgnoreCase, ignoreCase))
+		}
+	}
+	for _, dateParameter := range []struct {
+		name   string
+		target **date.Date
+	}{{"purchaseDateFrom", &query.PurchaseDateFrom}, {"purchaseDateTo", &query.PurchaseDateTo}} {
+		if value := parameters.Get(dateParameter.name); value != "" {
+			parsedDate, err := date.ParseDate(value, true)
+			if err != nil {
+				addProblem(dateParameter.name, err)
+				continue
+			}
+			*dateParameter.target = &parsedDate
+		}
+	}
+	for _, timeParameter := range []struct {
+		name   string
+		target **time.Time
+	}{{"purchaseTimeFrom", &query.PurchaseTimeFrom}, {"purchaseTimeTo", &query.PurchaseTimeTo}} {
+		if value := parameters.Get(timeParameter.name); value != "" {
+			parsedTime, err := time.ParseTime(value, true)
+			if err != nil {
+				addProblem(timeParameter.name, err)
+				continue
+			}
+			*timeParameter.target = &parsedTime
+		}
+	}
+	if currency := parameters.Get("currency"); currency != "" {
+		if query.Currency, err = money.ParseCurrency(currency); err != nil {
+			addProblem("currency", err)
+		}
+	}
+	for _, totalParameter := range []struct {
+		name   string
+		target **money.Money
+	}{{"totalMin", &query.TotalMin}, {"totalMax", &query.TotalMax}} {
+		if value := parameters.Get(totalParameter.name); value != "" {
+			amount, err := money.ParseIn(value, query.Currency.OrDefault())
+			if err != nil {
+				addProblem(totalParameter.name, fmt.Errorf("%w ... %w", ErrInvalidSearchAmount, err))
+				continue
+			}
+			*totalParameter.target = &amount
+		}
+	}
+	for _, pointsParameter := range []struct {
+		name   string
+		target **int64
+	}{{"pointsMin", &query.PointsMin}, {"pointsMax", &query.PointsMax}} {
+		if value := parameters.Get(pointsParameter.name); value != "" {
+			points, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				addProblem(pointsParameter.name, fmt.Errorf("%w given \"%s\" ( expected a whole number )", ErrInvalidPoints, value))
+				continue
+			}
+			*pointsParameter.target = &points
+		}
+	}
+	if cursor := parameters.Get("cursor"); cursor != "" {
+		if query.AfterId, err = decodeCursor(cursor); err != nil {
+			addProblem("cursor", err)
+		}
+	}
+	if limit := parameters.Get("limit"); limit != "" {
+		parsedLimit, err := strconv.Atoi(limit)
+		if err != nil || parsedLimit < 1 || parsedLimit > maxSearchLimit {
+			addProblem("limit", fmt.Errorf("%w given \"%s\" ( expected a whole number from 1 to %d )", ErrInvalidLimit, limit, maxSearchLimit))
+		} else {
+			query.Limit = parsedLimit
+		}
+	}
+	return query, problems
+}
+
+// On GET HTTP Request, finds the stored receipts matching the filters given as query parameters, a page at a time ordered by id.
+// It outputs each receipt in the same form as getReceipt, along with a cursor for the next page if there are more matches, in JSON format.
+func (s *Server) searchReceipts(w http.ResponseWriter, r *http.Request) {
+	query, problems := parseSearchQuery(r.URL.Query())
+	if len(problems) > 0 {
+		writeProblems(w, "The search is invalid", problems)
+		return
+	}
+	page, err := s.store.Search(query)
+	if err != nil {
+		http.Error(w, "The receipts could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+	response := searchResponse{Receipts: make([]receiptResponse, 0, len(page.Receipts)), NextCursor: encodeCursor(page.NextAfterId)}
+	for _, storedReceipt := range page.Receipts {
+		response.Receipts = append(response.Receipts, newReceiptResponse(storedReceipt))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		http.Error(w, "The receipts could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+}
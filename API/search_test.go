@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	utils "go-receipt-processor/TestingUtils"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// The retailers of each page of a search, following the cursors until there are none left
+func searchPages(t *testing.T, server *Server, parameters string) [][]string {
+	pages := [][]string{}
+	cursor := ""
+	for {
+		url := "http://localhost:8080/receipts?" + parameters
+		if cursor != "" {
+			url += "&cursor=" + cursor
+		}
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", url, nil)
+		server.ServeHTTP(w, r)
+		if statusCode := w.Result().StatusCode; statusCode != 200 {
+			t.Fatalf("search receipts ( %s ): expected status code 200 got %d ( %s )", parameters, statusCode, w.Body.String())
+		}
+		var response searchResponse
+		json.NewDecoder(w.Body).Decode(&response)
+		retailers := []string{}
+		for _, receipt := range response.Receipts {
+			retailers = append(retailers, receipt.Retailer+" "+receipt.PurchaseDate)
+		}
+		pages = append(pages, retailers)
+		if response.NextCursor == "" {
+			return pages
+		}
+		cursor = response.NextCursor
+	}
+}
+
+func TestSearchReceipts(t *testing.T) {
+	server := NewServer()
+	submitted := []string{
+		`{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "6.49", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}]}`,
+		`{"retailer": "Target", "purchaseDate": "2022-01-02", "purchaseTime": "23:30", "total": "12.25", "items": [{"shortDescription": "Emils Cheese Pizza", "price": "12.25"}]}`,
+		`{"retailer": "target express", "purchaseDate": "2022-02-01", "purchaseTime": "08:15", "total": "1.26", "items": [{"shortDescription": "Knorr Creamy Chicken", "price": "1.26"}]}`,
+		`{"retailer": "Walgreens", "purchaseDate": "2022-03-20", "purchaseTime": "14:33", "total": "9.00", "items": [{"shortDescription": "Gatorade", "price": "9.00"}]}`,
+		`{"retailer": "Walgreens", "purchaseDate": "2022-03-21", "purchaseTime": "14:33", "total": "900", "currency": "JPY", "items": [{"shortDescription": "Gatorade", "price": "900"}]}`,
+	}
+	for _, body := range submitted {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "http://localhost:8080/receipts/process", strings.NewReader(body))
+		server.ServeHTTP(w, r)
+		if statusCode := w.Result().StatusCode; statusCode != 200 {
+			t.Fatalf("search receipts: expected status code 200 submitting ( %s ) got %d", body, statusCode)
+		}
+	}
+
+	// ids are random, so the order of the results is checked by sorting them
+	var testCases []utils.CreationTestingData[string, []string] = []utils.CreationTestingData[string, []string]{
+		{Argument: "", ExpectedResult: []string{"Target 2022-01-01", "Target 2022-01-02", "Walgreens 2022-03-20", "Walgreens 2022-03-21", "target express 2022-02-01"}},
+		{Argument: "retailer=Target", ExpectedResult: []string{"Target 2022-01-01", "Target 2022-01-02"}},
+		{Argument: "retailer=TARGET&retailerMatch=prefix&retailerIgnoreCase=true", ExpectedResult: []string{"Target 2022-01-01", "Target 2022-01-02", "target express 2022-02-01"}},
+		{Argument: "purchaseDateFrom=2022-01-02&purchaseDateTo=2022-03-20", ExpectedResult: []string{"Target 2022-01-02", "Walgreens 2022-03-20", "target express 2022-02-01"}},
+		{Argument: "purchaseTimeFrom=22:00&purchaseTimeTo=09:00", ExpectedResult: []string{"Target 2022-01-02", "target express 2022-02-01"}},
+		{Argument: "currency=USD&totalMin=6.49&totalMax=9", ExpectedResult: []string{"Target 2022-01-01", "Walgreens 2022-03-20"}},
+		{Argument: "currency=JPY&totalMin=900", ExpectedResult: []string{"Walgreens 2022-03-21"}},
+		{Argument: "pointsMin=34&pointsMax=94", ExpectedResult: []string{"Target 2022-01-02", "Walgreens 2022-03-20"}},
+		{Argument: "item=CHEESE", ExpectedResult: []string{"Target 2022-01-02"}},
+		{Argument: "retailer=Costco", ExpectedResult: []string{}},
+	}
+	for _, testCase := range testCases {
+		pages := searchPages(t, server, testCase.Argument)
+		if len(pages) != 1 {
+			t.Fatalf("search receipts ( %s ): expected a single page got %d", testCase.Argument, len(pages))
+		}
+		sortedRetailers := append([]string{}, pages[0]...)
+		sort.Strings(sortedRetailers)
+		errCheck := testCase.CheckTestCase("search receipts", sortedRetailers, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	// following the cursors visits every receipt once, in pages of the requested size
+	pages := searchPages(t, server, "limit=2")
+	seen := map[string]bool{}
+	for i, page := range pages {
+		if expectedLength := []int{2, 2, 1}[i]; len(page) != expectedLength {
+			t.Fatalf("search receipts ( limit=2 ): expected page %d to have %d receipts got %d", i, expectedLength, len(page))
+		}
+		for _, retailer := range page {
+			if seen[retailer] {
+				t.Fatalf("search receipts ( limit=2 ): receipt ( %s ) was returned on more than one page", retailer)
+			}
+			seen[retailer] = true
+		}
+	}
+	if len(pages) != 3 || len(seen) != len(submitted) {
+		t.Fatalf("search receipts ( limit=2 ): expected %d receipts over 3 pages got %d over %d pages", len(submitted), len(seen), len(pages))
+	}
+}
+
+func TestSearchReceiptsValidationErrors(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, []validationProblem] = []utils.CreationTestingData[string, []validationProblem]{
+		{Argument: "retailerMatch=suffix", ExpectedResult: []validationProblem{{Field: "retailerMatch", Code: "ErrUnknownRetailerMatch"}}},
+		{Argument: "retailerIgnoreCase=maybe", ExpectedResult: []validationProblem{{Field: "retailerIgnoreCase", Code: "ErrInvalidIgnoreCase"}}},
+		{Argument: "purchaseDateFrom=2022-02-30&purchaseTimeTo=25:00", ExpectedResult: []validationProblem{{Field: "purchaseDateFrom", Code: "ErrInvalidDate"}, {Field: "purchaseTimeTo", Code: "ErrInvalidHour"}}},
+		{Argument: "currency=XYZ&totalMin=abc", ExpectedResult: []validationProblem{{Field: "currency", Code: "ErrUnknownCurrency"}, {Field: "totalMin", Code: "ErrInvalidSearchAmount"}}},
+		{Argument: "pointsMax=1.5", ExpectedResult: []validationProblem{{Field: "pointsMax", Code: "ErrInvalidPoints"}}},
+		{Argument: "cursor=!!!&limit=0", ExpectedResult: []validationProblem{{Field: "cursor", Code: "ErrInvalidCursor"}, {Field: "limit", Code: "ErrInvalidLimit"}}},
+		{Argument: "limit=501", ExpectedResult: []validationProblem{{Field: "limit", Code: "ErrInvalidLimit"}}},
+	}
+	server := NewServer()
+	for _, testCase := range testCases {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "http://localhost:8080/receipts?"+testCase.Argument, nil)
+		server.ServeHTTP(w, r)
+		if statusCode := w.Result().StatusCode; statusCode != 400 {
+			t.Fatalf("search receipts ( %s ): expected status code 400 got %d", testCase.Argument, statusCode)
+		}
+		var response validationErrorResponse
+		json.NewDecoder(w.Body).Decode(&response)
+		for i := range response.Problems {
+			response.Problems[i].Message = "" // messages are checked by the packages that produce them
+		}
+		errCheck := testCase.CheckTestCase("search receipts validation errors", response.Problems, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
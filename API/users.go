@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	ledger "go-receipt-processor/Ledger"
+	"net/http"
+	systime "time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Header clients set to the user a submitted receipt belongs to, who is credited with its points. Receipts submitted without one are not credited to anyone.
+	userIdHeader    = "X-User-Id"
+	maxUserIdLength = 255
+)
+
+var (
+	ErrInvalidUserId   = errors.New("invalid user id")
+	ErrCreditingPoints = errors.New("crediting receipt points")
+	ErrAdjustingPoints = errors.New("adjusting receipt points")
+)
+
+type balanceResponse struct {
+	UserId  string `json:"userId"`
+	Balance int64  `json:"balance"`
+}
+
+// A single transaction as it affected the user's points
+type ledgerEntryResponse struct {
+	Id          int64       `json:"id"`
+	Kind        ledger.Kind `json:"kind"`
+	ReceiptId   string      `json:"receiptId,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Points      int64       `json:"points"`  // the points added to the user's balance, negative when points were taken out
+	Balance     int64       `json:"balance"` // the user's balance after the transaction
+	Timestamp   string      `json:"timestamp"`
+}
+
+type ledgerResponse struct {
+	UserId  string                `json:"userId"`
+	Balance int64                 `json:"balance"`
+	Entries []ledgerEntryResponse `json:"entries"` // oldest first
+}
+
+// Returns the user the request's receipts are credited to, which is empty when the header is missing
+func requestUserId(r *http.Request) (string, error) {
+	userId := r.Header.Get(userIdHeader)
+	if len(userId) > maxUserIdLength {
+		return "", fmt.Errorf("%w ... the %s header must be at most %d characters", ErrInvalidUserId, userIdHeader, maxUserIdLength)
+	}
+	return userId, nil
+}
+
+func newLedgerEntryResponse(transaction ledger.Transaction, balance int64) ledgerEntryResponse {
+	return ledgerEntryResponse{
+		Id:          transaction.Id,
+		Kind:        transaction.Kind,
+		ReceiptId:   transaction.ReceiptId,
+		Description: transaction.Description,
+		Points:      transaction.UserPoints(),
+		Balance:     balance,
+		Timestamp:   transaction.Timestamp.Format(systime.RFC3339Nano),
+	}
+}
+
+// Adjusts the points credited for an amended or deleted receipt to what it is now worth
+func (s *Server) adjustReceiptPoints(receiptId string, points int64, description string) error {
+	_, _, err := s.ledger.AdjustReceipt(receiptId, points, description)
+	if err != nil {
+		return fmt.Errorf("%w given \"%s\" ... %w", ErrAdjustingPoints, receiptId, err)
+	}
+	return nil
+}
+
+// On GET HTTP Request, outputs the points balance of the user for the ID given within the request, in JSON format.
+func (s *Server) getUserBalance(w http.ResponseWriter, r *http.Request) {
+	userId := mux.Vars(r)["id"]
+	balance, found, err := s.ledger.Balance(userId)
+	if err != nil {
+		http.Error(w, "The balance could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "No user found for that id", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(balanceResponse{UserId: userId, Balance: balance})
+	if err != nil {
+		http.Error(w, "The balance could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+}
+
+// On GET HTTP Request, outputs every credit, redemption and adjustment of the points of the user for the ID given within the request, oldest first, with the balance after each, in JSON format.
+func (s *Server) getUserLedger(w http.ResponseWriter, r *http.Request) {
+	userId := mux.Vars(r)["id"]
+	statement, err := s.ledger.Statement(userId)
+	if err != nil {
+		http.Error(w, "The ledger could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+	if len(statement) == 0 {
+		http.Error(w, "No user found for that id", http.StatusNotFound)
+		return
+	}
+	response := ledgerResponse{UserId: userId, Entries: make([]ledgerEntryResponse, 0, len(statement))}
+	for _, transaction := range statement {
+		response.Balance += transaction.UserPoints()
+		response.Entries = append(response.Entries, newLedgerEntryResponse(transaction, response.Balance))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		http.Error(w, "The ledger could not be retrieved", http.StatusInternalServerError)
+		return
+	}
+}
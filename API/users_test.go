@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	ledger "go-receipt-processor/Ledger"
+	utils "go-receipt-processor/TestingUtils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// The parts of a ledger entry that are checked, leaving out its id, receipt and timestamp
+type ledgerOutcome struct {
+	Kind    ledger.Kind
+	Points  int64
+	Balance int64
+}
+
+func sendUserRequest(server *Server, method string, path string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(method, "http://localhost:8080/users/"+path, strings.NewReader(body))
+	server.ServeHTTP(w, r)
+	return w
+}
+
+func submitUserReceipt(t *testing.T, server *Server, userId string, body string) string {
+	w := sendReceiptRequest(server, "POST", "process", body, map[string]string{userIdHeader: userId})
+	if statusCode := w.Result().StatusCode; statusCode != 200 {
+		t.Fatalf("process receipt ( %s ): expected status code 200 got %d", userId, statusCode)
+	}
+	var submitted idResponse
+	json.NewDecoder(w.Body).Decode(&submitted)
+	return submitted.Id
+}
+
+func getUserBalance(t *testing.T, server *Server, userId string) int64 {
+	w := sendUserRequest(server, "GET", userId+"/balance", "")
+	if statusCode := w.Result().StatusCode; statusCode != 200 {
+		t.Fatalf("get balance ( %s ): expected status code 200 got %d", userId, statusCode)
+	}
+	var balance balanceResponse
+	json.NewDecoder(w.Body).Decode(&balance)
+	return balance.Balance
+}
+
+func TestUserLedger(t *testing.T) {
+	server := NewServer()
+	amendedId := submitUserReceipt(t, server, "alice", originalReceipt)
+	w := sendReceiptRequest(server, "POST", "batch", "["+analyticsReceipts[1]+"]", map[string]string{userIdHeader: "alice"})
+	var batch batchResponse
+	json.NewDecoder(w.Body).Decode(&batch)
+	deletedId := batch.Results[0].Id
+
+	// a resubmitted receipt is only recognized for the user who first submitted it, anyone else is refused without being given its id
+	if resubmittedId := submitUserReceipt(t, server, "alice", originalReceipt); resubmittedId != amendedId {
+		t.Fatalf("process receipt ( alice ): expected the id of the original ( %s ) got ( %s )", amendedId, resubmittedId)
+	}
+	for _, userId := range []string{"bob", ""} {
+		w := sendReceiptRequest(server, "POST", "process", originalReceipt, map[string]string{userIdHeader: userId})
+		if w.Result().StatusCode != 409 || strings.Contains(w.Body.String(), amendedId) {
+			t.Fatalf("process receipt ( %s ): expected status code 409 without the original's id got %d ( %s )", userId, w.Result().StatusCode, w.Body.String())
+		}
+	}
+	w = sendReceiptRequest(server, "POST", "batch", "["+analyticsReceipts[1]+"]", map[string]string{userIdHeader: "bob"})
+	var refused batchResponse
+	json.NewDecoder(w.Body).Decode(&refused)
+	if result := refused.Results[0]; result.Id != "" || result.Error != "The receipt was already submitted by a different user" {
+		t.Fatalf("process batch ( bob ): expected an error without the original's id got ( %+v )", result)
+	}
+	// receipts without a user are not credited to anyone
+	submitUserReceipt(t, server, "", analyticsReceipts[2])
+	if balance := getUserBalance(t, server, "alice"); balance != 46 {
+		t.Fatalf("get balance ( alice ): expected 46 got %d", balance)
+	}
+	if w := sendUserRequest(server, "GET", "bob/balance", ""); w.Result().StatusCode != 404 {
+		t.Fatalf("get balance ( bob ): expected status code 404 got %d", w.Result().StatusCode)
+	}
+
+	// only the user who submitted a receipt can amend or delete it
+	for _, userId := range []string{"bob", ""} {
+		for _, method := range []string{"PUT", "DELETE"} {
+			if w := sendReceiptRequest(server, method, amendedId, correctedReceipt, map[string]string{userIdHeader: userId}); w.Result().StatusCode != 403 {
+				t.Fatalf("%s receipt ( %s ): expected status code 403 got %d", method, userId, w.Result().StatusCode)
+			}
+		}
+	}
+	sendReceiptRequest(server, "PUT", amendedId, correctedReceipt, map[string]string{userIdHeader: "alice"})
+	// redemptions and adjustments by hand are only made through the ledger, never by clients of the api
+	if _, err := server.ledger.Redeem("alice", 40, "gift card"); err != nil {
+		t.Fatalf("redeem ( alice ): unexpected error ( %v )", err)
+	}
+	if _, err := server.ledger.Adjust("alice", 5, "goodwill"); err != nil {
+		t.Fatalf("adjust ( alice ): unexpected error ( %v )", err)
+	}
+	sendReceiptRequest(server, "DELETE", deletedId, "", map[string]string{userIdHeader: "alice"})
+
+	w = sendUserRequest(server, "GET", "alice/ledger", "")
+	var response ledgerResponse
+	json.NewDecoder(w.Body).Decode(&response)
+	outcomes := []ledgerOutcome{}
+	for _, entry := range response.Entries {
+		outcomes = append(outcomes, ledgerOutcome{Kind: entry.Kind, Points: entry.Points, Balance: entry.Balance})
+	}
+	ledgerTestCase := utils.CreationTestingData[string, []ledgerOutcome]{Argument: "alice", ExpectedResult: []ledgerOutcome{
+		{Kind: ledger.KindCredit, Points: 12, Balance: 12},
+		{Kind: ledger.KindCredit, Points: 34, Balance: 46},
+		{Kind: ledger.KindAdjustment, Points: 3, Balance: 49},
+		{Kind: ledger.KindRedemption, Points: -40, Balance: 9},
+		{Kind: ledger.KindAdjustment, Points: 5, Balance: 14},
+		{Kind: ledger.KindAdjustment, Points: -34, Balance: -20},
+	}}
+	errCheck := ledgerTestCase.CheckTestCase("get ledger", outcomes, nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+	if response.Balance != -20 || response.Entries[0].ReceiptId != amendedId || response.Entries[5].ReceiptId != deletedId {
+		t.Fatalf("get ledger: expected a balance of -20 with entries for each receipt got ( %+v )", response)
+	}
+	if err := server.ledger.Check(); err != nil {
+		t.Fatalf("check ledger: unexpected error ( %v )", err)
+	}
+}
+
+// Ledger that fails to credit receipts while it is unavailable, as if it could not be written to
+type unavailableLedger struct {
+	*ledger.MemoryLedger
+	unavailable bool
+}
+
+func (l *unavailableLedger) Credit(userId string, receiptId string, points int64) (ledger.Transaction, error) {
+	if l.unavailable {
+		return ledger.Transaction{}, ledger.ErrWritingLedger
+	}
+	return l.MemoryLedger.Credit(userId, receiptId, points)
+}
+
+func TestUserLedgerRetriesFailedCredits(t *testing.T) {
+	pointsLedger := &unavailableLedger{MemoryLedger: ledger.NewMemoryLedger(), unavailable: true}
+	server := NewServer(WithLedger(pointsLedger))
+	if w := sendReceiptRequest(server, "POST", "process", originalReceipt, map[string]string{userIdHeader: "alice"}); w.Result().StatusCode != 500 {
+		t.Fatalf("process receipt ( alice ): expected status code 500 while the ledger is unavailable got %d", w.Result().StatusCode)
+	}
+	// the receipt was stored with its user, so it is still refused to anyone else
+	if w := sendReceiptRequest(server, "POST", "process", originalReceipt, map[string]string{userIdHeader: "bob"}); w.Result().StatusCode != 409 {
+		t.Fatalf("process receipt ( bob ): expected status code 409 got %d", w.Result().StatusCode)
+	}
+	pointsLedger.unavailable = false
+	// retrying finishes the credit, and retrying again does not credit the receipt twice
+	for attempt := 1; attempt <= 2; attempt++ {
+		w := sendReceiptRequest(server, "POST", "process", originalReceipt, map[string]string{userIdHeader: "alice"})
+		var submitted idResponse
+		json.NewDecoder(w.Body).Decode(&submitted)
+		if w.Result().StatusCode != 200 || !submitted.Duplicate {
+			t.Fatalf("process receipt ( alice attempt %d ): expected status code 200 as a duplicate got %d ( %+v )", attempt, w.Result().StatusCode, submitted)
+		}
+		if balance := getUserBalance(t, server, "alice"); balance != 12 {
+			t.Fatalf("get balance ( alice attempt %d ): expected 12 got %d", attempt, balance)
+		}
+	}
+}
+
+func TestUserLedgerErrors(t *testing.T) {
+	server := NewServer()
+	tooLong := strings.Repeat("a", maxUserIdLength+1)
+	var testCases []utils.CreationTestingData[[3]string, int] = []utils.CreationTestingData[[3]string, int]{
+		{Argument: [3]string{"process", originalReceipt, tooLong}, ExpectedResult: 400},
+		{Argument: [3]string{"batch", "[" + originalReceipt + "]", tooLong}, ExpectedResult: 400},
+	}
+	for _, testCase := range testCases {
+		w := sendReceiptRequest(server, "POST", testCase.Argument[0], testCase.Argument[1], map[string]string{userIdHeader: testCase.Argument[2]})
+		errCheck := testCase.CheckTestCase("process receipt", w.Result().StatusCode, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+	for _, path := range []string{"unknown/balance", "unknown/ledger"} {
+		if w := sendUserRequest(server, "GET", path, ""); w.Result().StatusCode != 404 {
+			t.Fatalf("get %s: expected status code 404 got %d", path, w.Result().StatusCode)
+		}
+	}
+	for _, path := range []string{"alice/redemptions", "alice/adjustments", "alice/balance"} {
+		if w := sendUserRequest(server, "POST", path, `{"points": 1, "description": "free points"}`); w.Result().StatusCode < 400 {
+			t.Fatalf("post %s: expected the points of a user to be read only got status code %d", path, w.Result().StatusCode)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	date "go-receipt-processor/Date"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
+	receipt "go-receipt-processor/Receipt"
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	time "go-receipt-processor/Time"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidJSON = errors.New("invalid receipt json")
+)
+
+// A sentinel error that can be reported to clients, and the code it is reported with
+type validationCode struct {
+	Code string
+	Err  error
+}
+
+// Sentinel errors found in submitted receipts, ordered from most to least specific so the first match is the most useful code for a problem
+var validationCodes = []validationCode{
+	{"ErrEmptyDateString", date.ErrEmptyDateString},
+	{"ErrInvalidDateSyntax", date.ErrInvalidDateSyntax},
+	{"ErrAmbiguousDate", date.ErrAmbiguousDate},
+	{"ErrParsingYear", date.ErrParsingYear},
+	{"ErrParsingMonth", date.ErrParsingMonth},
+	{"ErrParsingDay", date.ErrParsingDay},
+	{"ErrParsingDate", date.ErrParsingDate},
+	{"ErrInvalidDate", date.ErrInvalidDate},
+
+	{"ErrEmptyTimeString", time.ErrEmptyTimeString},
+	{"ErrInvalidTimeSyntax", time.ErrInvalidTimeSyntax},
+	{"ErrParsingHour", time.ErrParsingHour},
+	{"ErrParsingMinute", time.ErrParsingMinute},
+	{"ErrParsingSecond", time.ErrParsingSecond},
+	{"ErrParsingTime", time.ErrParsingTime},
+	{"ErrInvalidHour", time.ErrInvalidHour},
+	{"ErrInvalidMinute", time.ErrInvalidMinute},
+	{"ErrInvalidSecond", time.ErrInvalidSecond},
+	{"ErrInvalidTime", time.ErrInvalidTime},
+
+	{"ErrEmptyPriceString", receiptitem.ErrEmptyPriceString},
+	{"ErrParsingPrice", receiptitem.ErrParsingPrice},
+	{"ErrParsingReceiptItem", receiptitem.ErrParsingReceiptItem},
+
+	{"ErrParsingTotal", receipt.ErrParsingTotal},
+	{"ErrParsingCurrency", receipt.ErrParsingCurrency},
+	{"ErrParsingTimeZone", receipt.ErrParsingTimeZone},
+	{"ErrRateNotFound", exchangerate.ErrRateNotFound},
+	{"ErrInvalidTotal", receipt.ErrInvalidTotal},
+	{"ErrInvalidReceipt", receipt.ErrInvalidReceipt},
+	{"ErrParsingReceipt", receipt.ErrParsingReceipt},
+
+	{"ErrInvalidJSON", ErrInvalidJSON},
+}
+
+// A single problem found with a submitted receipt
+type validationProblem struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type validationErrorResponse struct {
+	Error    string              `json:"error"`
+	Problems []validationProblem `json:"problems"`
+}
+
+// Finds the code for the error, checking the codes of the feature the error came from, if any, before the codes of submitted receipts
+func getValidationCode(err error, featureCodes ...validationCode) string {
+	for _, codes := range [][]validationCode{featureCodes, validationCodes} {
+		for _, validationCode := range codes {
+			if errors.Is(err, validationCode.Err) {
+				return validationCode.Code
+			}
+		}
+	}
+	return "ErrInvalidReceipt"
+}
+
+// Walks the tree of wrapped and joined errors, producing one problem for each receipt field that has an error.
+// If none of the errors are attached to a field, the error as a whole is reported with an empty field.
+func collectValidationProblems(err error) []validationProblem {
+	problems := []validationProblem{}
+	for _, fieldErr := range receipt.FieldErrors(err) {
+		problems = append(problems, validationProblem{Field: fieldErr.Field, Code: getValidationCode(fieldErr.Err), Message: fieldErr.Err.Error()})
+	}
+	if len(problems) == 0 && err != nil {
+		problems = append(problems, validationProblem{Code: getValidationCode(err), Message: err.Error()})
+	}
+	return problems
+}
+
+// Formats a Go struct field path ( i.e. "Items.Price" ) the way it appears in the submitted json ( i.e. "items.price" )
+func toJSONFieldPath(fieldPath string) string {
+	segments := strings.Split(fieldPath, ".")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		runes := []rune(segment)
+		runes[0] = unicode.ToLower(runes[0])
+		segments[i] = string(runes)
+	}
+	return strings.Join(segments, ".")
+}
+
+// Describes why the request body could not be decoded into an unparsed receipt
+func collectDecodingProblems(err error) []validationProblem {
+	if errors.Is(err, ErrReceiptTooLarge) {
+		return []validationProblem{{Code: "ErrReceiptTooLarge", Message: err.Error()}}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []validationProblem{{
+			Field:   toJSONFieldPath(typeErr.Field),
+			Code:    "ErrInvalidJSON",
+			Message: fmt.Sprintf("%s expected a %s but was given a json %s", ErrInvalidJSON.Error(), typeErr.Type.String(), typeErr.Value),
+		}}
+	}
+	return []validationProblem{{Code: "ErrInvalidJSON", Message: fmt.Errorf("%w ... %w", ErrInvalidJSON, err).Error()}}
+}
+
+func writeValidationError(w http.ResponseWriter, problems []validationProblem) {
+	writeProblems(w, "The receipt is invalid", problems)
+}
+
+// Writes a 400 response listing every problem found with the request
+func writeProblems(w http.ResponseWriter, message string, problems []validationProblem) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(validationErrorResponse{Error: message, Problems: problems})
+}
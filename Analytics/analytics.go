@@ -0,0 +1,322 @@
+package analytics
+
+import (
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	store "go-receipt-processor/Store"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The amount spent in a single currency, and how many receipts it was spent over
+type CurrencySpend struct {
+	Receipts int64
+	Amount   money.Money
+}
+
+// Running totals over a group of receipts. Spend is kept separately for each currency, since amounts in different currencies can not be added together.
+type Totals struct {
+	Receipts int64
+	Points   int64
+	Items    int64
+	Spend    map[money.Currency]CurrencySpend
+}
+
+// Adds the receipt to the totals, or removes it when the sign is negative
+func (t *Totals) apply(storedReceipt store.StoredReceipt, sign int64) {
+	r := storedReceipt.Receipt
+	t.Receipts += sign
+	t.Points += sign * storedReceipt.Points
+	t.Items += sign * int64(len(r.Items))
+	t.addSpend(r.Currency.OrDefault(), sign, r.Total.Multiply(sign, 1, money.RoundDown))
+}
+
+func (t *Totals) addSpend(currency money.Currency, receipts int64, amount money.Money) {
+	if t.Spend == nil {
+		t.Spend = make(map[money.Currency]CurrencySpend)
+	}
+	spend := t.Spend[currency]
+	spend.Receipts += receipts
+	spend.Amount = spend.Amount.Add(amount)
+	if spend.Receipts == 0 {
+		delete(t.Spend, currency)
+		return
+	}
+	t.Spend[currency] = spend
+}
+
+func (t *Totals) merge(other Totals) {
+	t.Receipts += other.Receipts
+	t.Points += other.Points
+	t.Items += other.Items
+	for currency, spend := range other.Spend {
+		t.addSpend(currency, spend.Receipts, spend.Amount)
+	}
+}
+
+type RetailerTotals struct {
+	Retailer string
+	Totals
+}
+
+type DayTotals struct {
+	Date date.Date
+	Totals
+}
+
+type HourTotals struct {
+	Hour int
+	Totals
+}
+
+// How often an item was bought, and how much was spent on it
+type ItemTotals struct {
+	Description string
+	Quantity    int64
+	Spend       map[money.Currency]CurrencySpend // the receipts of each currency are the number of times it was bought in it
+}
+
+// The average receipt
+type BasketSize struct {
+	Receipts      int64
+	AverageItems  float64
+	AveragePoints float64
+	AverageSpend  map[money.Currency]money.Money // the average of the receipts in each currency, rounded to the currency's minor unit
+}
+
+// Everything recorded for a single day of purchases
+type dayRollup struct {
+	totals    Totals
+	retailers map[string]*Totals
+	hours     [24]Totals
+	items     map[string]*ItemTotals
+}
+
+func newDayRollup() *dayRollup {
+	return &dayRollup{retailers: make(map[string]*Totals), items: make(map[string]*ItemTotals)}
+}
+
+func (day *dayRollup) apply(storedReceipt store.StoredReceipt, hour uint8, sign int64) {
+	r := storedReceipt.Receipt
+	day.totals.apply(storedReceipt, sign)
+	day.hours[hour].apply(storedReceipt, sign)
+
+	retailer, containsKey := day.retailers[r.Retailer]
+	if !containsKey {
+		retailer = &Totals{}
+		day.retailers[r.Retailer] = retailer
+	}
+	retailer.apply(storedReceipt, sign)
+	if retailer.Receipts == 0 {
+		delete(day.retailers, r.Retailer)
+	}
+
+	currency := r.Currency.OrDefault()
+	for _, receiptItem := range r.Items {
+		description := normalizeDescription(receiptItem.ShortDescription)
+		item, containsKey := day.items[description]
+		if !containsKey {
+			item = &ItemTotals{Description: description}
+			day.items[description] = item
+		}
+		item.Quantity += sign
+		itemTotals := Totals{Spend: item.Spend}
+		itemTotals.addSpend(currency, sign, receiptItem.Price.Multiply(sign, 1, money.RoundDown))
+		item.Spend = itemTotals.Spend
+		if item.Quantity == 0 {
+			delete(day.items, description)
+		}
+	}
+}
+
+// Items are grouped by their description without the padding and case differences receipts often have, i.e. "  Gatorade " and "GATORADE"
+func normalizeDescription(description string) string {
+	return strings.ToLower(strings.Join(strings.Fields(description), " "))
+}
+
+// Aggregates of every stored receipt, grouped by the day they were purchased on, that are updated as each receipt is stored or removed rather than recalculated from the store.
+// Days are the purchase date at the store, and hours are the hour of the purchase time there, the same local date and time the points rules use.
+// Safe for concurrent use by multiple goroutines.
+type Rollups struct {
+	mutex sync.RWMutex
+	days  map[date.Date]*dayRollup
+	dates []date.Date // the days with receipts, in order
+}
+
+func NewRollups() *Rollups {
+	return &Rollups{days: make(map[date.Date]*dayRollup)}
+}
+
+// Adds the receipt to every aggregate it belongs in
+func (r *Rollups) Add(storedReceipt store.StoredReceipt) {
+	r.apply(storedReceipt, 1)
+}
+
+// Removes a receipt that was previously added from every aggregate it was in
+func (r *Rollups) Remove(storedReceipt store.StoredReceipt) {
+	r.apply(storedReceipt, -1)
+}
+
+func (r *Rollups) apply(storedReceipt store.StoredReceipt, sign int64) {
+	localDate, localTime := storedReceipt.Receipt.LocalPurchaseDateTime()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	day, containsKey := r.days[localDate]
+	if !containsKey {
+		day = newDayRollup()
+		r.days[localDate] = day
+		position := sort.Search(len(r.dates), func(i int) bool { return !r.dates[i].Before(localDate) })
+		r.dates = append(r.dates, date.Date{})
+		copy(r.dates[position+1:], r.dates[position:])
+		r.dates[position] = localDate
+	}
+	day.apply(storedReceipt, localTime.Hour%24, sign)
+	if day.totals.Receipts == 0 {
+		delete(r.days, localDate)
+		position := sort.Search(len(r.dates), func(i int) bool { return !r.dates[i].Before(localDate) })
+		r.dates = append(r.dates[:position], r.dates[position+1:]...)
+	}
+}
+
+// The days with receipts from the first day up to and including the last day, where a nil end leaves that side of the range open. The read lock must be held.
+func (r *Rollups) datesBetween(from *date.Date, to *date.Date) []date.Date {
+	start, end := 0, len(r.dates)
+	if from != nil {
+		start = sort.Search(len(r.dates), func(i int) bool { return !r.dates[i].Before(*from) })
+	}
+	if to != nil {
+		end = sort.Search(len(r.dates), func(i int) bool { return r.dates[i].After(*to) })
+	}
+	if start >= end {
+		return nil
+	}
+	return r.dates[start:end]
+}
+
+func (r *Rollups) daysBetween(from *date.Date, to *date.Date) []*dayRollup {
+	dates := r.datesBetween(from, to)
+	days := make([]*dayRollup, 0, len(dates))
+	for _, day := range dates {
+		days = append(days, r.days[day])
+	}
+	return days
+}
+
+// Totals of every receipt purchased within the range
+func (r *Rollups) Total(from *date.Date, to *date.Date) Totals {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	totals := Totals{Spend: map[money.Currency]CurrencySpend{}}
+	for _, day := range r.daysBetween(from, to) {
+		totals.merge(day.totals)
+	}
+	return totals
+}
+
+// Totals for each retailer with receipts within the range, ordered from the most points to the least
+func (r *Rollups) Retailers(from *date.Date, to *date.Date) []RetailerTotals {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	merged := map[string]*RetailerTotals{}
+	for _, day := range r.daysBetween(from, to) {
+		for retailer, totals := range day.retailers {
+			retailerTotals, containsKey := merged[retailer]
+			if !containsKey {
+				retailerTotals = &RetailerTotals{Retailer: retailer, Totals: Totals{Spend: map[money.Currency]CurrencySpend{}}}
+				merged[retailer] = retailerTotals
+			}
+			retailerTotals.merge(*totals)
+		}
+	}
+	retailers := make([]RetailerTotals, 0, len(merged))
+	for _, retailerTotals := range merged {
+		retailers = append(retailers, *retailerTotals)
+	}
+	sort.Slice(retailers, func(i, j int) bool {
+		if retailers[i].Points != retailers[j].Points {
+			return retailers[i].Points > retailers[j].Points
+		}
+		return retailers[i].Retailer < retailers[j].Retailer
+	})
+	return retailers
+}
+
+// Totals for each day with receipts within the range, in order
+func (r *Rollups) Days(from *date.Date, to *date.Date) []DayTotals {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	days := []DayTotals{}
+	for _, day := range r.datesBetween(from, to) {
+		totals := Totals{Spend: map[money.Currency]CurrencySpend{}}
+		totals.merge(r.days[day].totals)
+		days = append(days, DayTotals{Date: day, Totals: totals})
+	}
+	return days
+}
+
+// Totals for every hour of the day over the receipts within the range, including hours without any
+func (r *Rollups) Hours(from *date.Date, to *date.Date) []HourTotals {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	hours := make([]HourTotals, 24)
+	for hour := range hours {
+		hours[hour] = HourTotals{Hour: hour, Totals: Totals{Spend: map[money.Currency]CurrencySpend{}}}
+	}
+	for _, day := range r.daysBetween(from, to) {
+		for hour := range day.hours {
+			hours[hour].merge(day.hours[hour])
+		}
+	}
+	return hours
+}
+
+// The average number of items, points and spend of the receipts within the range
+func (r *Rollups) Basket(from *date.Date, to *date.Date) BasketSize {
+	totals := r.Total(from, to)
+	basket := BasketSize{Receipts: totals.Receipts, AverageSpend: map[money.Currency]money.Money{}}
+	if totals.Receipts == 0 {
+		return basket
+	}
+	basket.AverageItems = float64(totals.Items) / float64(totals.Receipts)
+	basket.AveragePoints = float64(totals.Points) / float64(totals.Receipts)
+	for currency, spend := range totals.Spend {
+		basket.AverageSpend[currency] = spend.Amount.Multiply(1, spend.Receipts, money.RoundHalfEven)
+	}
+	return basket
+}
+
+// The most bought items within the range, ordered from the most bought to the least, up to the limit
+func (r *Rollups) TopItems(from *date.Date, to *date.Date, limit int) []ItemTotals {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	merged := map[string]*ItemTotals{}
+	for _, day := range r.daysBetween(from, to) {
+		for description, item := range day.items {
+			itemTotals, containsKey := merged[description]
+			if !containsKey {
+				itemTotals = &ItemTotals{Description: description, Spend: map[money.Currency]CurrencySpend{}}
+				merged[description] = itemTotals
+			}
+			itemTotals.Quantity += item.Quantity
+			spend := Totals{Spend: itemTotals.Spend}
+			for currency, currencySpend := range item.Spend {
+				spend.addSpend(currency, currencySpend.Receipts, currencySpend.Amount)
+			}
+		}
+	}
+	items := make([]ItemTotals, 0, len(merged))
+	for _, item := range merged {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Quantity != items[j].Quantity {
+			return items[i].Quantity > items[j].Quantity
+		}
+		return items[i].Description < items[j].Description
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
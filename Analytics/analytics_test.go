@@ -0,0 +1,179 @@
+package analytics
+
+import (
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	receipt "go-receipt-processor/Receipt"
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	store "go-receipt-processor/Store"
+	utils "go-receipt-processor/TestingUtils"
+	time "go-receipt-processor/Time"
+	"testing"
+)
+
+func newStoredReceipt(retailer string, purchaseDate date.Date, purchaseTime time.Time, currency money.Currency, points int64, items ...receiptitem.ReceiptItem) store.StoredReceipt {
+	prices := []money.Money{}
+	for _, item := range items {
+		prices = append(prices, item.Price)
+	}
+	return store.StoredReceipt{
+		Receipt: receipt.Receipt{
+			Retailer:     retailer,
+			PurchaseDate: purchaseDate,
+			PurchaseTime: purchaseTime,
+			Items:        items,
+			Total:        money.Sum(prices...),
+			Currency:     currency,
+		},
+		Points: points,
+	}
+}
+
+func testReceipts() []store.StoredReceipt {
+	converted := newStoredReceipt("Target", date.Date{Year: 2022, Month: 3, Day: 20}, time.Time{Hour: 2}, money.USD, 5, receiptitem.ReceiptItem{ShortDescription: "Gatorade", Price: 1 * money.Dollar})
+	converted.Receipt.TimeZone, converted.Receipt.StoreTimeZone = time.UTC, time.Zone("-05:00") // purchased at 21:00 the day before at the store
+	return []store.StoredReceipt{
+		newStoredReceipt("Target", date.Date{Year: 2022, Month: 1, Day: 1}, time.Time{Hour: 13, Minute: 1}, money.USD, 10, receiptitem.ReceiptItem{ShortDescription: "Mountain Dew 12PK", Price: 649}),
+		newStoredReceipt("Target", date.Date{Year: 2022, Month: 1, Day: 2}, time.Time{Hour: 23, Minute: 30}, money.USD, 20, receiptitem.ReceiptItem{ShortDescription: "Emils Cheese Pizza", Price: 1225}),
+		newStoredReceipt("Walgreens", date.Date{Year: 2022, Month: 1, Day: 2}, time.Time{Hour: 8, Minute: 15}, money.JPY, 30, receiptitem.ReceiptItem{ShortDescription: " gatorade ", Price: 450}, receiptitem.ReceiptItem{ShortDescription: "GATORADE", Price: 450}),
+		newStoredReceipt("Walgreens", date.Date{Year: 2022, Month: 3, Day: 20}, time.Time{Hour: 14, Minute: 33}, money.USD, 40, receiptitem.ReceiptItem{ShortDescription: "Gatorade", Price: 9 * money.Dollar}),
+		converted,
+	}
+}
+
+func newTestRollups(storedReceipts []store.StoredReceipt) *Rollups {
+	rollups := NewRollups()
+	for _, storedReceipt := range storedReceipts {
+		rollups.Add(storedReceipt)
+	}
+	return rollups
+}
+
+// A date range, where a nil end leaves that side of the range open
+type dateRange [2]*date.Date
+
+func Test_Retailers(t *testing.T) {
+	day := date.Date{Year: 2022, Month: 1, Day: 2}
+	var testCases []utils.CreationTestingData[dateRange, []RetailerTotals] = []utils.CreationTestingData[dateRange, []RetailerTotals]{
+		{Argument: dateRange{}, ExpectedResult: []RetailerTotals{
+			{Retailer: "Walgreens", Totals: Totals{Receipts: 2, Points: 70, Items: 3, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 900}, money.JPY: {Receipts: 1, Amount: 900}}}},
+			{Retailer: "Target", Totals: Totals{Receipts: 3, Points: 35, Items: 3, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 3, Amount: 1974}}}},
+		}},
+		{Argument: dateRange{&day, &day}, ExpectedResult: []RetailerTotals{
+			{Retailer: "Walgreens", Totals: Totals{Receipts: 1, Points: 30, Items: 2, Spend: map[money.Currency]CurrencySpend{money.JPY: {Receipts: 1, Amount: 900}}}},
+			{Retailer: "Target", Totals: Totals{Receipts: 1, Points: 20, Items: 1, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 1225}}}},
+		}},
+		{Argument: dateRange{&date.Date{Year: 2023, Month: 1, Day: 1}, nil}, ExpectedResult: []RetailerTotals{}},
+	}
+	rollups := newTestRollups(testReceipts())
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("retailer totals", rollups.Retailers(testCase.Argument[0], testCase.Argument[1]), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_Days(t *testing.T) {
+	var testCases []utils.CreationTestingData[dateRange, []DayTotals] = []utils.CreationTestingData[dateRange, []DayTotals]{
+		{Argument: dateRange{}, ExpectedResult: []DayTotals{
+			{Date: date.Date{Year: 2022, Month: 1, Day: 1}, Totals: Totals{Receipts: 1, Points: 10, Items: 1, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 649}}}},
+			{Date: date.Date{Year: 2022, Month: 1, Day: 2}, Totals: Totals{Receipts: 2, Points: 50, Items: 3, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 1225}, money.JPY: {Receipts: 1, Amount: 900}}}},
+			{Date: date.Date{Year: 2022, Month: 3, Day: 19}, Totals: Totals{Receipts: 1, Points: 5, Items: 1, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 100}}}},
+			{Date: date.Date{Year: 2022, Month: 3, Day: 20}, Totals: Totals{Receipts: 1, Points: 40, Items: 1, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 900}}}},
+		}},
+		{Argument: dateRange{&date.Date{Year: 2022, Month: 1, Day: 2}, &date.Date{Year: 2022, Month: 3, Day: 19}}, ExpectedResult: []DayTotals{
+			{Date: date.Date{Year: 2022, Month: 1, Day: 2}, Totals: Totals{Receipts: 2, Points: 50, Items: 3, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 1225}, money.JPY: {Receipts: 1, Amount: 900}}}},
+			{Date: date.Date{Year: 2022, Month: 3, Day: 19}, Totals: Totals{Receipts: 1, Points: 5, Items: 1, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 100}}}},
+		}},
+		{Argument: dateRange{&date.Date{Year: 2022, Month: 2, Day: 1}, &date.Date{Year: 2022, Month: 2, Day: 28}}, ExpectedResult: []DayTotals{}},
+	}
+	rollups := newTestRollups(testReceipts())
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("day totals", rollups.Days(testCase.Argument[0], testCase.Argument[1]), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_Hours(t *testing.T) {
+	hours := newTestRollups(testReceipts()).Hours(nil, nil)
+	if len(hours) != 24 {
+		t.Fatalf("hour totals: expected 24 hours got %d", len(hours))
+	}
+	receiptsByHour := map[int]int64{}
+	for _, hour := range hours {
+		if hour.Receipts > 0 {
+			receiptsByHour[hour.Hour] = hour.Receipts
+		}
+	}
+	testCase := utils.CreationTestingData[string, map[int]int64]{Argument: "receipts by hour", ExpectedResult: map[int]int64{8: 1, 13: 1, 14: 1, 21: 1, 23: 1}}
+	errCheck := testCase.CheckTestCase("hour totals", receiptsByHour, nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+}
+
+func Test_Basket(t *testing.T) {
+	var testCases []utils.CreationTestingData[dateRange, BasketSize] = []utils.CreationTestingData[dateRange, BasketSize]{
+		{Argument: dateRange{}, ExpectedResult: BasketSize{Receipts: 5, AverageItems: 1.2, AveragePoints: 21, AverageSpend: map[money.Currency]money.Money{money.USD: 718, money.JPY: 900}}},
+		{Argument: dateRange{&date.Date{Year: 2022, Month: 3, Day: 1}, nil}, ExpectedResult: BasketSize{Receipts: 2, AverageItems: 1, AveragePoints: 22.5, AverageSpend: map[money.Currency]money.Money{money.USD: 500}}},
+		{Argument: dateRange{nil, &date.Date{Year: 2021, Month: 12, Day: 31}}, ExpectedResult: BasketSize{AverageSpend: map[money.Currency]money.Money{}}},
+	}
+	rollups := newTestRollups(testReceipts())
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("basket size", rollups.Basket(testCase.Argument[0], testCase.Argument[1]), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_TopItems(t *testing.T) {
+	var testCases []utils.CreationTestingData[int, []ItemTotals] = []utils.CreationTestingData[int, []ItemTotals]{
+		{Argument: 2, ExpectedResult: []ItemTotals{
+			{Description: "gatorade", Quantity: 4, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 2, Amount: 1000}, money.JPY: {Receipts: 2, Amount: 900}}},
+			{Description: "emils cheese pizza", Quantity: 1, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 1225}}},
+		}},
+		{Argument: 0, ExpectedResult: []ItemTotals{
+			{Description: "gatorade", Quantity: 4, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 2, Amount: 1000}, money.JPY: {Receipts: 2, Amount: 900}}},
+			{Description: "emils cheese pizza", Quantity: 1, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 1225}}},
+			{Description: "mountain dew 12pk", Quantity: 1, Spend: map[money.Currency]CurrencySpend{money.USD: {Receipts: 1, Amount: 649}}},
+		}},
+	}
+	rollups := newTestRollups(testReceipts())
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("top items", rollups.TopItems(nil, nil, testCase.Argument), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+// Removing receipts leaves the same rollups as never having added them
+func Test_Remove(t *testing.T) {
+	storedReceipts := testReceipts()
+	rollups := newTestRollups(storedReceipts)
+	rollups.Remove(storedReceipts[2])
+	rollups.Remove(storedReceipts[4])
+	expected := newTestRollups([]store.StoredReceipt{storedReceipts[0], storedReceipts[1], storedReceipts[3]})
+
+	testCase := utils.CreationTestingData[string, []DayTotals]{Argument: "remove receipts", ExpectedResult: expected.Days(nil, nil)}
+	errCheck := testCase.CheckTestCase("remove receipts", rollups.Days(nil, nil), nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+	itemsTestCase := utils.CreationTestingData[string, []ItemTotals]{Argument: "remove receipts", ExpectedResult: expected.TopItems(nil, nil, 0)}
+	errCheck = itemsTestCase.CheckTestCase("remove receipts", rollups.TopItems(nil, nil, 0), nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+
+	for _, storedReceipt := range []store.StoredReceipt{storedReceipts[0], storedReceipts[1], storedReceipts[3]} {
+		rollups.Remove(storedReceipt)
+	}
+	if len(rollups.days) != 0 || len(rollups.dates) != 0 {
+		t.Fatalf("remove receipts: expected no days after removing every receipt got %d", len(rollups.dates))
+	}
+}
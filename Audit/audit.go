@@ -0,0 +1,249 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	store "go-receipt-processor/Store"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// What was done to a receipt
+type Action string
+
+const (
+	ActionProcess  Action = "process"  // a new receipt was stored
+	ActionResubmit Action = "resubmit" // a receipt that was already stored was submitted again, leaving it unchanged
+	ActionAmend    Action = "amend"    // the receipt was replaced with a corrected version
+	ActionDelete   Action = "delete"   // the receipt was removed
+)
+
+// The previous hash of the first event in a log
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+var (
+	ErrBrokenChain     = errors.New("audit log chain is broken")
+	ErrHashMismatch    = errors.New("audit event does not match its hash")
+	ErrMalformedEvent  = errors.New("malformed audit event")
+	ErrEmptyReceiptId  = errors.New("audit event has an empty receipt id")
+	ErrUnknownAction   = errors.New("unknown audit action")
+	ErrAppendingEvent  = errors.New("appending audit event")
+	ErrOpeningAuditLog = errors.New("opening audit log")
+	ErrAuditLogClosed  = errors.New("audit log is closed")
+)
+
+// Who made a request, and the id it was made under, so every event caused by the same request can be found together
+type Origin struct {
+	Actor     string
+	RequestId string
+}
+
+// A change to a receipt that is about to be recorded. The log fills in the rest of the event.
+type Entry struct {
+	Origin
+	Action     Action
+	ReceiptId  string
+	BeforeHash string // the hash of the receipt before the change, empty if it did not exist
+	AfterHash  string // the hash of the receipt after the change, empty if it no longer exists
+}
+
+// A single recorded change to a receipt.
+// Each event includes the hash of the event before it, so changing, removing or reordering any event breaks the chain from that point on.
+type Event struct {
+	Sequence     int64     `json:"sequence"`
+	Action       Action    `json:"action"`
+	ReceiptId    string    `json:"receiptId"`
+	Actor        string    `json:"actor"`
+	RequestId    string    `json:"requestId"`
+	Timestamp    time.Time `json:"timestamp"`
+	BeforeHash   string    `json:"beforeHash,omitempty"`
+	AfterHash    string    `json:"afterHash,omitempty"`
+	PreviousHash string    `json:"previousHash"`
+	Hash         string    `json:"hash"`
+}
+
+// The fields of an event that are hashed, in a fixed order and with the timestamp in a single canonical form
+type chainedEvent struct {
+	Sequence     int64  `json:"sequence"`
+	Action       Action `json:"action"`
+	ReceiptId    string `json:"receiptId"`
+	Actor        string `json:"actor"`
+	RequestId    string `json:"requestId"`
+	Timestamp    string `json:"timestamp"`
+	BeforeHash   string `json:"beforeHash"`
+	AfterHash    string `json:"afterHash"`
+	PreviousHash string `json:"previousHash"`
+}
+
+// Returns the hex encoded SHA-256 hash of every field of the event except its own hash
+func (e Event) ComputeHash() string {
+	encoded, _ := json.Marshal(chainedEvent{ // only strings and integers, which always encode
+		Sequence:     e.Sequence,
+		Action:       e.Action,
+		ReceiptId:    e.ReceiptId,
+		Actor:        e.Actor,
+		RequestId:    e.RequestId,
+		Timestamp:    e.Timestamp.UTC().Format(time.RFC3339Nano),
+		BeforeHash:   e.BeforeHash,
+		AfterHash:    e.AfterHash,
+		PreviousHash: e.PreviousHash,
+	})
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:])
+}
+
+// The fields of a stored receipt that are hashed into its audit events
+type hashedReceipt struct {
+	Id             string `json:"id"`
+	ContentHash    string `json:"contentHash"`
+	Points         int64  `json:"points"`
+	RulesetVersion int    `json:"rulesetVersion"`
+	Version        int    `json:"version"`
+}
+
+// Returns a hex encoded SHA-256 hash of the stored receipt's id, content, points and version, so an audit event records exactly which state of the receipt it saw
+func ReceiptHash(storedReceipt store.StoredReceipt) string {
+	encoded, _ := json.Marshal(hashedReceipt{ // only strings and integers, which always encode
+		Id:             storedReceipt.Receipt.Id,
+		ContentHash:    storedReceipt.Receipt.ContentHash(),
+		Points:         storedReceipt.Points,
+		RulesetVersion: storedReceipt.RulesetVersion,
+		Version:        storedReceipt.EffectiveVersion(),
+	})
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:])
+}
+
+// Checks that the events form an unbroken chain, from the first event ever recorded to the last.
+// Returns ErrBrokenChain if an event is missing, out of order or does not follow on from the event before it, and ErrHashMismatch if an event was changed after it was recorded.
+func Verify(events []Event) error {
+	previousHash := genesisHash
+	for i, event := range events {
+		if expected := int64(i + 1); event.Sequence != expected {
+			return fmt.Errorf("%w given event %d ... expected sequence %d", ErrBrokenChain, event.Sequence, expected)
+		}
+		if event.PreviousHash != previousHash {
+			return fmt.Errorf("%w given event %d ... expected previous hash \"%s\" got \"%s\"", ErrBrokenChain, event.Sequence, previousHash, event.PreviousHash)
+		}
+		if hash := event.ComputeHash(); event.Hash != hash {
+			return fmt.Errorf("%w given event %d ... expected hash \"%s\" got \"%s\"", ErrHashMismatch, event.Sequence, hash, event.Hash)
+		}
+		previousHash = event.Hash
+	}
+	return nil
+}
+
+// Reads newline delimited json events, as written by a FileLog, skipping blank lines
+func ReadEvents(reader io.Reader) ([]Event, error) {
+	events := []Event{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return events, fmt.Errorf("%w on line %d ... %w", ErrMalformedEvent, line, err)
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+func validateEntry(entry Entry) error {
+	if entry.ReceiptId == "" {
+		return ErrEmptyReceiptId
+	}
+	switch entry.Action {
+	case ActionProcess, ActionResubmit, ActionAmend, ActionDelete:
+		return nil
+	}
+	return fmt.Errorf("%w given \"%s\"", ErrUnknownAction, entry.Action)
+}
+
+// An append-only record of every change made to the stored receipts
+type Log interface {
+	// Records the entry as the next event in the chain and returns it
+	Append(entry Entry) (Event, error)
+	// Returns every event, oldest first
+	Events() ([]Event, error)
+	// Returns the events for a single receipt, oldest first, which is empty if the receipt was never changed
+	History(receiptId string) ([]Event, error)
+}
+
+// Log that only keeps its events in memory, so they are lost when the server stops
+type MemoryLog struct {
+	mutex     sync.RWMutex
+	events    []Event
+	byReceipt map[string][]int // the positions in events of each receipt's events
+}
+
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{events: []Event{}, byReceipt: map[string][]int{}}
+}
+
+func (l *MemoryLog) Append(entry Entry) (Event, error) {
+	if err := validateEntry(entry); err != nil {
+		return Event{}, err
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	event := l.next(entry, time.Now().UTC())
+	l.add(event)
+	return event, nil
+}
+
+// Builds the event that would follow the last one in the log. The caller must hold the mutex.
+func (l *MemoryLog) next(entry Entry, timestamp time.Time) Event {
+	previousHash := genesisHash
+	if len(l.events) > 0 {
+		previousHash = l.events[len(l.events)-1].Hash
+	}
+	event := Event{
+		Sequence:     int64(len(l.events) + 1),
+		Action:       entry.Action,
+		ReceiptId:    entry.ReceiptId,
+		Actor:        entry.Actor,
+		RequestId:    entry.RequestId,
+		Timestamp:    timestamp,
+		BeforeHash:   entry.BeforeHash,
+		AfterHash:    entry.AfterHash,
+		PreviousHash: previousHash,
+	}
+	event.Hash = event.ComputeHash()
+	return event
+}
+
+// Adds an event that already follows the last one in the log. The caller must hold the mutex.
+func (l *MemoryLog) add(event Event) {
+	l.byReceipt[event.ReceiptId] = append(l.byReceipt[event.ReceiptId], len(l.events))
+	l.events = append(l.events, event)
+}
+
+func (l *MemoryLog) Events() ([]Event, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	events := make([]Event, len(l.events))
+	copy(events, l.events)
+	return events, nil
+}
+
+func (l *MemoryLog) History(receiptId string) ([]Event, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	positions := l.byReceipt[receiptId]
+	events := make([]Event, 0, len(positions))
+	for _, position := range positions {
+		events = append(events, l.events[position])
+	}
+	return events, nil
+}
@@ -0,0 +1,160 @@
+package audit
+
+import (
+	receipt "go-receipt-processor/Receipt"
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	store "go-receipt-processor/Store"
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+	"time"
+)
+
+var testEntries = []Entry{
+	{Origin: Origin{Actor: "alice", RequestId: "request-1"}, Action: ActionProcess, ReceiptId: "a", AfterHash: "hash-a1"},
+	{Origin: Origin{Actor: "bob", RequestId: "request-2"}, Action: ActionProcess, ReceiptId: "b", AfterHash: "hash-b1"},
+	{Origin: Origin{Actor: "alice", RequestId: "request-3"}, Action: ActionAmend, ReceiptId: "a", BeforeHash: "hash-a1", AfterHash: "hash-a2"},
+	{Origin: Origin{Actor: "carol", RequestId: "request-4"}, Action: ActionDelete, ReceiptId: "a", BeforeHash: "hash-a2"},
+}
+
+func appendEntries(t *testing.T, log Log, entries []Entry) []Event {
+	events := []Event{}
+	for _, entry := range entries {
+		event, err := log.Append(entry)
+		if err != nil {
+			t.Fatalf("append ( %+v ): unexpected error ( %v )", entry, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// Runs the same checks against every implementation of Log
+func testLog(t *testing.T, log Log) {
+	events := appendEntries(t, log, testEntries)
+	for i, event := range events {
+		if event.Sequence != int64(i+1) || event.Actor != testEntries[i].Actor || event.Hash != event.ComputeHash() || event.Timestamp.IsZero() {
+			t.Fatalf("append ( %+v ): unexpected event ( %+v )", testEntries[i], event)
+		}
+	}
+	if events[0].PreviousHash != genesisHash || events[1].PreviousHash != events[0].Hash {
+		t.Fatalf("append: expected each event to follow on from the one before it got ( %+v )", events)
+	}
+
+	allEvents, err := log.Events()
+	if err != nil || Verify(allEvents) != nil || len(allEvents) != len(testEntries) {
+		t.Fatalf("events: expected a valid chain of %d events got ( %+v ) error ( %v )", len(testEntries), allEvents, err)
+	}
+
+	var testCases []utils.CreationTestingData[string, []int64] = []utils.CreationTestingData[string, []int64]{
+		{Argument: "a", ExpectedResult: []int64{1, 3, 4}},
+		{Argument: "b", ExpectedResult: []int64{2}},
+		{Argument: "c", ExpectedResult: []int64{}},
+	}
+	for _, testCase := range testCases {
+		history, err := log.History(testCase.Argument)
+		sequences := []int64{}
+		for _, event := range history {
+			sequences = append(sequences, event.Sequence)
+		}
+		errCheck := testCase.CheckTestCase("history", sequences, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	var entryTestCases []utils.CreationTestingData[Entry, error] = []utils.CreationTestingData[Entry, error]{
+		{Argument: Entry{Action: ActionProcess}, ExpectedErr: ErrEmptyReceiptId},
+		{Argument: Entry{Action: "rename", ReceiptId: "a"}, ExpectedErr: ErrUnknownAction},
+	}
+	for _, testCase := range entryTestCases {
+		_, err := log.Append(testCase.Argument)
+		errCheck := testCase.CheckTestCase("append", nil, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_MemoryLog(t *testing.T) {
+	testLog(t, NewMemoryLog())
+}
+
+func Test_Verify(t *testing.T) {
+	log := NewMemoryLog()
+	events := appendEntries(t, log, testEntries)
+	tamper := func(change func([]Event) []Event) []Event {
+		tampered := make([]Event, len(events))
+		copy(tampered, events)
+		return change(tampered)
+	}
+
+	var testCases []utils.CreationTestingData[[]Event, error] = []utils.CreationTestingData[[]Event, error]{
+		{Argument: events},
+		{Argument: []Event{}},
+		{ //Tests an event that was edited without recomputing its hash
+			Argument:    tamper(func(e []Event) []Event { e[1].Actor = "mallory"; return e }),
+			ExpectedErr: ErrHashMismatch,
+		},
+		{ //Tests an event that was edited along with its own hash, which no longer matches the next event
+			Argument: tamper(func(e []Event) []Event {
+				e[1].AfterHash = "hash-b2"
+				e[1].Hash = e[1].ComputeHash()
+				return e
+			}),
+			ExpectedErr: ErrBrokenChain,
+		},
+		{Argument: tamper(func(e []Event) []Event { return append(e[:1], e[2:]...) }), ExpectedErr: ErrBrokenChain},
+		{Argument: tamper(func(e []Event) []Event { e[2], e[3] = e[3], e[2]; return e }), ExpectedErr: ErrBrokenChain},
+		{Argument: tamper(func(e []Event) []Event { return e[1:] }), ExpectedErr: ErrBrokenChain},
+		{ //Tests truncating the end of the log, which can only be noticed by comparing with the last hash that was seen
+			Argument: tamper(func(e []Event) []Event { return e[:3] }),
+		},
+	}
+	for _, testCase := range testCases {
+		err := Verify(testCase.Argument)
+		errCheck := testCase.CheckTestCase("verify", nil, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_ReceiptHash(t *testing.T) {
+	parsedReceipt, err := receipt.ParseReceipt("a", receipt.UnparsedReceipt{
+		Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Total: "6.49",
+		Items: []receiptitem.UnparsedReceiptItem{{ShortDescription: "Mountain Dew 12PK", Price: "6.49"}},
+	}, true)
+	if err != nil {
+		t.Fatalf("parse receipt: unexpected error ( %v )", err)
+	}
+	original := store.StoredReceipt{Receipt: parsedReceipt, Points: 12, RulesetVersion: 1}
+	versioned := original
+	versioned.Version = 1
+	rescored := original
+	rescored.Points = 13
+	amended := original
+	amended.Version = 2
+	renamed := original
+	renamed.Receipt.Retailer = "Walgreens"
+
+	if ReceiptHash(original) != ReceiptHash(versioned) {
+		t.Fatalf("receipt hash: expected a receipt stored before versions were kept to hash the same as version 1")
+	}
+	for _, changed := range []store.StoredReceipt{rescored, amended, renamed} {
+		if ReceiptHash(changed) == ReceiptHash(original) {
+			t.Fatalf("receipt hash: expected ( %+v ) to hash differently from the original", changed)
+		}
+	}
+	if len(ReceiptHash(original)) != 64 {
+		t.Fatalf("receipt hash: expected a hex encoded SHA-256 hash got ( %s )", ReceiptHash(original))
+	}
+}
+
+func Test_ComputeHashIgnoresTimeZone(t *testing.T) {
+	event := Event{Sequence: 1, Action: ActionProcess, ReceiptId: "a", Timestamp: time.Date(2023, 10, 15, 15, 30, 0, 0, time.UTC), PreviousHash: genesisHash}
+	local := event
+	local.Timestamp = event.Timestamp.In(time.FixedZone("EST", -5*60*60))
+	if event.ComputeHash() != local.ComputeHash() {
+		t.Fatalf("compute hash: expected the same instant to hash the same in any time zone")
+	}
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Log that appends each event to a newline delimited json file before adding it to the events kept in memory.
+// The file is only ever appended to, and the whole chain is verified when it is opened, so a log that was tampered with is refused instead of being extended.
+// A file that ends in a partially written event ( i.e. the process crashed mid-write ) is truncated back to the last complete event.
+type FileLog struct {
+	path   string
+	memory *MemoryLog
+	file   *os.File
+	closed bool
+}
+
+func OpenFileLog(path string) (*FileLog, error) {
+	l := &FileLog{path: path, memory: NewMemoryLog()}
+	validLength, err := l.load()
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningAuditLog, path, err)
+	}
+	if err := Verify(l.memory.events); err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningAuditLog, path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningAuditLog, path, err)
+	}
+	// drops any partially written event left behind by a crash, so new events are appended after the last complete one
+	if err := file.Truncate(validLength); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningAuditLog, path, err)
+	}
+	if _, err := file.Seek(validLength, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningAuditLog, path, err)
+	}
+	l.file = file
+	return l, nil
+}
+
+// Adds every complete event in the file to the events kept in memory and returns the length of the file up to the end of the last complete event.
+// A missing file is treated as empty.
+func (l *FileLog) load() (int64, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var validLength int64 = 0
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return validLength, nil // anything left without a newline was never completely written
+		} else if err != nil {
+			return validLength, err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			var event Event
+			if err := json.Unmarshal(data, &event); err != nil {
+				return validLength, fmt.Errorf("%w on line %d ... %w", ErrMalformedEvent, line, err)
+			}
+			l.memory.add(event)
+		}
+		validLength += int64(len(data))
+	}
+}
+
+// Durably appends the entry to the file as the next event in the chain, then adds it to the events kept in memory
+func (l *FileLog) Append(entry Entry) (Event, error) {
+	if err := validateEntry(entry); err != nil {
+		return Event{}, err
+	}
+	l.memory.mutex.Lock()
+	defer l.memory.mutex.Unlock()
+	if l.closed {
+		return Event{}, ErrAuditLogClosed
+	}
+	event := l.memory.next(entry, time.Now().UTC())
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return Event{}, fmt.Errorf("%w ... %w", ErrAppendingEvent, err)
+	}
+	if _, err := l.file.Write(append(encoded, '\n')); err != nil {
+		return Event{}, fmt.Errorf("%w ... %w", ErrAppendingEvent, err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return Event{}, fmt.Errorf("%w ... %w", ErrAppendingEvent, err)
+	}
+	l.memory.add(event)
+	return event, nil
+}
+
+func (l *FileLog) Events() ([]Event, error) {
+	return l.memory.Events()
+}
+
+func (l *FileLog) History(receiptId string) ([]Event, error) {
+	return l.memory.History(receiptId)
+}
+
+func (l *FileLog) Close() error {
+	l.memory.mutex.Lock()
+	defer l.memory.mutex.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.file.Close()
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func openTestFileLog(t *testing.T, path string) *FileLog {
+	fileLog, err := OpenFileLog(path)
+	if err != nil {
+		t.Fatalf("open file log ( %s ): unexpected error ( %v )", path, err)
+	}
+	return fileLog
+}
+
+func Test_FileLog(t *testing.T) {
+	fileLog := openTestFileLog(t, filepath.Join(t.TempDir(), "audit.log"))
+	defer fileLog.Close()
+	testLog(t, fileLog)
+}
+
+func Test_FileLogReloadsEventsOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	fileLog := openTestFileLog(t, path)
+	written := appendEntries(t, fileLog, testEntries[:2])
+	fileLog.Close()
+	if _, err := fileLog.Append(testEntries[2]); !errors.Is(err, ErrAuditLogClosed) {
+		t.Fatalf("append: expected ( %v ) once closed got ( %v )", ErrAuditLogClosed, err)
+	}
+
+	reopened := openTestFileLog(t, path)
+	defer reopened.Close()
+	written = append(written, appendEntries(t, reopened, testEntries[2:])...)
+	events, _ := reopened.Events()
+	if !cmp.Equal(events, written) || Verify(events) != nil {
+		t.Fatalf("reload events: expected the chain to continue from the reloaded events got ( %+v )", events)
+	}
+
+	// the file holds exactly what a verifier reading it back would see
+	file, _ := os.Open(path)
+	defer file.Close()
+	read, err := ReadEvents(file)
+	if err != nil || !cmp.Equal(read, written) {
+		t.Fatalf("read events: expected the events that were appended got ( %+v ) error ( %v )", read, err)
+	}
+}
+
+func Test_FileLogDropsPartiallyWrittenEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	fileLog := openTestFileLog(t, path)
+	written := appendEntries(t, fileLog, testEntries[:2])
+	fileLog.Close()
+
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, append(data, []byte(`{"sequence": 3, "action": "am`)...), 0o644)
+
+	reopened := openTestFileLog(t, path)
+	written = append(written, appendEntries(t, reopened, testEntries[2:3])...)
+	reopened.Close()
+	file, _ := os.Open(path)
+	defer file.Close()
+	read, err := ReadEvents(file)
+	if err != nil || !cmp.Equal(read, written) {
+		t.Fatalf("partially written event: expected it to be replaced by the next event got ( %+v ) error ( %v )", read, err)
+	}
+}
+
+func Test_FileLogRefusesTamperedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	fileLog := openTestFileLog(t, path)
+	appendEntries(t, fileLog, testEntries)
+	fileLog.Close()
+	data, _ := os.ReadFile(path)
+
+	var testCases = []struct {
+		name        string
+		data        []byte
+		expectedErr error
+	}{
+		{name: "edited", data: bytes.Replace(data, []byte(`"actor":"bob"`), []byte(`"actor":"eve"`), 1), expectedErr: ErrHashMismatch},
+		{name: "removed", data: data[bytes.IndexByte(data, '\n')+1:], expectedErr: ErrBrokenChain},
+		{name: "malformed", data: append([]byte("not json\n"), data...), expectedErr: ErrMalformedEvent},
+	}
+	for _, testCase := range testCases {
+		os.WriteFile(path, testCase.data, 0o644)
+		_, err := OpenFileLog(path)
+		if !errors.Is(err, ErrOpeningAuditLog) || !errors.Is(err, testCase.expectedErr) {
+			t.Fatalf("open %s file log: expected ( %v ) got ( %v )", testCase.name, testCase.expectedErr, err)
+		}
+	}
+}
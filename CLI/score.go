@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	api "go-receipt-processor/API"
+	points "go-receipt-processor/Points"
+	receipt "go-receipt-processor/Receipt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Exit codes of the score and verify-audit commands
+const (
+	ExitValid   = 0 // every receipt was scored, or the audit log is intact
+	ExitInvalid = 1 // at least one receipt could not be read or was invalid, or the audit log was tampered with
+	ExitUsage   = 2 // the arguments were wrong or an input could not be opened
+)
+
+// The name inputs read from standard input are reported under
+const stdinName = "stdin"
+
+var (
+	ErrUnknownOutputFormat = errors.New("unknown output format")
+)
+
+// How the results are printed
+type OutputFormat string
+
+const (
+	TableFormat OutputFormat = "table"
+	JSONFormat  OutputFormat = "json"
+	CSVFormat   OutputFormat = "csv"
+)
+
+func ParseOutputFormat(str string) (OutputFormat, error) {
+	switch format := OutputFormat(str); format {
+	case TableFormat, JSONFormat, CSVFormat:
+		return format, nil
+	}
+	return "", fmt.Errorf("%w given %q ... expected one of %s, %s or %s", ErrUnknownOutputFormat, str, TableFormat, JSONFormat, CSVFormat)
+}
+
+// A single receipt read from an input, or the reason it could not be read
+type scoreInput struct {
+	id              string
+	unparsedReceipt receipt.UnparsedReceipt
+	decodeErr       error
+}
+
+type scoreProblem struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// The outcome of scoring a single receipt, with either its points or why it could not be scored
+type scoreResult struct {
+	Id        string                 `json:"id"`
+	Valid     bool                   `json:"valid"`
+	Points    int64                  `json:"points"`
+	Breakdown *api.BreakdownResponse `json:"breakdown,omitempty"`
+	Problems  []scoreProblem         `json:"problems,omitempty"`
+}
+
+// Scores the receipts in the files given as arguments, or standard input if there are none, printing the results to stdout.
+// Returns the exit code for the process: ExitValid if every receipt was scored, ExitInvalid if any were not, and ExitUsage if the arguments were wrong or an input could not be read.
+func RunScore(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("score", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: go-receipt-processor score [flags] [file ...]")
+		fmt.Fprintln(stderr, "Scores json, json array or newline delimited json receipts from each file, or standard input if no files or - are given.")
+		flags.PrintDefaults()
+	}
+	formatFlag := flags.String("format", string(TableFormat), "how results are printed, one of table, json or csv")
+	rulesetPath := flags.String("ruleset", "", "json or yaml ruleset file, or a directory with one file per ruleset version, whose current version receipts are scored with. If empty the built-in rules are used")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	format, err := ParseOutputFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	ruleset := points.DefaultRuleset()
+	if *rulesetPath != "" {
+		rulesets, err := points.LoadRulesetHistory(*rulesetPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		ruleset = rulesets.Current()
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	inputs := []scoreInput{}
+	for _, path := range paths {
+		pathInputs, err := readInputs(path, stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		inputs = append(inputs, pathInputs...)
+	}
+
+	results := make([]scoreResult, 0, len(inputs))
+	exitCode := ExitValid
+	for _, input := range inputs {
+		result := scoreReceipt(input, ruleset)
+		if !result.Valid {
+			exitCode = ExitInvalid
+		}
+		results = append(results, result)
+	}
+	if err := writeResults(stdout, format, results); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	return exitCode
+}
+
+// Reads every receipt in the file at the path, or standard input if the path is -
+func readInputs(path string, stdin io.Reader) ([]scoreInput, error) {
+	name := path
+	var data []byte
+	var err error
+	if path == "-" {
+		name = stdinName
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeInputs(name, data), nil
+}
+
+// Splits the data into receipts, which can be a single json receipt, a json array of receipts, or newline delimited json with one receipt per line.
+// Each receipt is given an id of the input's name and its position: the line it was on for newline delimited json, or its 1-based index otherwise.
+func decodeInputs(name string, data []byte) []scoreInput {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	if trimmed[0] == '[' {
+		return decodeArrayInputs(name, trimmed)
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	if firstLine := firstNonEmptyLine(lines); !json.Valid(firstLine) {
+		// a single receipt spread over several lines
+		return []scoreInput{decodeInput(fmt.Sprintf("%s#1", name), trimmed)}
+	}
+	inputs := []scoreInput{}
+	for lineIndex, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		inputs = append(inputs, decodeInput(fmt.Sprintf("%s:%d", name, lineIndex+1), line))
+	}
+	return inputs
+}
+
+func firstNonEmptyLine(lines [][]byte) []byte {
+	for _, line := range lines {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line
+		}
+	}
+	return nil
+}
+
+func decodeArrayInputs(name string, data []byte) []scoreInput {
+	var rawReceipts []json.RawMessage
+	if err := json.Unmarshal(data, &rawReceipts); err != nil {
+		return []scoreInput{{id: fmt.Sprintf("%s#1", name), decodeErr: err}}
+	}
+	inputs := make([]scoreInput, 0, len(rawReceipts))
+	for index, rawReceipt := range rawReceipts {
+		inputs = append(inputs, decodeInput(fmt.Sprintf("%s#%d", name, index+1), rawReceipt))
+	}
+	return inputs
+}
+
+func decodeInput(id string, data []byte) scoreInput {
+	input := scoreInput{id: id}
+	input.decodeErr = json.Unmarshal(data, &input.unparsedReceipt)
+	return input
+}
+
+func scoreReceipt(input scoreInput, ruleset *points.Ruleset) scoreResult {
+	result := scoreResult{Id: input.id}
+	if input.decodeErr != nil {
+		result.Problems = []scoreProblem{{Message: input.decodeErr.Error()}}
+		return result
+	}
+	parsedReceipt, err := receipt.ParseReceipt(input.id, input.unparsedReceipt, true)
+	if err != nil {
+		result.Problems = collectProblems(err)
+		return result
+	}
+	breakdown := api.NewBreakdownResponse(ruleset.Calculate(parsedReceipt), parsedReceipt.Currency) // item prices are shown in the receipt's currency rather than as dollars
+	result.Valid = true
+	result.Points = breakdown.Points
+	result.Breakdown = &breakdown
+	return result
+}
+
+func collectProblems(err error) []scoreProblem {
+	problems := []scoreProblem{}
+	for _, fieldErr := range receipt.FieldErrors(err) {
+		problems = append(problems, scoreProblem{Field: fieldErr.Field, Message: fieldErr.Err.Error()})
+	}
+	if len(problems) == 0 {
+		problems = append(problems, scoreProblem{Message: err.Error()})
+	}
+	return problems
+}
+
+func writeResults(w io.Writer, format OutputFormat, results []scoreResult) error {
+	switch format {
+	case JSONFormat:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case CSVFormat:
+		writer := csv.NewWriter(w)
+		writer.WriteAll(flattenResults(results))
+		return writer.Error()
+	default:
+		writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, row := range flattenResults(results) {
+			for column, cell := range row {
+				if column > 0 {
+					fmt.Fprint(writer, "\t")
+				}
+				fmt.Fprint(writer, cell)
+			}
+			fmt.Fprintln(writer)
+		}
+		return writer.Flush()
+	}
+}
+
+// The columns the table and csv output share
+var resultColumns = []string{"id", "kind", "name", "points", "message"}
+
+// Flattens the results into rows under resultColumns: a "total" row with the points of each valid receipt followed by a "rule" row for each rule that awarded it points,
+// or an "error" row for each problem with an invalid receipt naming the field it was found in
+func flattenResults(results []scoreResult) [][]string {
+	rows := [][]string{resultColumns}
+	for _, result := range results {
+		if !result.Valid {
+			for _, problem := range result.Problems {
+				rows = append(rows, []string{result.Id, "error", problem.Field, "", problem.Message})
+			}
+			continue
+		}
+		rows = append(rows, []string{result.Id, "total", "", strconv.FormatInt(result.Points, 10), ""})
+		for _, awarded := range result.Breakdown.Rules {
+			rows = append(rows, []string{result.Id, "rule", awarded.Rule, strconv.FormatInt(awarded.Points, 10), awarded.Description})
+		}
+	}
+	return rows
+}
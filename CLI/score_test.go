@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	utils "go-receipt-processor/TestingUtils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	validScoreReceipt   = `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "6.49", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}]}`
+	invalidScoreReceipt = `{"retailer": "Target", "purchaseDate": "2022-01-01", "purchaseTime": "13:01", "total": "6.49", "items": [{"shortDescription": "Mountain Dew 12PK", "price": "6.4a9"}]}`
+)
+
+// The parts of a score result that are checked, leaving out the wording of rules and errors
+type scoreOutcome struct {
+	Id     string
+	Valid  bool
+	Points int64
+	Fields []string
+}
+
+func summarizeScores(results []scoreResult) []scoreOutcome {
+	outcomes := []scoreOutcome{}
+	for _, result := range results {
+		outcome := scoreOutcome{Id: result.Id, Valid: result.Valid, Points: result.Points}
+		for _, problem := range result.Problems {
+			outcome.Fields = append(outcome.Fields, problem.Field)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+func TestRunScoreFromStdin(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, []scoreOutcome] = []utils.CreationTestingData[string, []scoreOutcome]{
+		{
+			Argument:       validScoreReceipt,
+			ExpectedResult: []scoreOutcome{{Id: "stdin:1", Valid: true, Points: 12}},
+		},
+		{ //Tests a single receipt spread over several lines
+			Argument:       strings.ReplaceAll(validScoreReceipt, ", ", ",\n  "),
+			ExpectedResult: []scoreOutcome{{Id: "stdin#1", Valid: true, Points: 12}},
+		},
+		{
+			Argument: "[" + validScoreReceipt + ", " + invalidScoreReceipt + `, {"retailer": 5}]`,
+			ExpectedResult: []scoreOutcome{
+				{Id: "stdin#1", Valid: true, Points: 12},
+				{Id: "stdin#2", Fields: []string{"items[0].price"}},
+				{Id: "stdin#3", Fields: []string{""}},
+			},
+		},
+		{ //Tests newline delimited json, where blank lines are skipped and a malformed line only rejects that receipt
+			Argument: validScoreReceipt + "\n\n" + `{"retailer": ` + "\n" + invalidScoreReceipt + "\r\n" + validScoreReceipt,
+			ExpectedResult: []scoreOutcome{
+				{Id: "stdin:1", Valid: true, Points: 12},
+				{Id: "stdin:3", Fields: []string{""}},
+				{Id: "stdin:4", Fields: []string{"items[0].price"}},
+				{Id: "stdin:5", Valid: true, Points: 12},
+			},
+		},
+		{Argument: "", ExpectedResult: []scoreOutcome{}},
+	}
+	for _, testCase := range testCases {
+		var stdout, stderr bytes.Buffer
+		exitCode := RunScore([]string{"-format", "json"}, strings.NewReader(testCase.Argument), &stdout, &stderr)
+		var results []scoreResult
+		if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+			t.Fatalf("score ( %s ): could not read output %q: %s", testCase.Argument, stdout.String(), err)
+		}
+		outcomes := summarizeScores(results)
+		errCheck := testCase.CheckTestCase("score", outcomes, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+		expectedExitCode := ExitValid
+		for _, outcome := range outcomes {
+			if !outcome.Valid {
+				expectedExitCode = ExitInvalid
+			}
+		}
+		if exitCode != expectedExitCode {
+			t.Fatalf("score ( %s ): expected exit code %d got %d", testCase.Argument, expectedExitCode, exitCode)
+		}
+	}
+}
+
+func TestRunScoreOutputFormats(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, string] = []utils.CreationTestingData[string, string]{
+		{
+			Argument: "csv",
+			ExpectedResult: "id,kind,name,points,message\n" +
+				"stdin:1,total,,12,\n" +
+				"stdin:1,rule,alphanumericRetailer,6,\"1 point for every alphanumeric character in the retailer name \"\"Target\"\"\"\n" +
+				"stdin:1,rule,oddPurchaseDay,6,\"6 points because the day of the purchase date, 2022-01-01, is odd\"\n" +
+				"stdin:2,error,,,unexpected end of JSON input\n",
+		},
+		{
+			Argument: "table",
+			ExpectedResult: "id       kind   name                  points  message\n" +
+				"stdin:1  total                        12      \n" +
+				"stdin:1  rule   alphanumericRetailer  6       1 point for every alphanumeric character in the retailer name \"Target\"\n" +
+				"stdin:1  rule   oddPurchaseDay        6       6 points because the day of the purchase date, 2022-01-01, is odd\n" +
+				"stdin:2  error                                unexpected end of JSON input\n",
+		},
+	}
+	for _, testCase := range testCases {
+		var stdout, stderr bytes.Buffer
+		RunScore([]string{"-format", testCase.Argument}, strings.NewReader(validScoreReceipt+"\n{"), &stdout, &stderr)
+		errCheck := testCase.CheckTestCase("score output", stdout.String(), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func TestRunScoreFromFiles(t *testing.T) {
+	directory := t.TempDir()
+	receiptsPath := filepath.Join(directory, "receipts.jsonl")
+	if err := os.WriteFile(receiptsPath, []byte(validScoreReceipt+"\n"+invalidScoreReceipt+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	exitCode := RunScore([]string{"-format", "json", receiptsPath, "-"}, strings.NewReader(validScoreReceipt), &stdout, &stderr)
+	var results []scoreResult
+	json.Unmarshal(stdout.Bytes(), &results)
+	testCase := utils.CreationTestingData[[]string, []scoreOutcome]{
+		Argument: []string{receiptsPath, "-"},
+		ExpectedResult: []scoreOutcome{
+			{Id: receiptsPath + ":1", Valid: true, Points: 12},
+			{Id: receiptsPath + ":2", Fields: []string{"items[0].price"}},
+			{Id: "stdin:1", Valid: true, Points: 12},
+		},
+	}
+	errCheck := testCase.CheckTestCase("score files", summarizeScores(results), nil, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+	if exitCode != ExitInvalid {
+		t.Fatalf("score files: expected exit code %d got %d", ExitInvalid, exitCode)
+	}
+
+	for _, args := range [][]string{{filepath.Join(directory, "missing.json")}, {"-format", "xml"}} {
+		stdout.Reset()
+		if exitCode := RunScore(args, strings.NewReader(""), &stdout, &stderr); exitCode != ExitUsage {
+			t.Fatalf("score %v: expected exit code %d got %d", args, ExitUsage, exitCode)
+		}
+	}
+}
+
+func TestRunScoreItemPricesInCurrency(t *testing.T) {
+	// the built-in rules only score item prices in currencies like the dollar, so yen are scored by a rule of their own
+	rulesetPath := filepath.Join(t.TempDir(), "yen.json")
+	ruleset := `{"name": "yen", "version": 1, "rules": [{"name": "itemPrice", "type": "itemDescriptionLength", "lengthMultiple": 1, "priceMultiplier": 0.002, "currencies": ["JPY"]}]}`
+	if err := os.WriteFile(rulesetPath, []byte(ruleset), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yenReceipt := `{"retailer": "Lawson", "purchaseDate": "2022-01-02", "purchaseTime": "10:15", "currency": "JPY", "total": "1500", "items": [{"shortDescription": "Bento Box", "price": "1500"}]}`
+
+	var stdout, stderr bytes.Buffer
+	if exitCode := RunScore([]string{"-format", "json", "-ruleset", rulesetPath}, strings.NewReader(yenReceipt), &stdout, &stderr); exitCode != ExitValid {
+		t.Fatalf("score yen receipt: expected exit code %d got %d ( %s )", ExitValid, exitCode, stderr.String())
+	}
+	var results []struct {
+		Breakdown struct {
+			Rules []struct {
+				Item *struct {
+					Price json.Number `json:"price"`
+				} `json:"item"`
+			} `json:"rules"`
+		} `json:"breakdown"`
+	}
+	json.Unmarshal(stdout.Bytes(), &results)
+	if len(results) != 1 || len(results[0].Breakdown.Rules) != 1 || results[0].Breakdown.Rules[0].Item == nil || results[0].Breakdown.Rules[0].Item.Price != "1500" {
+		t.Fatalf("score yen receipt: expected the item to be priced ( 1500 ) yen got ( %s )", stdout.String())
+	}
+}
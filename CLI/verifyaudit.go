@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	audit "go-receipt-processor/Audit"
+	"io"
+	"os"
+)
+
+var (
+	ErrAuditLogTruncated = errors.New("audit log does not end with the expected hash")
+)
+
+// Verifies the hash chain of the audit log file given as the only argument, printing how many events were verified and the hash of the last one to stdout.
+// Returns the exit code for the process: ExitValid if the chain is intact, ExitInvalid if it was tampered with, and ExitUsage if the arguments were wrong or the file could not be opened.
+func RunVerifyAudit(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("verify-audit", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: go-receipt-processor verify-audit [flags] file")
+		fmt.Fprintln(stderr, "Checks that no event in the audit log file was changed, removed or reordered since it was recorded.")
+		flags.PrintDefaults()
+	}
+	expectedHash := flags.String("expect-hash", "", "hash of the last event from an earlier verification, so events removed from the end of the log are also noticed. If empty only the chain itself is checked")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	defer file.Close()
+	events, err := audit.ReadEvents(file)
+	if err == nil {
+		err = audit.Verify(events)
+	}
+	if err == nil && *expectedHash != "" && !containsHash(events, *expectedHash) {
+		err = fmt.Errorf("%w given \"%s\"", ErrAuditLogTruncated, *expectedHash)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitInvalid
+	}
+
+	lastHash := ""
+	if len(events) > 0 {
+		lastHash = events[len(events)-1].Hash
+	}
+	fmt.Fprintf(stdout, "verified %d events, last hash %s\n", len(events), lastHash)
+	return ExitValid
+}
+
+// Reports whether any event has the hash, so a log that has grown since the hash was taken still verifies
+func containsHash(events []audit.Event, hash string) bool {
+	for _, event := range events {
+		if event.Hash == hash {
+			return true
+		}
+	}
+	return false
+}
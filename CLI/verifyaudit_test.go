@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	audit "go-receipt-processor/Audit"
+	utils "go-receipt-processor/TestingUtils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestAuditLog(t *testing.T) (string, []audit.Event) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.OpenFileLog(path)
+	if err != nil {
+		t.Fatalf("open audit log: unexpected error ( %v )", err)
+	}
+	defer auditLog.Close()
+	events := []audit.Event{}
+	for _, entry := range []audit.Entry{
+		{Origin: audit.Origin{Actor: "alice", RequestId: "request-1"}, Action: audit.ActionProcess, ReceiptId: "a", AfterHash: "hash-a1"},
+		{Origin: audit.Origin{Actor: "bob", RequestId: "request-2"}, Action: audit.ActionAmend, ReceiptId: "a", BeforeHash: "hash-a1", AfterHash: "hash-a2"},
+		{Origin: audit.Origin{Actor: "bob", RequestId: "request-3"}, Action: audit.ActionDelete, ReceiptId: "a", BeforeHash: "hash-a2"},
+	} {
+		event, err := auditLog.Append(entry)
+		if err != nil {
+			t.Fatalf("append ( %+v ): unexpected error ( %v )", entry, err)
+		}
+		events = append(events, event)
+	}
+	return path, events
+}
+
+func TestRunVerifyAudit(t *testing.T) {
+	path, events := writeTestAuditLog(t)
+	data, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	tampered := filepath.Join(filepath.Dir(path), "tampered.log")
+
+	var testCases []utils.CreationTestingData[[]string, int] = []utils.CreationTestingData[[]string, int]{
+		{Argument: []string{"", path}, ExpectedResult: ExitValid},
+		{Argument: []string{"", "-expect-hash", events[1].Hash, path}, ExpectedResult: ExitValid},
+		{Argument: []string{strings.Replace(string(data), `"actor":"bob"`, `"actor":"eve"`, 1), tampered}, ExpectedResult: ExitInvalid},
+		{Argument: []string{lines[0] + lines[2], tampered}, ExpectedResult: ExitInvalid},
+		{ //Tests removing events from the end of the log, which is only noticed when the last hash is known
+			Argument:       []string{lines[0] + lines[1], "-expect-hash", events[2].Hash, tampered},
+			ExpectedResult: ExitInvalid,
+		},
+		{Argument: []string{"not json\n", tampered}, ExpectedResult: ExitInvalid},
+		{Argument: []string{"", filepath.Join(filepath.Dir(path), "missing.log")}, ExpectedResult: ExitUsage},
+		{Argument: []string{""}, ExpectedResult: ExitUsage},
+		{Argument: []string{"", path, path}, ExpectedResult: ExitUsage},
+	}
+	for _, testCase := range testCases {
+		if testCase.Argument[0] != "" {
+			os.WriteFile(tampered, []byte(testCase.Argument[0]), 0o644)
+		}
+		var stdout, stderr bytes.Buffer
+		exitCode := RunVerifyAudit(testCase.Argument[1:], &stdout, &stderr)
+		errCheck := testCase.CheckTestCase("verify audit", exitCode, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s ( %s )", errCheck.Error(), stderr.String())
+		}
+		if exitCode == ExitValid && !strings.Contains(stdout.String(), "verified 3 events, last hash "+events[2].Hash) {
+			t.Fatalf("verify audit: expected the number of events and last hash to be printed got ( %s )", stdout.String())
+		}
+	}
+}
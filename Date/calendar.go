@@ -0,0 +1,181 @@
+package date
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	weekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+	ErrDateOutOfRange = errors.New("date out of range")
+)
+
+const (
+	MinYear uint16 = 0
+	MaxYear uint16 = 9999 // the largest year that can be written as YYYY
+
+	// days from 0000-03-01, the start of the proleptic Gregorian calendar's first 400 year era, to 1970-01-01
+	daysToUnixEpoch int64 = 719468
+	daysPerEra      int64 = 146097
+)
+
+type Weekday uint8
+
+const (
+	Sunday Weekday = iota
+	Monday
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+)
+
+func (w Weekday) String() string {
+	if int(w) >= len(weekdayNames) {
+		return "invalid weekday"
+	}
+	return weekdayNames[w]
+}
+
+// Whether the day is a Saturday or a Sunday
+func (w Weekday) IsWeekend() bool {
+	return w == Saturday || w == Sunday
+}
+
+// Follows the Gregorian calendar, where years divisible by 100 are only leap years if they are also divisible by 400, i.e. 2000 is a leap year but 1900 and 2100 are not
+func IsLeapYear(year uint16) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func GetDaysInYear(year uint16) int {
+	if IsLeapYear(year) {
+		return 366
+	}
+	return 365
+}
+
+// Returns the number of days from 1970-01-01 to the date, which is negative for earlier dates.
+// Uses the proleptic Gregorian calendar, treating years as 400 year eras that start on March 1st so the leap day is always the last day of the year.
+func (d Date) daysSinceUnixEpoch() int64 {
+	return daysFromCivil(int64(d.Year), d.Month, d.Day)
+}
+
+// Takes the year as a signed value so the start of the weeks around year 0 and year 9999 can be found
+func daysFromCivil(year int64, month uint8, day uint8) int64 {
+	if month <= 2 {
+		year--
+	}
+	era := floorDivide(year, 400)
+	yearOfEra := year - era*400
+	monthFromMarch := (int64(month) + 9) % 12
+	dayOfYear := (153*monthFromMarch+2)/5 + int64(day) - 1
+	dayOfEra := yearOfEra*365 + yearOfEra/4 - yearOfEra/100 + dayOfYear
+	return era*daysPerEra + dayOfEra - daysToUnixEpoch
+}
+
+// The inverse of daysSinceUnixEpoch, returning the year as a signed value so days before year 0 can be checked for
+func civilFromDaysSinceUnixEpoch(days int64) (int64, uint8, uint8) {
+	days += daysToUnixEpoch
+	era := floorDivide(days, daysPerEra)
+	dayOfEra := days - era*daysPerEra
+	yearOfEra := (dayOfEra - dayOfEra/1460 + dayOfEra/36524 - dayOfEra/146096) / 365
+	dayOfYear := dayOfEra - (365*yearOfEra + yearOfEra/4 - yearOfEra/100)
+	monthFromMarch := (5*dayOfYear + 2) / 153
+	day := dayOfYear - (153*monthFromMarch+2)/5 + 1
+	month := monthFromMarch + 3
+	if month > 12 {
+		month -= 12
+	}
+	year := yearOfEra + era*400
+	if month <= 2 {
+		year++
+	}
+	return year, uint8(month), uint8(day)
+}
+
+func floorDivide(a int64, b int64) int64 {
+	quotient := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		quotient--
+	}
+	return quotient
+}
+
+func dateFromDaysSinceUnixEpoch(days int64) (Date, error) {
+	year, month, day := civilFromDaysSinceUnixEpoch(days)
+	if year < int64(MinYear) || year > int64(MaxYear) {
+		return Date{}, fmt.Errorf("%w given year %d ( valid years range inclusively from %d to %d )", ErrDateOutOfRange, year, MinYear, MaxYear)
+	}
+	return Date{Year: uint16(year), Month: month, Day: day}, nil
+}
+
+func (d Date) Weekday() Weekday {
+	// 1970-01-01 was a Thursday
+	return Weekday(floorModulo(d.daysSinceUnixEpoch()+int64(Thursday), 7))
+}
+
+func floorModulo(a int64, b int64) int64 {
+	return a - floorDivide(a, b)*b
+}
+
+// Returns the day of the year, starting from 1 for January 1st
+func (d Date) DayOfYear() int {
+	return int(d.daysSinceUnixEpoch()-daysFromCivil(int64(d.Year), 1, 1)) + 1
+}
+
+// Returns the date the given number of days later, or earlier when the number is negative
+func (d Date) AddDays(days int) (Date, error) {
+	if err := d.IsValid(); err != nil {
+		return Date{}, err
+	}
+	return dateFromDaysSinceUnixEpoch(d.daysSinceUnixEpoch() + int64(days))
+}
+
+// Returns the date the given number of months later, or earlier when the number is negative.
+// When the day does not exist in the resulting month, the last day of that month is used instead, i.e. one month after 2024-01-31 is 2024-02-29.
+func (d Date) AddMonths(months int) (Date, error) {
+	if err := d.IsValid(); err != nil {
+		return Date{}, err
+	}
+	totalMonths := int64(d.Year)*12 + int64(d.Month) - 1 + int64(months)
+	year := floorDivide(totalMonths, 12)
+	if year < int64(MinYear) || year > int64(MaxYear) {
+		return Date{}, fmt.Errorf("%w given year %d ( valid years range inclusively from %d to %d )", ErrDateOutOfRange, year, MinYear, MaxYear)
+	}
+	result := Date{Year: uint16(year), Month: uint8(floorModulo(totalMonths, 12)) + 1, Day: d.Day}
+	if lastDay := GetValidDaysInMonth(result.Year, result.Month); result.Day > lastDay {
+		result.Day = lastDay
+	}
+	return result, nil
+}
+
+// Returns the number of days from the other date to this one, which is negative when this date is earlier
+func (d Date) DaysSince(other Date) int {
+	return int(d.daysSinceUnixEpoch() - other.daysSinceUnixEpoch())
+}
+
+func (d Date) Before(other Date) bool {
+	return d.Compare(other) < 0
+}
+
+func (d Date) After(other Date) bool {
+	return d.Compare(other) > 0
+}
+
+// Sorts the dates from earliest to latest
+func SortDates(dates []Date) {
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+}
+
+// Returns the ISO 8601 week of the date, where weeks start on Monday and the first week of a year is the one containing its first Thursday.
+// The year of the week can differ from the year of the date at the start and end of a year, i.e. 2021-01-01 is in week 53 of 2020.
+func (d Date) ISOWeek() (int, uint8) {
+	days := d.daysSinceUnixEpoch()
+	daysFromMonday := floorModulo(int64(d.Weekday())+6, 7)
+	thursday := days - daysFromMonday + 3 // the Thursday of the same week decides which year the week belongs to
+	year, _, _ := civilFromDaysSinceUnixEpoch(thursday)
+	return int(year), uint8((thursday-daysFromCivil(year, 1, 1))/7 + 1)
+}
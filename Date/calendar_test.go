@@ -0,0 +1,209 @@
+package date
+
+import (
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+	systime "time"
+)
+
+func Test_IsLeapYear(t *testing.T) {
+	var testCases []utils.CreationTestingData[uint16, bool] = []utils.CreationTestingData[uint16, bool]{
+		{Argument: 2004, ExpectedResult: true},
+		{Argument: 2023, ExpectedResult: false},
+		{Argument: 2000, ExpectedResult: true},
+		{Argument: 1600, ExpectedResult: true},
+		{Argument: 1900, ExpectedResult: false},
+		{Argument: 2100, ExpectedResult: false},
+		{Argument: 0, ExpectedResult: true},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("is leap year", IsLeapYear(testCase.Argument), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_IsValidLeapDay(t *testing.T) {
+	var testCases []utils.CreationTestingData[Date, error] = []utils.CreationTestingData[Date, error]{
+		{Argument: Date{Year: 2000, Month: 2, Day: 29}, ExpectedErr: nil},
+		{Argument: Date{Year: 2024, Month: 2, Day: 29}, ExpectedErr: nil},
+		{Argument: Date{Year: 1900, Month: 2, Day: 29}, ExpectedErr: ErrInvalidDate},
+		{Argument: Date{Year: 2100, Month: 2, Day: 29}, ExpectedErr: ErrInvalidDate},
+		{Argument: Date{Year: 2023, Month: 2, Day: 29}, ExpectedErr: ErrInvalidDate},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("is valid leap day", nil, testCase.Argument.IsValid(), false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_Weekday(t *testing.T) {
+	var testCases []utils.CreationTestingData[Date, Weekday] = []utils.CreationTestingData[Date, Weekday]{
+		{Argument: Date{Year: 1970, Month: 1, Day: 1}, ExpectedResult: Thursday},
+		{Argument: Date{Year: 2023, Month: 10, Day: 15}, ExpectedResult: Sunday},
+		{Argument: Date{Year: 2024, Month: 2, Day: 29}, ExpectedResult: Thursday},
+		{Argument: Date{Year: 2000, Month: 1, Day: 1}, ExpectedResult: Saturday},
+		{Argument: Date{Year: 1900, Month: 3, Day: 1}, ExpectedResult: Thursday},
+		{Argument: Date{Year: 1, Month: 1, Day: 1}, ExpectedResult: Monday},
+		{Argument: Date{Year: 9999, Month: 12, Day: 31}, ExpectedResult: Friday},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("weekday", testCase.Argument.Weekday(), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+	if !Saturday.IsWeekend() || !Sunday.IsWeekend() || Friday.IsWeekend() {
+		t.Fatalf("is weekend: expected only Saturday and Sunday to be weekend days")
+	}
+}
+
+func Test_DayOfYear(t *testing.T) {
+	var testCases []utils.CreationTestingData[Date, int] = []utils.CreationTestingData[Date, int]{
+		{Argument: Date{Year: 2023, Month: 1, Day: 1}, ExpectedResult: 1},
+		{Argument: Date{Year: 2023, Month: 3, Day: 1}, ExpectedResult: 60},
+		{Argument: Date{Year: 2024, Month: 3, Day: 1}, ExpectedResult: 61},
+		{Argument: Date{Year: 2023, Month: 12, Day: 31}, ExpectedResult: 365},
+		{Argument: Date{Year: 2024, Month: 12, Day: 31}, ExpectedResult: 366},
+		{Argument: Date{Year: 1900, Month: 12, Day: 31}, ExpectedResult: 365},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("day of year", testCase.Argument.DayOfYear(), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+type addArguments struct {
+	Date   Date
+	Amount int
+}
+
+func Test_AddDays(t *testing.T) {
+	var testCases []utils.CreationTestingData[addArguments, Date] = []utils.CreationTestingData[addArguments, Date]{
+		{Argument: addArguments{Date: Date{Year: 2023, Month: 10, Day: 15}, Amount: 0}, ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: addArguments{Date: Date{Year: 2023, Month: 12, Day: 31}, Amount: 1}, ExpectedResult: Date{Year: 2024, Month: 1, Day: 1}},
+		{Argument: addArguments{Date: Date{Year: 2024, Month: 2, Day: 28}, Amount: 1}, ExpectedResult: Date{Year: 2024, Month: 2, Day: 29}},
+		{Argument: addArguments{Date: Date{Year: 2100, Month: 2, Day: 28}, Amount: 1}, ExpectedResult: Date{Year: 2100, Month: 3, Day: 1}},
+		{Argument: addArguments{Date: Date{Year: 2024, Month: 3, Day: 1}, Amount: -1}, ExpectedResult: Date{Year: 2024, Month: 2, Day: 29}},
+		{Argument: addArguments{Date: Date{Year: 2023, Month: 1, Day: 1}, Amount: 365}, ExpectedResult: Date{Year: 2024, Month: 1, Day: 1}},
+		{Argument: addArguments{Date: Date{Year: 2024, Month: 1, Day: 1}, Amount: -365}, ExpectedResult: Date{Year: 2023, Month: 1, Day: 1}},
+		//Tests that dates outside of what can be written as YYYY and invalid dates will correctly throw an error
+		{Argument: addArguments{Date: Date{Year: 9999, Month: 12, Day: 31}, Amount: 1}, ExpectedResult: Date{}, ExpectedErr: ErrDateOutOfRange},
+		{Argument: addArguments{Date: Date{Year: 0, Month: 1, Day: 1}, Amount: -1}, ExpectedResult: Date{}, ExpectedErr: ErrDateOutOfRange},
+		{Argument: addArguments{Date: Date{Year: 2023, Month: 2, Day: 29}, Amount: 1}, ExpectedResult: Date{}, ExpectedErr: ErrInvalidDate},
+	}
+	for _, testCase := range testCases {
+		result, err := testCase.Argument.Date.AddDays(testCase.Argument.Amount)
+		errCheck := testCase.CheckTestCase("add days", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_AddMonths(t *testing.T) {
+	var testCases []utils.CreationTestingData[addArguments, Date] = []utils.CreationTestingData[addArguments, Date]{
+		{Argument: addArguments{Date: Date{Year: 2023, Month: 10, Day: 15}, Amount: 1}, ExpectedResult: Date{Year: 2023, Month: 11, Day: 15}},
+		{Argument: addArguments{Date: Date{Year: 2023, Month: 11, Day: 15}, Amount: 2}, ExpectedResult: Date{Year: 2024, Month: 1, Day: 15}},
+		{Argument: addArguments{Date: Date{Year: 2024, Month: 1, Day: 15}, Amount: -13}, ExpectedResult: Date{Year: 2022, Month: 12, Day: 15}},
+		//Tests that days past the end of the resulting month are moved back to its last day
+		{Argument: addArguments{Date: Date{Year: 2024, Month: 1, Day: 31}, Amount: 1}, ExpectedResult: Date{Year: 2024, Month: 2, Day: 29}},
+		{Argument: addArguments{Date: Date{Year: 2023, Month: 1, Day: 31}, Amount: 1}, ExpectedResult: Date{Year: 2023, Month: 2, Day: 28}},
+		{Argument: addArguments{Date: Date{Year: 2024, Month: 2, Day: 29}, Amount: 12}, ExpectedResult: Date{Year: 2025, Month: 2, Day: 28}},
+		{Argument: addArguments{Date: Date{Year: 2023, Month: 5, Day: 31}, Amount: -1}, ExpectedResult: Date{Year: 2023, Month: 4, Day: 30}},
+		{Argument: addArguments{Date: Date{Year: 9999, Month: 12, Day: 1}, Amount: 1}, ExpectedResult: Date{}, ExpectedErr: ErrDateOutOfRange},
+		{Argument: addArguments{Date: Date{Year: 0, Month: 1, Day: 1}, Amount: -1}, ExpectedResult: Date{}, ExpectedErr: ErrDateOutOfRange},
+	}
+	for _, testCase := range testCases {
+		result, err := testCase.Argument.Date.AddMonths(testCase.Argument.Amount)
+		errCheck := testCase.CheckTestCase("add months", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_DaysSince(t *testing.T) {
+	var testCases []utils.CreationTestingData[[2]Date, int] = []utils.CreationTestingData[[2]Date, int]{
+		{Argument: [2]Date{{Year: 2022, Month: 1, Day: 1}, {Year: 2022, Month: 1, Day: 1}}, ExpectedResult: 0},
+		{Argument: [2]Date{{Year: 2024, Month: 3, Day: 1}, {Year: 2024, Month: 2, Day: 1}}, ExpectedResult: 29},
+		{Argument: [2]Date{{Year: 2023, Month: 3, Day: 1}, {Year: 2023, Month: 2, Day: 1}}, ExpectedResult: 28},
+		{Argument: [2]Date{{Year: 2000, Month: 1, Day: 1}, {Year: 2001, Month: 1, Day: 1}}, ExpectedResult: -366},
+		{Argument: [2]Date{{Year: 2100, Month: 1, Day: 1}, {Year: 1900, Month: 1, Day: 1}}, ExpectedResult: 73049},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("days since", testCase.Argument[0].DaysSince(testCase.Argument[1]), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_SortDates(t *testing.T) {
+	var testCases []utils.CreationTestingData[[]Date, []Date] = []utils.CreationTestingData[[]Date, []Date]{
+		{
+			Argument:       []Date{{Year: 2022, Month: 2, Day: 1}, {Year: 2021, Month: 12, Day: 31}, {Year: 2022, Month: 1, Day: 31}, {Year: 2022, Month: 1, Day: 2}},
+			ExpectedResult: []Date{{Year: 2021, Month: 12, Day: 31}, {Year: 2022, Month: 1, Day: 2}, {Year: 2022, Month: 1, Day: 31}, {Year: 2022, Month: 2, Day: 1}},
+		},
+		{Argument: []Date{}, ExpectedResult: []Date{}},
+	}
+	for _, testCase := range testCases {
+		SortDates(testCase.Argument)
+		errCheck := testCase.CheckTestCase("sort dates", testCase.Argument, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+	earlier, later := Date{Year: 2022, Month: 1, Day: 1}, Date{Year: 2022, Month: 1, Day: 2}
+	if !earlier.Before(later) || earlier.After(later) || !later.After(earlier) || earlier.Before(earlier) {
+		t.Fatalf("before and after: expected %s to be before %s", earlier, later)
+	}
+}
+
+type isoWeek struct {
+	Year int
+	Week uint8
+}
+
+func Test_ISOWeek(t *testing.T) {
+	var testCases []utils.CreationTestingData[Date, isoWeek] = []utils.CreationTestingData[Date, isoWeek]{
+		{Argument: Date{Year: 2023, Month: 10, Day: 15}, ExpectedResult: isoWeek{Year: 2023, Week: 41}},
+		{Argument: Date{Year: 2024, Month: 1, Day: 1}, ExpectedResult: isoWeek{Year: 2024, Week: 1}},
+		//Tests weeks that belong to a different year than the date
+		{Argument: Date{Year: 2021, Month: 1, Day: 1}, ExpectedResult: isoWeek{Year: 2020, Week: 53}},
+		{Argument: Date{Year: 2023, Month: 1, Day: 1}, ExpectedResult: isoWeek{Year: 2022, Week: 52}},
+		{Argument: Date{Year: 2024, Month: 12, Day: 30}, ExpectedResult: isoWeek{Year: 2025, Week: 1}},
+		{Argument: Date{Year: 2026, Month: 12, Day: 31}, ExpectedResult: isoWeek{Year: 2026, Week: 53}},
+		{Argument: Date{Year: 9999, Month: 12, Day: 31}, ExpectedResult: isoWeek{Year: 9999, Week: 52}},
+	}
+	for _, testCase := range testCases {
+		year, week := testCase.Argument.ISOWeek()
+		errCheck := testCase.CheckTestCase("iso week", isoWeek{Year: year, Week: week}, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+// Checks every day over several centuries against the standard library, which also uses the proleptic Gregorian calendar
+func Test_CalendarMatchesStandardLibrary(t *testing.T) {
+	start := Date{Year: 1599, Month: 12, Day: 1}
+	expected := systime.Date(1599, 12, 1, 0, 0, 0, 0, systime.UTC)
+	for i := 0; i < 365*600; i++ {
+		d, err := start.AddDays(i)
+		if err != nil {
+			t.Fatalf("add days ( %s + %d ): unexpected error ( %v )", start, i, err)
+		}
+		expectedYear, expectedWeek := expected.ISOWeek()
+		year, week := d.ISOWeek()
+		if d.String() != expected.Format("2006-01-02") || d.Weekday() != Weekday(expected.Weekday()) || d.DayOfYear() != expected.YearDay() || year != expectedYear || int(week) != expectedWeek || d.DaysSince(start) != i {
+			t.Fatalf("calendar ( %s ): does not match the standard library's %s", d, expected.Format("2006-01-02 Monday"))
+		}
+		expected = expected.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,19 @@
+package date
+
+import (
+	systime "time"
+)
+
+// Returns the calendar date of the moment in its own location, i.e. 2023-10-15 01:30 UTC is 2023-10-15 even though it is still the 14th in Chicago
+func FromStandard(moment systime.Time) Date {
+	year, month, day := moment.Date()
+	return Date{Year: uint16(year), Month: uint8(month), Day: uint8(day)}
+}
+
+// Returns the start of the day in the given location, or in UTC when the location is nil
+func (d Date) ToStandard(location *systime.Location) systime.Time {
+	if location == nil {
+		location = systime.UTC
+	}
+	return systime.Date(int(d.Year), systime.Month(d.Month), int(d.Day), 0, 0, 0, 0, location)
+}
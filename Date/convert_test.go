@@ -0,0 +1,47 @@
+package date
+
+import (
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+	systime "time"
+)
+
+func Test_FromStandard(t *testing.T) {
+	chicago, err := systime.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("load location: unexpected error ( %v )", err)
+	}
+	var testCases []utils.CreationTestingData[systime.Time, Date] = []utils.CreationTestingData[systime.Time, Date]{
+		{Argument: systime.Date(2023, 10, 15, 1, 30, 0, 0, systime.UTC), ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: systime.Date(2023, 10, 15, 1, 30, 0, 0, systime.UTC).In(chicago), ExpectedResult: Date{Year: 2023, Month: 10, Day: 14}},
+		{Argument: systime.Date(2024, 2, 29, 23, 59, 59, 0, systime.UTC), ExpectedResult: Date{Year: 2024, Month: 2, Day: 29}},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("date from standard", FromStandard(testCase.Argument), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_ToStandard(t *testing.T) {
+	chicago, err := systime.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("load location: unexpected error ( %v )", err)
+	}
+	var testCases []utils.CreationTestingData[Date, systime.Time] = []utils.CreationTestingData[Date, systime.Time]{
+		{Argument: Date{Year: 2023, Month: 10, Day: 15}, ExpectedResult: systime.Date(2023, 10, 15, 0, 0, 0, 0, systime.UTC)},
+		{Argument: Date{Year: 2024, Month: 2, Day: 29}, ExpectedResult: systime.Date(2024, 2, 29, 0, 0, 0, 0, systime.UTC)},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("date to standard", testCase.Argument.ToStandard(nil), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	startOfDay := Date{Year: 2023, Month: 10, Day: 15}.ToStandard(chicago)
+	if !startOfDay.Equal(systime.Date(2023, 10, 15, 5, 0, 0, 0, systime.UTC)) || FromStandard(startOfDay) != (Date{Year: 2023, Month: 10, Day: 15}) {
+		t.Fatalf("date to standard: expected the start of the day in Chicago to be 05:00 UTC got %v", startOfDay.UTC())
+	}
+}
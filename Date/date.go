@@ -33,6 +33,34 @@ func (d *Date) Equals(other Date) bool {
 	return (d.Year == other.Year && d.Month == other.Month && d.Day == other.Day)
 }
 
+// Returns -1 if the date is before the other date, 1 if it is after, and 0 if they are the same day
+func (d Date) Compare(other Date) int {
+	switch {
+	case d.Year != other.Year:
+		return compareUint(uint(d.Year), uint(other.Year))
+	case d.Month != other.Month:
+		return compareUint(uint(d.Month), uint(other.Month))
+	default:
+		return compareUint(uint(d.Day), uint(other.Day))
+	}
+}
+
+func compareUint(a uint, b uint) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Formats the date as YYYY-MM-DD, the same format it is parsed from
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
 func GetMonthName(month uint8) string {
 	if month < 1 || month > 12 {
 		return "invalid month"
@@ -44,7 +72,7 @@ func GetValidDaysInMonth(year uint16, month uint8) uint8 {
 	if month < 1 || month > 12 {
 		return 0 //month outside of bounds
 	}
-	if year%4 == 0 && month == 2 {
+	if month == 2 && IsLeapYear(year) {
 		return 29 // February has 29 days on a Leap Year
 	}
 	return validDaysPerMonth[month-1]
@@ -110,7 +138,7 @@ func ParseDate(dateString string, validateResults bool) (Date, error) {
 	if err == nil {
 		date.Year = uint16(yearValue)
 	} else {
-		parsingYearErr = fmt.Errorf("%w ... given %s ... %s", ErrParsingYear, yearString, err.Error())
+		parsingYearErr = fmt.Errorf("%w ... given %s ... %w", ErrParsingYear, yearString, err)
 	}
 
 	var parsingMonthErr error = nil
@@ -118,7 +146,7 @@ func ParseDate(dateString string, validateResults bool) (Date, error) {
 	if err == nil {
 		date.Month = uint8(monthValue)
 	} else {
-		parsingMonthErr = fmt.Errorf("%w given %s ... %s", ErrParsingMonth, monthString, err.Error())
+		parsingMonthErr = fmt.Errorf("%w given %s ... %w", ErrParsingMonth, monthString, err)
 	}
 
 	var parsingDayErr error = nil
@@ -127,11 +155,11 @@ func ParseDate(dateString string, validateResults bool) (Date, error) {
 	if err == nil {
 		date.Day = uint8(dayValue)
 	} else {
-		parsingDayErr = fmt.Errorf("%w given %s ... %s", ErrParsingDay, dayString, err.Error())
+		parsingDayErr = fmt.Errorf("%w given %s ... %w", ErrParsingDay, dayString, err)
 	}
 
 	if parsingYearErr != nil || parsingMonthErr != nil || parsingDayErr != nil {
-		return date, fmt.Errorf("%w given %s ... %s", ErrParsingDate, dateString, errors.Join(parsingYearErr, parsingMonthErr, parsingDayErr).Error())
+		return date, fmt.Errorf("%w given %s ... %w", ErrParsingDate, dateString, errors.Join(parsingYearErr, parsingMonthErr, parsingDayErr))
 	}
 	if validateResults {
 		return date, date.IsValid()
@@ -30,3 +30,18 @@ func Test_ParseDate(t *testing.T) {
 
 	}
 }
+
+func Test_Compare(t *testing.T) {
+	var testCases []utils.CreationTestingData[[2]Date, int] = []utils.CreationTestingData[[2]Date, int]{
+		{Argument: [2]Date{{Year: 2022, Month: 1, Day: 1}, {Year: 2022, Month: 1, Day: 1}}, ExpectedResult: 0},
+		{Argument: [2]Date{{Year: 2021, Month: 12, Day: 31}, {Year: 2022, Month: 1, Day: 1}}, ExpectedResult: -1},
+		{Argument: [2]Date{{Year: 2022, Month: 2, Day: 1}, {Year: 2022, Month: 1, Day: 31}}, ExpectedResult: 1},
+		{Argument: [2]Date{{Year: 2022, Month: 1, Day: 2}, {Year: 2022, Month: 1, Day: 10}}, ExpectedResult: -1},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("compare dates", testCase.Argument[0].Compare(testCase.Argument[1]), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package date
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	systime "time"
+)
+
+var (
+	ErrScanningDate = errors.New("scanning date")
+)
+
+// Dates are written as "YYYY-MM-DD" strings in json
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Reads a "YYYY-MM-DD" string, or the {"Year", "Month", "Day"} object dates were written as before they had their own json format, so previously persisted receipts can still be read
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		type legacyDate Date // has none of Date's methods, so is decoded field by field
+		var legacy legacyDate
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return fmt.Errorf("%w given %s ... %w", ErrParsingDate, data, err)
+		}
+		*d = Date(legacy)
+		return nil
+	}
+	var dateString string
+	if err := json.Unmarshal(data, &dateString); err != nil {
+		return fmt.Errorf("%w given %s ... %w", ErrParsingDate, data, err)
+	}
+	return d.UnmarshalText([]byte(dateString))
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Reads a "YYYY-MM-DD" string, rejecting dates that do not exist
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text), true)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Stores the date as "YYYY-MM-DD" text, which sorts in the same order as the dates
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Reads a date stored as "YYYY-MM-DD" text, or as a timestamp by drivers that convert date columns themselves.
+// Stored dates are not validated again, so rows written by older versions are still readable.
+func (d *Date) Scan(src any) error {
+	switch value := src.(type) {
+	case string:
+		return d.scanString(value)
+	case []byte:
+		return d.scanString(string(value))
+	case systime.Time:
+		*d = FromStandard(value)
+		return nil
+	default:
+		return fmt.Errorf("%w given a %T ( dates can only be read from text or timestamps )", ErrScanningDate, src)
+	}
+}
+
+func (d *Date) scanString(dateString string) error {
+	parsed, err := ParseDate(dateString, false)
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrScanningDate, err)
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,91 @@
+package date
+
+import (
+	"encoding/json"
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+	systime "time"
+)
+
+func Test_DateMarshalJSON(t *testing.T) {
+	var testCases []utils.CreationTestingData[Date, string] = []utils.CreationTestingData[Date, string]{
+		{Argument: Date{Year: 2023, Month: 10, Day: 15}, ExpectedResult: `"2023-10-15"`},
+		{Argument: Date{Year: 900, Month: 1, Day: 2}, ExpectedResult: `"0900-01-02"`},
+	}
+	for _, testCase := range testCases {
+		result, err := json.Marshal(testCase.Argument)
+		errCheck := testCase.CheckTestCase("marshal date json", string(result), err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_DateUnmarshalJSON(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, Date] = []utils.CreationTestingData[string, Date]{
+		{Argument: `"2023-10-15"`, ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: `null`, ExpectedResult: Date{}},
+		//Tests that dates persisted before they had their own json format can still be read
+		{Argument: `{"Year": 2022, "Month": 3, "Day": 20}`, ExpectedResult: Date{Year: 2022, Month: 3, Day: 20}},
+		//Tests invalid dates will correctly throw an error
+		{Argument: `"2023-02-29"`, ExpectedResult: Date{}, ExpectedErr: ErrInvalidDate},
+		{Argument: `"10/15/2023"`, ExpectedResult: Date{}, ExpectedErr: ErrInvalidDateSyntax},
+		{Argument: `20231015`, ExpectedResult: Date{}, ExpectedErr: ErrParsingDate},
+		{Argument: `{"Year": "2022"}`, ExpectedResult: Date{}, ExpectedErr: ErrParsingDate},
+	}
+	for _, testCase := range testCases {
+		var result Date
+		err := json.Unmarshal([]byte(testCase.Argument), &result)
+		errCheck := testCase.CheckTestCase("unmarshal date json", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_DateText(t *testing.T) {
+	type wrapper struct {
+		Dates map[Date]int // map keys are written with MarshalText
+	}
+	original := wrapper{Dates: map[Date]int{{Year: 2023, Month: 10, Day: 15}: 1, {Year: 2024, Month: 2, Day: 29}: 2}}
+	encoded, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("marshal date text: unexpected error ( %v )", err)
+	}
+	if string(encoded) != `{"Dates":{"2023-10-15":1,"2024-02-29":2}}` {
+		t.Fatalf("marshal date text: unexpected json ( %s )", encoded)
+	}
+	var decoded wrapper
+	err = json.Unmarshal(encoded, &decoded)
+	testCase := utils.CreationTestingData[string, wrapper]{Argument: string(encoded), ExpectedResult: original}
+	errCheck := testCase.CheckTestCase("unmarshal date text", decoded, err, false)
+	if errCheck != nil {
+		t.Fatalf("%s", errCheck.Error())
+	}
+}
+
+func Test_DateScan(t *testing.T) {
+	var testCases []utils.CreationTestingData[any, Date] = []utils.CreationTestingData[any, Date]{
+		{Argument: "2023-10-15", ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: []byte("2023-10-15"), ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: systime.Date(2023, 10, 15, 0, 0, 0, 0, systime.UTC), ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		//Tests that dates accepted by older versions are still read
+		{Argument: "2100-02-29", ExpectedResult: Date{Year: 2100, Month: 2, Day: 29}},
+		{Argument: "Oct 15 2023", ExpectedResult: Date{}, ExpectedErr: ErrScanningDate},
+		{Argument: nil, ExpectedResult: Date{}, ExpectedErr: ErrScanningDate},
+		{Argument: int64(20231015), ExpectedResult: Date{}, ExpectedErr: ErrScanningDate},
+	}
+	for _, testCase := range testCases {
+		var result Date
+		err := result.Scan(testCase.Argument)
+		errCheck := testCase.CheckTestCase("scan date", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	value, err := Date{Year: 2023, Month: 10, Day: 15}.Value()
+	if err != nil || value != "2023-10-15" {
+		t.Fatalf("date value: expected ( 2023-10-15 ) got ( %v ) with error ( %v )", value, err)
+	}
+}
@@ -0,0 +1,222 @@
+package date
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownLayout   = errors.New("unknown date layout")
+	ErrUnknownDayOrder = errors.New("unknown day order")
+	ErrAmbiguousDate   = errors.New("ambiguous date")
+
+	layoutDefinitions = map[Layout]layoutDefinition{
+		LayoutISO:          {pattern: regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`), year: 1, month: 2, day: 3},
+		LayoutMonthDayYear: {pattern: regexp.MustCompile(`^(\d{1,2})/(\d{1,2})/(\d{4})$`), month: 1, day: 2, year: 3, order: MonthFirst},
+		LayoutDayMonthYear: {pattern: regexp.MustCompile(`^(\d{1,2})/(\d{1,2})/(\d{4})$`), day: 1, month: 2, year: 3, order: DayFirst},
+		LayoutDotted:       {pattern: regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})\.(\d{4})$`), day: 1, month: 2, year: 3, order: DayFirst},
+		LayoutMonthName:    {pattern: regexp.MustCompile(`^([A-Za-z]{3,9})\.? +(\d{1,2}),? +(\d{4})$`), month: 1, day: 2, year: 3},
+	}
+)
+
+// Names a way of writing a date that can be parsed, written the same way it is given in configuration
+type Layout string
+
+const (
+	LayoutISO          Layout = "YYYY-MM-DD"
+	LayoutMonthDayYear Layout = "MM/DD/YYYY"
+	LayoutDayMonthYear Layout = "DD/MM/YYYY"
+	LayoutDotted       Layout = "DD.MM.YYYY"
+	LayoutMonthName    Layout = "Mon DD YYYY" // i.e. "Oct 15 2023", "Oct. 15, 2023" or "October 15 2023"
+)
+
+// Which of the day and the month is written first, used to choose between layouts when a date could be read either way
+type DayOrder uint8
+
+const (
+	UnknownOrder DayOrder = iota
+	MonthFirst
+	DayFirst
+)
+
+func (o DayOrder) String() string {
+	switch o {
+	case MonthFirst:
+		return "month-first"
+	case DayFirst:
+		return "day-first"
+	default:
+		return ""
+	}
+}
+
+func ParseDayOrder(orderString string) (DayOrder, error) {
+	switch strings.ToLower(strings.Trim(orderString, " ")) {
+	case "":
+		return UnknownOrder, nil
+	case MonthFirst.String():
+		return MonthFirst, nil
+	case DayFirst.String():
+		return DayFirst, nil
+	default:
+		return UnknownOrder, fmt.Errorf("%w given \"%s\" ( valid orders are %s and %s )", ErrUnknownDayOrder, orderString, MonthFirst, DayFirst)
+	}
+}
+
+// Where each part of the date is found in the layout's pattern
+type layoutDefinition struct {
+	pattern *regexp.Regexp
+	year    int
+	month   int
+	day     int
+	order   DayOrder
+}
+
+func (l layoutDefinition) build(match []string) (Date, error) {
+	date := Date{}
+	var parsingYearErr error = nil
+	yearValue, err := strconv.ParseUint(match[l.year], 10, 16)
+	if err == nil {
+		date.Year = uint16(yearValue)
+	} else {
+		parsingYearErr = fmt.Errorf("%w ... given %s ... %w", ErrParsingYear, match[l.year], err)
+	}
+
+	var parsingMonthErr error = nil
+	monthValue, err := parseMonth(match[l.month])
+	if err == nil {
+		date.Month = monthValue
+	} else {
+		parsingMonthErr = fmt.Errorf("%w given %s ... %w", ErrParsingMonth, match[l.month], err)
+	}
+
+	var parsingDayErr error = nil
+	dayValue, err := strconv.ParseUint(match[l.day], 10, 8)
+	if err == nil {
+		date.Day = uint8(dayValue)
+	} else {
+		parsingDayErr = fmt.Errorf("%w given %s ... %w", ErrParsingDay, match[l.day], err)
+	}
+
+	if parsingYearErr != nil || parsingMonthErr != nil || parsingDayErr != nil {
+		return date, fmt.Errorf("%w given %s ... %w", ErrParsingDate, match[0], errors.Join(parsingYearErr, parsingMonthErr, parsingDayErr))
+	}
+	return date, nil
+}
+
+// Parses a month written as a number, its full name, or the first three letters of its name
+func parseMonth(monthString string) (uint8, error) {
+	if monthValue, err := strconv.ParseUint(monthString, 10, 8); err == nil {
+		return uint8(monthValue), nil
+	}
+	for i, monthName := range monthNames {
+		if strings.EqualFold(monthString, monthName) || strings.EqualFold(monthString, monthName[:3]) || (monthName == "September" && strings.EqualFold(monthString, "Sept")) {
+			return uint8(i + 1), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown month name ( valid names are full month names or their first three letters, i.e. October or Oct )")
+}
+
+// The layouts dates are accepted in, and the order of the day and month to assume when a date matches more than one of them
+type Formats struct {
+	Layouts []Layout
+	Order   DayOrder
+}
+
+// Accepts every layout, without assuming an order for dates like 03/04/2023 that could be read either way
+var DefaultFormats = Formats{Layouts: []Layout{LayoutISO, LayoutMonthDayYear, LayoutDayMonthYear, LayoutDotted, LayoutMonthName}}
+
+func ParseLayout(layoutString string) (Layout, error) {
+	layout := Layout(strings.Trim(layoutString, " "))
+	if _, isKnown := layoutDefinitions[layout]; !isKnown {
+		return "", fmt.Errorf("%w given \"%s\" ( valid layouts are %s )", ErrUnknownLayout, layoutString, describeLayouts(DefaultFormats.Layouts))
+	}
+	return layout, nil
+}
+
+// Parses a comma separated list of layouts, i.e. "YYYY-MM-DD, MM/DD/YYYY"
+func ParseFormats(layoutsString string, order DayOrder) (Formats, error) {
+	formats := Formats{Order: order}
+	for _, layoutString := range strings.Split(layoutsString, ",") {
+		layout, err := ParseLayout(layoutString)
+		if err != nil {
+			return Formats{}, err
+		}
+		formats.Layouts = append(formats.Layouts, layout)
+	}
+	return formats, nil
+}
+
+func describeLayouts(layouts []Layout) string {
+	names := make([]string, 0, len(layouts))
+	for _, layout := range layouts {
+		names = append(names, string(layout))
+	}
+	return strings.Join(names, ", ")
+}
+
+type candidateDate struct {
+	layout Layout
+	date   Date
+}
+
+// Parses the date with every layout that matches it.
+// When more than one layout gives a different valid date, i.e. 03/04/2023 as either March 4th or April 3rd, the day order decides between them, and without one an error is returned rather than guessing.
+func (f Formats) Parse(dateString string, validateResults bool) (Date, error) {
+	trimmed := strings.Join(strings.Fields(dateString), " ")
+	if trimmed == "" {
+		return Date{}, ErrEmptyDateString
+	}
+
+	matched := []candidateDate{}
+	valid := []candidateDate{}
+	for _, layout := range f.Layouts {
+		definition, isKnown := layoutDefinitions[layout]
+		if !isKnown {
+			continue
+		}
+		match := definition.pattern.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+		date, err := definition.build(match)
+		if err != nil {
+			return date, err
+		}
+		matched = append(matched, candidateDate{layout: layout, date: date})
+		if date.IsValid() == nil && !containsDate(valid, date) {
+			valid = append(valid, candidateDate{layout: layout, date: date})
+		}
+	}
+
+	switch {
+	case len(matched) == 0:
+		return Date{}, fmt.Errorf("%w given \"%s\" ( accepted formats are %s )", ErrInvalidDateSyntax, dateString, describeLayouts(f.Layouts))
+	case len(valid) == 0:
+		if validateResults {
+			return matched[0].date, matched[0].date.IsValid()
+		}
+		return matched[0].date, nil
+	case len(valid) == 1:
+		return valid[0].date, nil
+	}
+	for _, candidate := range valid {
+		if f.Order != UnknownOrder && layoutDefinitions[candidate.layout].order == f.Order {
+			return candidate.date, nil
+		}
+	}
+	return Date{}, fmt.Errorf("%w given \"%s\", it could be %s ( %s ) or %s ( %s ) ( set the day order to %s or %s to choose between them )",
+		ErrAmbiguousDate, dateString, valid[0].date, valid[0].layout, valid[1].date, valid[1].layout, MonthFirst, DayFirst)
+}
+
+func containsDate(candidates []candidateDate, date Date) bool {
+	for _, candidate := range candidates {
+		if candidate.date.Equals(date) {
+			return true
+		}
+	}
+	return false
+}
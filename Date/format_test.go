@@ -0,0 +1,76 @@
+package date
+
+import (
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+)
+
+type formatsArguments struct {
+	Formats    Formats
+	DateString string
+}
+
+func Test_FormatsParse(t *testing.T) {
+	monthFirst := Formats{Layouts: DefaultFormats.Layouts, Order: MonthFirst}
+	dayFirst := Formats{Layouts: DefaultFormats.Layouts, Order: DayFirst}
+	var testCases []utils.CreationTestingData[formatsArguments, Date] = []utils.CreationTestingData[formatsArguments, Date]{
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "2023-10-15"}, ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "10/15/2023"}, ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "15/10/2023"}, ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "15.10.2023"}, ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "Oct 15 2023"}, ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: " oct.  15, 2023 "}, ExpectedResult: Date{Year: 2023, Month: 10, Day: 15}},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "September 5 2023"}, ExpectedResult: Date{Year: 2023, Month: 9, Day: 5}},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "Sept 5 2023"}, ExpectedResult: Date{Year: 2023, Month: 9, Day: 5}},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "3/4/2023"}, ExpectedResult: Date{}, ExpectedErr: ErrAmbiguousDate},
+		//Tests that dates that could be read either way are only accepted with a day order
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "03/04/2023"}, ExpectedResult: Date{}, ExpectedErr: ErrAmbiguousDate},
+		{Argument: formatsArguments{Formats: monthFirst, DateString: "03/04/2023"}, ExpectedResult: Date{Year: 2023, Month: 3, Day: 4}},
+		{Argument: formatsArguments{Formats: dayFirst, DateString: "03/04/2023"}, ExpectedResult: Date{Year: 2023, Month: 4, Day: 3}},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "04/04/2023"}, ExpectedResult: Date{Year: 2023, Month: 4, Day: 4}},
+		{Argument: formatsArguments{Formats: monthFirst, DateString: "13/04/2023"}, ExpectedResult: Date{Year: 2023, Month: 4, Day: 13}},
+		//Tests that only the configured layouts are accepted
+		{Argument: formatsArguments{Formats: Formats{Layouts: []Layout{LayoutISO}}, DateString: "10/15/2023"}, ExpectedResult: Date{}, ExpectedErr: ErrInvalidDateSyntax},
+		{Argument: formatsArguments{Formats: Formats{Layouts: []Layout{LayoutMonthDayYear}}, DateString: "03/04/2023"}, ExpectedResult: Date{Year: 2023, Month: 3, Day: 4}},
+		//Tests that invalid dates will correctly throw an error
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: ""}, ExpectedResult: Date{}, ExpectedErr: ErrEmptyDateString},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "2023/10/15"}, ExpectedResult: Date{}, ExpectedErr: ErrInvalidDateSyntax},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "Oct 15th 2023"}, ExpectedResult: Date{}, ExpectedErr: ErrInvalidDateSyntax},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "Okt 15 2023"}, ExpectedResult: Date{Year: 2023, Day: 15}, ExpectedErr: ErrParsingMonth},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "29.02.2023"}, ExpectedResult: Date{Year: 2023, Month: 2, Day: 29}, ExpectedErr: ErrInvalidDate},
+		{Argument: formatsArguments{Formats: DefaultFormats, DateString: "13/13/2023"}, ExpectedResult: Date{Year: 2023, Month: 13, Day: 13}, ExpectedErr: ErrInvalidDate},
+	}
+	for _, testCase := range testCases {
+		result, err := testCase.Argument.Formats.Parse(testCase.Argument.DateString, true)
+		errCheck := testCase.CheckTestCase("parse date with formats", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+type parseFormatsArguments struct {
+	Layouts string
+	Order   string
+}
+
+func Test_ParseFormats(t *testing.T) {
+	var testCases []utils.CreationTestingData[parseFormatsArguments, Formats] = []utils.CreationTestingData[parseFormatsArguments, Formats]{
+		{Argument: parseFormatsArguments{Layouts: "YYYY-MM-DD", Order: ""}, ExpectedResult: Formats{Layouts: []Layout{LayoutISO}}},
+		{Argument: parseFormatsArguments{Layouts: "YYYY-MM-DD, DD/MM/YYYY,Mon DD YYYY", Order: "Day-First"}, ExpectedResult: Formats{Layouts: []Layout{LayoutISO, LayoutDayMonthYear, LayoutMonthName}, Order: DayFirst}},
+		{Argument: parseFormatsArguments{Layouts: "YYYY/MM/DD", Order: ""}, ExpectedResult: Formats{}, ExpectedErr: ErrUnknownLayout},
+		{Argument: parseFormatsArguments{Layouts: "", Order: ""}, ExpectedResult: Formats{}, ExpectedErr: ErrUnknownLayout},
+		{Argument: parseFormatsArguments{Layouts: "YYYY-MM-DD", Order: "year-first"}, ExpectedResult: Formats{}, ExpectedErr: ErrUnknownDayOrder},
+	}
+	for _, testCase := range testCases {
+		order, err := ParseDayOrder(testCase.Argument.Order)
+		result := Formats{}
+		if err == nil {
+			result, err = ParseFormats(testCase.Argument.Layouts, order)
+		}
+		errCheck := testCase.CheckTestCase("parse formats", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Ledger that appends each transaction to a newline delimited json journal file before applying it to the balances kept in memory.
+// The journal is replayed and checked when it is opened, so a ledger whose transactions do not balance is refused instead of being extended.
+// A journal that ends in a partially written transaction ( i.e. the process crashed mid-write ) is truncated back to the last complete transaction.
+type FileLedger struct {
+	*MemoryLedger
+	path   string
+	file   *os.File
+	closed bool
+}
+
+func OpenFileLedger(path string) (*FileLedger, error) {
+	l := &FileLedger{MemoryLedger: NewMemoryLedger(), path: path}
+	validLength, err := l.load()
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningLedger, path, err)
+	}
+	if err := l.check(); err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningLedger, path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningLedger, path, err)
+	}
+	// drops any partially written transaction left behind by a crash, so new transactions are appended after the last complete one
+	if err := file.Truncate(validLength); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningLedger, path, err)
+	}
+	if _, err := file.Seek(validLength, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningLedger, path, err)
+	}
+	l.file = file
+	l.writeAhead = l.write
+	return l, nil
+}
+
+// Adds every complete transaction in the journal to the ledger and returns the length of the file up to the end of the last complete transaction.
+// A missing file is treated as empty.
+func (l *FileLedger) load() (int64, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var validLength int64 = 0
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return validLength, nil // anything left without a newline was never completely written
+		} else if err != nil {
+			return validLength, err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			var transaction Transaction
+			if err := json.Unmarshal(data, &transaction); err != nil {
+				return validLength, fmt.Errorf("%w on line %d ... %w", ErrMalformedTransaction, line, err)
+			}
+			l.add(transaction)
+		}
+		validLength += int64(len(data))
+	}
+}
+
+// Durably appends the transaction to the journal. Called by the memory ledger with its mutex held.
+func (l *FileLedger) write(transaction Transaction) error {
+	if l.closed {
+		return ErrLedgerClosed
+	}
+	encoded, err := json.Marshal(transaction)
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrWritingLedger, err)
+	}
+	if _, err := l.file.Write(append(encoded, '\n')); err != nil {
+		return fmt.Errorf("%w ... %w", ErrWritingLedger, err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("%w ... %w", ErrWritingLedger, err)
+	}
+	return nil
+}
+
+func (l *FileLedger) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.file.Close()
+}
@@ -0,0 +1,96 @@
+package ledger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func openTestFileLedger(t *testing.T, path string) *FileLedger {
+	fileLedger, err := OpenFileLedger(path)
+	if err != nil {
+		t.Fatalf("open file ledger ( %s ): unexpected error ( %v )", path, err)
+	}
+	return fileLedger
+}
+
+func Test_FileLedger(t *testing.T) {
+	fileLedger := openTestFileLedger(t, filepath.Join(t.TempDir(), "ledger.jsonl"))
+	defer fileLedger.Close()
+	testLedger(t, fileLedger)
+}
+
+func Test_FileLedgerReplaysJournalOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	fileLedger := openTestFileLedger(t, path)
+	recordTestTransactions(t, fileLedger)
+	written, _ := fileLedger.Statement("alice")
+	fileLedger.Close()
+	if _, err := fileLedger.Redeem("bob", 1, ""); !errors.Is(err, ErrLedgerClosed) {
+		t.Fatalf("redeem: expected ( %v ) once closed got ( %v )", ErrLedgerClosed, err)
+	}
+
+	reopened := openTestFileLedger(t, path)
+	defer reopened.Close()
+	statement, _ := reopened.Statement("alice")
+	if !cmp.Equal(statement, written) {
+		t.Fatalf("replay journal: expected the same statement after reopening got ( %+v )", statement)
+	}
+	// the receipts credited before reopening are still known
+	if _, err := reopened.Credit("bob", "a", 5); !errors.Is(err, ErrReceiptAlreadyCredited) {
+		t.Fatalf("credit: expected ( %v ) after reopening got ( %v )", ErrReceiptAlreadyCredited, err)
+	}
+	transaction, err := reopened.Redeem("bob", 100, "")
+	if err != nil || transaction.Id != 8 {
+		t.Fatalf("redeem: expected transaction 8 after reopening got ( %+v ) error ( %v )", transaction, err)
+	}
+}
+
+func Test_FileLedgerDropsPartiallyWrittenTransaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	fileLedger := openTestFileLedger(t, path)
+	fileLedger.Credit("alice", "a", 28)
+	fileLedger.Close()
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, append(data, []byte(`{"id": 2, "kind": "cre`)...), 0o644)
+
+	reopened := openTestFileLedger(t, path)
+	if _, err := reopened.Credit("alice", "b", 12); err != nil {
+		t.Fatalf("credit: unexpected error ( %v )", err)
+	}
+	reopened.Close()
+	reopened = openTestFileLedger(t, path)
+	defer reopened.Close()
+	if balance, _, _ := reopened.Balance("alice"); balance != 40 {
+		t.Fatalf("balance: expected 40 once the partial transaction was replaced got %d", balance)
+	}
+}
+
+func Test_FileLedgerRefusesInconsistentJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	fileLedger := openTestFileLedger(t, path)
+	recordTestTransactions(t, fileLedger)
+	fileLedger.Close()
+	data, _ := os.ReadFile(path)
+
+	var testCases = []struct {
+		name        string
+		data        []byte
+		expectedErr error
+	}{
+		{name: "unbalanced", data: bytes.Replace(data, []byte(`{"account":"points:issued","points":-109}`), []byte(`{"account":"points:issued","points":-10}`), 1), expectedErr: ErrUnbalancedTransaction},
+		{name: "removed", data: data[bytes.IndexByte(data, '\n')+1:], expectedErr: ErrInconsistentLedger},
+		{name: "malformed", data: append([]byte("not json\n"), data...), expectedErr: ErrMalformedTransaction},
+	}
+	for _, testCase := range testCases {
+		os.WriteFile(path, testCase.data, 0o644)
+		_, err := OpenFileLedger(path)
+		if !errors.Is(err, ErrOpeningLedger) || !errors.Is(err, testCase.expectedErr) {
+			t.Fatalf("open %s file ledger: expected ( %v ) got ( %v )", testCase.name, testCase.expectedErr, err)
+		}
+	}
+}
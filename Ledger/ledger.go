@@ -0,0 +1,298 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Why points moved between accounts
+type Kind string
+
+const (
+	KindCredit     Kind = "credit"     // points earned by a processed receipt
+	KindRedemption Kind = "redemption" // points spent by a user
+	KindAdjustment Kind = "adjustment" // a correction, either to a receipt that was amended or deleted or made by hand
+)
+
+// The accounts points are issued from and redeemed into. Every user has their own account, named by UserAccount.
+const (
+	IssuedAccount     = "points:issued"
+	RedeemedAccount   = "points:redeemed"
+	userAccountPrefix = "user:"
+)
+
+var (
+	ErrEmptyUserId            = errors.New("empty user id")
+	ErrInvalidPoints          = errors.New("invalid points")
+	ErrInsufficientPoints     = errors.New("insufficient points")
+	ErrReceiptAlreadyCredited = errors.New("receipt points were already credited")
+	ErrUnbalancedTransaction  = errors.New("ledger transaction does not balance")
+	ErrInconsistentLedger     = errors.New("ledger is inconsistent")
+	ErrMalformedTransaction   = errors.New("malformed ledger transaction")
+	ErrOpeningLedger          = errors.New("opening ledger")
+	ErrWritingLedger          = errors.New("writing to ledger")
+	ErrLedgerClosed           = errors.New("ledger is closed")
+)
+
+// Returns the account a user's points are held in
+func UserAccount(userId string) string {
+	return userAccountPrefix + userId
+}
+
+// Points moved into an account, or out of it when negative
+type Posting struct {
+	Account string `json:"account"`
+	Points  int64  `json:"points"`
+}
+
+// A single movement of points, recorded as postings to two or more accounts that add up to zero, so points are never created or destroyed without a matching entry
+type Transaction struct {
+	Id          int64     `json:"id"`
+	Kind        Kind      `json:"kind"`
+	UserId      string    `json:"userId"`
+	ReceiptId   string    `json:"receiptId,omitempty"` // the receipt the points were earned by, empty for redemptions and adjustments made by hand
+	Description string    `json:"description,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+	Postings    []Posting `json:"postings"`
+}
+
+// Returns the points the transaction moved into the user's account, which are negative when points were taken out of it
+func (t Transaction) UserPoints() int64 {
+	points := int64(0)
+	for _, posting := range t.Postings {
+		if posting.Account == UserAccount(t.UserId) {
+			points += posting.Points
+		}
+	}
+	return points
+}
+
+// Checks that the transaction has at least two postings and that they add up to zero
+func (t Transaction) Validate() error {
+	if len(t.Postings) < 2 {
+		return fmt.Errorf("%w given transaction %d ... expected at least 2 postings got %d", ErrUnbalancedTransaction, t.Id, len(t.Postings))
+	}
+	sum := int64(0)
+	for _, posting := range t.Postings {
+		sum += posting.Points
+	}
+	if sum != 0 {
+		return fmt.Errorf("%w given transaction %d ... postings add up to %d", ErrUnbalancedTransaction, t.Id, sum)
+	}
+	return nil
+}
+
+// A double-entry record of the points earned and spent by every user.
+// Receipts are credited to the user who submitted them, and a receipt that is later amended or deleted is adjusted so the user only keeps the points it is currently worth.
+type Ledger interface {
+	// Credits the points a newly processed receipt is worth to the user who submitted it
+	Credit(userId string, receiptId string, points int64) (Transaction, error)
+	// Adjusts the points credited for a receipt to its new points, returning false if the receipt was never credited to a user or its points did not change
+	AdjustReceipt(receiptId string, points int64, description string) (Transaction, bool, error)
+	// Takes points out of the user's account, returning ErrInsufficientPoints if the user does not have enough
+	Redeem(userId string, points int64, description string) (Transaction, error)
+	// Adds points to the user's account by hand, or takes them out when negative
+	Adjust(userId string, points int64, description string) (Transaction, error)
+	// Returns the user's balance, and false if the user has no transactions
+	Balance(userId string) (int64, bool, error)
+	// Returns every transaction that moved the user's points, oldest first
+	Statement(userId string) ([]Transaction, error)
+	// Returns the user the receipt was credited to, and false if it was never credited to a user
+	Owner(receiptId string) (string, bool, error)
+	// Checks that every transaction balances, and that the balances kept for every account match the transactions
+	Check() error
+}
+
+// Ledger that only keeps its transactions in memory, so they are lost when the server stops
+type MemoryLedger struct {
+	mutex        sync.RWMutex
+	transactions []Transaction
+	balances     map[string]int64  // the balance of every account
+	byUser       map[string][]int  // the positions in transactions of each user's transactions
+	receipts     map[string]string // the user every credited receipt belongs to
+	credited     map[string]int64  // the points currently credited for every receipt
+
+	// called with each transaction before it is added, so it can be written somewhere durable first. The mutex is held while it runs.
+	writeAhead func(Transaction) error
+}
+
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{
+		transactions: []Transaction{},
+		balances:     map[string]int64{},
+		byUser:       map[string][]int{},
+		receipts:     map[string]string{},
+		credited:     map[string]int64{},
+	}
+}
+
+func (l *MemoryLedger) Credit(userId string, receiptId string, points int64) (Transaction, error) {
+	if userId == "" {
+		return Transaction{}, ErrEmptyUserId
+	}
+	if points < 0 {
+		return Transaction{}, fmt.Errorf("%w given %d ... a receipt can not be worth negative points", ErrInvalidPoints, points)
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if owner, found := l.receipts[receiptId]; found {
+		return Transaction{}, fmt.Errorf("%w given \"%s\" ... credited to user \"%s\"", ErrReceiptAlreadyCredited, receiptId, owner)
+	}
+	return l.commit(Transaction{Kind: KindCredit, UserId: userId, ReceiptId: receiptId, Description: "points earned by receipt", Postings: transfer(IssuedAccount, UserAccount(userId), points)})
+}
+
+func (l *MemoryLedger) AdjustReceipt(receiptId string, points int64, description string) (Transaction, bool, error) {
+	if points < 0 {
+		return Transaction{}, false, fmt.Errorf("%w given %d ... a receipt can not be worth negative points", ErrInvalidPoints, points)
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	userId, found := l.receipts[receiptId]
+	difference := points - l.credited[receiptId]
+	if !found || difference == 0 {
+		return Transaction{}, false, nil
+	}
+	transaction, err := l.commit(Transaction{Kind: KindAdjustment, UserId: userId, ReceiptId: receiptId, Description: description, Postings: transfer(IssuedAccount, UserAccount(userId), difference)})
+	return transaction, err == nil, err
+}
+
+func (l *MemoryLedger) Redeem(userId string, points int64, description string) (Transaction, error) {
+	if userId == "" {
+		return Transaction{}, ErrEmptyUserId
+	}
+	if points <= 0 {
+		return Transaction{}, fmt.Errorf("%w given %d ... expected a positive number of points to redeem", ErrInvalidPoints, points)
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	// checked while holding the mutex, so concurrent redemptions can not spend the same points twice
+	if balance := l.balances[UserAccount(userId)]; balance < points {
+		return Transaction{}, fmt.Errorf("%w given %d ... user \"%s\" has %d", ErrInsufficientPoints, points, userId, balance)
+	}
+	return l.commit(Transaction{Kind: KindRedemption, UserId: userId, Description: description, Postings: transfer(UserAccount(userId), RedeemedAccount, points)})
+}
+
+func (l *MemoryLedger) Adjust(userId string, points int64, description string) (Transaction, error) {
+	if userId == "" {
+		return Transaction{}, ErrEmptyUserId
+	}
+	if points == 0 {
+		return Transaction{}, fmt.Errorf("%w given %d ... expected a non-zero number of points to adjust by", ErrInvalidPoints, points)
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.commit(Transaction{Kind: KindAdjustment, UserId: userId, Description: description, Postings: transfer(IssuedAccount, UserAccount(userId), points)})
+}
+
+// Returns the postings that move the points from one account to another
+func transfer(from string, to string, points int64) []Posting {
+	return []Posting{{Account: from, Points: -points}, {Account: to, Points: points}}
+}
+
+// Numbers and timestamps the transaction, then writes it ahead and adds it. The caller must hold the mutex.
+func (l *MemoryLedger) commit(transaction Transaction) (Transaction, error) {
+	transaction.Id = int64(len(l.transactions) + 1)
+	transaction.Timestamp = time.Now().UTC()
+	if err := transaction.Validate(); err != nil {
+		return Transaction{}, err
+	}
+	if l.writeAhead != nil {
+		if err := l.writeAhead(transaction); err != nil {
+			return Transaction{}, err
+		}
+	}
+	l.add(transaction)
+	return transaction, nil
+}
+
+// Adds a transaction that has already been numbered and validated. The caller must hold the mutex.
+func (l *MemoryLedger) add(transaction Transaction) {
+	for _, posting := range transaction.Postings {
+		l.balances[posting.Account] += posting.Points
+	}
+	l.byUser[transaction.UserId] = append(l.byUser[transaction.UserId], len(l.transactions))
+	if transaction.ReceiptId != "" {
+		l.receipts[transaction.ReceiptId] = transaction.UserId
+		l.credited[transaction.ReceiptId] += transaction.UserPoints()
+	}
+	l.transactions = append(l.transactions, transaction)
+}
+
+func (l *MemoryLedger) Balance(userId string) (int64, bool, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	_, found := l.byUser[userId]
+	return l.balances[UserAccount(userId)], found, nil
+}
+
+func (l *MemoryLedger) Statement(userId string) ([]Transaction, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	positions := l.byUser[userId]
+	transactions := make([]Transaction, 0, len(positions))
+	for _, position := range positions {
+		transactions = append(transactions, l.transactions[position])
+	}
+	return transactions, nil
+}
+
+func (l *MemoryLedger) Owner(receiptId string) (string, bool, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	userId, found := l.receipts[receiptId]
+	return userId, found, nil
+}
+
+func (l *MemoryLedger) Check() error {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.check()
+}
+
+// Replays every transaction to rebuild the balances from scratch, and compares them with the balances that were kept as each transaction was added. The caller must hold the mutex.
+func (l *MemoryLedger) check() error {
+	balances := map[string]int64{}
+	credited := map[string]int64{}
+	for i, transaction := range l.transactions {
+		if expected := int64(i + 1); transaction.Id != expected {
+			return fmt.Errorf("%w given transaction %d ... expected id %d", ErrInconsistentLedger, transaction.Id, expected)
+		}
+		if err := transaction.Validate(); err != nil {
+			return fmt.Errorf("%w ... %w", ErrInconsistentLedger, err)
+		}
+		for _, posting := range transaction.Postings {
+			balances[posting.Account] += posting.Points
+		}
+		if transaction.ReceiptId != "" {
+			credited[transaction.ReceiptId] += transaction.UserPoints()
+		}
+	}
+
+	accounts := []string{}
+	for account := range l.balances {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	total := int64(0)
+	for _, account := range accounts {
+		if balances[account] != l.balances[account] {
+			return fmt.Errorf("%w given account \"%s\" ... expected a balance of %d got %d", ErrInconsistentLedger, account, balances[account], l.balances[account])
+		}
+		total += l.balances[account]
+	}
+	if len(balances) != len(l.balances) {
+		return fmt.Errorf("%w ... expected %d accounts got %d", ErrInconsistentLedger, len(balances), len(l.balances))
+	}
+	if total != 0 {
+		return fmt.Errorf("%w ... account balances add up to %d", ErrInconsistentLedger, total)
+	}
+	for receiptId, points := range credited {
+		if points < 0 || points != l.credited[receiptId] {
+			return fmt.Errorf("%w given receipt \"%s\" ... expected %d points credited got %d", ErrInconsistentLedger, receiptId, points, l.credited[receiptId])
+		}
+	}
+	return nil
+}
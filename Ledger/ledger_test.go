@@ -0,0 +1,166 @@
+package ledger
+
+import (
+	utils "go-receipt-processor/TestingUtils"
+	"strconv"
+	"testing"
+)
+
+// The parts of a transaction that are checked, leaving out its timestamp and postings
+type transactionOutcome struct {
+	Id         int64
+	Kind       Kind
+	ReceiptId  string
+	UserPoints int64
+}
+
+func summarizeTransactions(transactions []Transaction) []transactionOutcome {
+	outcomes := []transactionOutcome{}
+	for _, transaction := range transactions {
+		outcomes = append(outcomes, transactionOutcome{Id: transaction.Id, Kind: transaction.Kind, ReceiptId: transaction.ReceiptId, UserPoints: transaction.UserPoints()})
+	}
+	return outcomes
+}
+
+// Records the same transactions for two users on any ledger, failing the test on the first error
+func recordTestTransactions(t *testing.T, ledger Ledger) {
+	steps := []func() error{
+		func() error { _, err := ledger.Credit("alice", "a", 28); return err },
+		func() error { _, err := ledger.Credit("bob", "b", 109); return err },
+		func() error { _, err := ledger.Credit("alice", "c", 12); return err },
+		func() error { _, err := ledger.Redeem("alice", 30, "gift card"); return err },
+		func() error { _, _, err := ledger.AdjustReceipt("a", 15, "receipt amended"); return err },
+		func() error { _, _, err := ledger.AdjustReceipt("c", 0, "receipt deleted"); return err },
+		func() error { _, err := ledger.Adjust("bob", -9, "duplicate promotion"); return err },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("record transaction %d: unexpected error ( %v )", i+1, err)
+		}
+	}
+}
+
+// Runs the same checks against every implementation of Ledger
+func testLedger(t *testing.T, ledger Ledger) {
+	recordTestTransactions(t, ledger)
+
+	var statementTestCases []utils.CreationTestingData[string, []transactionOutcome] = []utils.CreationTestingData[string, []transactionOutcome]{
+		{
+			Argument: "alice",
+			ExpectedResult: []transactionOutcome{
+				{Id: 1, Kind: KindCredit, ReceiptId: "a", UserPoints: 28},
+				{Id: 3, Kind: KindCredit, ReceiptId: "c", UserPoints: 12},
+				{Id: 4, Kind: KindRedemption, UserPoints: -30},
+				{Id: 5, Kind: KindAdjustment, ReceiptId: "a", UserPoints: -13},
+				{Id: 6, Kind: KindAdjustment, ReceiptId: "c", UserPoints: -12},
+			},
+		},
+		{
+			Argument: "bob",
+			ExpectedResult: []transactionOutcome{
+				{Id: 2, Kind: KindCredit, ReceiptId: "b", UserPoints: 109},
+				{Id: 7, Kind: KindAdjustment, UserPoints: -9},
+			},
+		},
+		{Argument: "carol", ExpectedResult: []transactionOutcome{}},
+	}
+	for _, testCase := range statementTestCases {
+		statement, err := ledger.Statement(testCase.Argument)
+		errCheck := testCase.CheckTestCase("statement", summarizeTransactions(statement), err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	// alice redeemed points that were later taken back by a deleted receipt, so her balance can fall below zero
+	var balanceTestCases []utils.CreationTestingData[string, [2]int64] = []utils.CreationTestingData[string, [2]int64]{
+		{Argument: "alice", ExpectedResult: [2]int64{-15, 1}},
+		{Argument: "bob", ExpectedResult: [2]int64{100, 1}},
+		{Argument: "carol", ExpectedResult: [2]int64{0, 0}},
+	}
+	for _, testCase := range balanceTestCases {
+		balance, found, err := ledger.Balance(testCase.Argument)
+		foundFlag := int64(0)
+		if found {
+			foundFlag = 1
+		}
+		errCheck := testCase.CheckTestCase("balance", [2]int64{balance, foundFlag}, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	var errorTestCases []utils.CreationTestingData[func() error, error] = []utils.CreationTestingData[func() error, error]{
+		{Argument: func() error { _, err := ledger.Credit("", "d", 5); return err }, ExpectedErr: ErrEmptyUserId},
+		{Argument: func() error { _, err := ledger.Credit("bob", "a", 5); return err }, ExpectedErr: ErrReceiptAlreadyCredited},
+		{Argument: func() error { _, err := ledger.Credit("bob", "d", -5); return err }, ExpectedErr: ErrInvalidPoints},
+		{Argument: func() error { _, err := ledger.Redeem("alice", 1, ""); return err }, ExpectedErr: ErrInsufficientPoints},
+		{Argument: func() error { _, err := ledger.Redeem("bob", 101, ""); return err }, ExpectedErr: ErrInsufficientPoints},
+		{Argument: func() error { _, err := ledger.Redeem("bob", 0, ""); return err }, ExpectedErr: ErrInvalidPoints},
+		{Argument: func() error { _, err := ledger.Adjust("bob", 0, ""); return err }, ExpectedErr: ErrInvalidPoints},
+		{Argument: func() error { _, _, err := ledger.AdjustReceipt("b", -1, ""); return err }, ExpectedErr: ErrInvalidPoints},
+	}
+	for i, testCase := range errorTestCases {
+		errCheck := testCase.CheckTestCase("ledger error", nil, testCase.Argument(), false)
+		if errCheck != nil {
+			t.Fatalf("case %d %s", i, errCheck.Error())
+		}
+	}
+
+	var ownerTestCases []utils.CreationTestingData[string, [2]string] = []utils.CreationTestingData[string, [2]string]{
+		{Argument: "a", ExpectedResult: [2]string{"alice", "true"}},
+		{Argument: "b", ExpectedResult: [2]string{"bob", "true"}},
+		{Argument: "c", ExpectedResult: [2]string{"alice", "true"}}, // still belongs to alice after its points were taken back
+		{Argument: "unknown", ExpectedResult: [2]string{"", "false"}},
+	}
+	for _, testCase := range ownerTestCases {
+		owner, found, err := ledger.Owner(testCase.Argument)
+		errCheck := testCase.CheckTestCase("owner", [2]string{owner, strconv.FormatBool(found)}, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	// adjusting a receipt to the points it is already credited with, or one that was never credited, records nothing
+	for _, receiptId := range []string{"b", "unknown"} {
+		if _, adjusted, err := ledger.AdjustReceipt(receiptId, 109, ""); adjusted || err != nil {
+			t.Fatalf("adjust receipt ( %s ): expected nothing to be adjusted got adjusted ( %t ) error ( %v )", receiptId, adjusted, err)
+		}
+	}
+	if err := ledger.Check(); err != nil {
+		t.Fatalf("check: unexpected error ( %v )", err)
+	}
+}
+
+func Test_MemoryLedger(t *testing.T) {
+	testLedger(t, NewMemoryLedger())
+}
+
+func Test_Check(t *testing.T) {
+	var testCases []utils.CreationTestingData[func(*MemoryLedger), error] = []utils.CreationTestingData[func(*MemoryLedger), error]{
+		{Argument: func(l *MemoryLedger) {}},
+		{ //Tests a transaction whose postings do not add up to zero
+			Argument:    func(l *MemoryLedger) { l.transactions[1].Postings[0].Points = -100 },
+			ExpectedErr: ErrUnbalancedTransaction,
+		},
+		{ //Tests a transaction that was changed on both sides, which balances but no longer matches the balances that were kept
+			Argument: func(l *MemoryLedger) {
+				l.transactions[1].Postings[0].Points = -100
+				l.transactions[1].Postings[1].Points = 100
+			},
+			ExpectedErr: ErrInconsistentLedger,
+		},
+		{Argument: func(l *MemoryLedger) { l.balances[UserAccount("bob")] += 5 }, ExpectedErr: ErrInconsistentLedger},
+		{Argument: func(l *MemoryLedger) { l.transactions = l.transactions[1:] }, ExpectedErr: ErrInconsistentLedger},
+		{Argument: func(l *MemoryLedger) { l.credited["a"] = 28 }, ExpectedErr: ErrInconsistentLedger},
+	}
+	for i, testCase := range testCases {
+		ledger := NewMemoryLedger()
+		recordTestTransactions(t, ledger)
+		testCase.Argument(ledger)
+		errCheck := testCase.CheckTestCase("check", nil, ledger.Check(), false)
+		if errCheck != nil {
+			t.Fatalf("case %d %s", i, errCheck.Error())
+		}
+	}
+}
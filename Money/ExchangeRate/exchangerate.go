@@ -0,0 +1,185 @@
+package exchangerate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrParsingRateTable    = errors.New("parsing exchange rate table")
+	ErrUnknownFormat       = errors.New("unknown exchange rate table format")
+	ErrInvalidRate         = errors.New("invalid exchange rate")
+	ErrDuplicateRate       = errors.New("duplicate exchange rate")
+	ErrRateNotFound        = errors.New("no exchange rate found")
+	ErrMismatchedCurrency  = errors.New("exchange rate is for a different currency")
+	ErrMissingBaseCurrency = errors.New("exchange rate table has no base currency")
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// A single dated rate as it is written in the table file
+type RateDefinition struct {
+	Currency string  `json:"currency" yaml:"currency"`
+	Date     string  `json:"date" yaml:"date"` // formatted YYYY-MM-DD, the rate applies from this date until the next rate given for the currency
+	Rate     float64 `json:"rate" yaml:"rate"` // how many units of the base currency one unit of the currency is worth, i.e. 1.09 for EUR when the base is USD
+}
+
+// The rate an amount was converted with. It is recorded alongside the points it was used to calculate, so the points can be explained and recalculated even after the table changes.
+type Rate struct {
+	From          money.Currency `json:"from"`
+	To            money.Currency `json:"to"`
+	EffectiveDate string         `json:"effectiveDate"` // formatted YYYY-MM-DD
+	Rate          string         `json:"rate"`          // exact decimal, so converting never picks up floating point error
+}
+
+// Converts the amount, given in the rate's From currency, into its To currency, rounding half to even to the To currency's minor unit
+func (r Rate) Convert(amount money.Money) (money.Money, error) {
+	numerator, denominator, err := money.ParseDecimal(r.Rate)
+	if err != nil || numerator <= 0 {
+		return 0, fmt.Errorf("%w \"%s\" from %s to %s ... %w", ErrInvalidRate, r.Rate, r.From.Code(), r.To.Code(), err)
+	}
+	// the amounts are in minor units, so the difference in the number of minor units between the currencies is part of the rate
+	numerator *= int64(r.To.MajorUnit())
+	denominator *= int64(r.From.MajorUnit())
+	return amount.Multiply(numerator, denominator, money.RoundHalfEven), nil
+}
+
+type datedRate struct {
+	effectiveDate date.Date
+	rate          Rate
+}
+
+// Offline, dated exchange rates from a number of currencies into a single base currency
+type Table struct {
+	Base  string           `json:"base" yaml:"base"`
+	Rates []RateDefinition `json:"rates" yaml:"rates"`
+
+	baseCurrency money.Currency
+	compiled     map[money.Currency][]datedRate // each currency's rates in ascending order of date
+}
+
+// The currency every amount is converted into
+func (t *Table) BaseCurrency() money.Currency {
+	return t.baseCurrency
+}
+
+// Checks every rate in the table and orders them by date so they can be looked up
+func (t *Table) compile() error {
+	baseCurrency, err := money.ParseCurrency(t.Base)
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrMissingBaseCurrency, err)
+	}
+	compiled := map[money.Currency][]datedRate{}
+	errs := []error{}
+	for _, definition := range t.Rates {
+		invalid := func(reason string) error {
+			return fmt.Errorf("%w given %+v, %s", ErrInvalidRate, definition, reason)
+		}
+		currency, currencyErr := money.ParseCurrency(definition.Currency)
+		effectiveDate, dateErr := date.ParseDate(definition.Date, true)
+		if err := errors.Join(currencyErr, dateErr); err != nil {
+			errs = append(errs, fmt.Errorf("%w ... %w", invalid("currency and date must be valid"), err))
+			continue
+		}
+		if currency == baseCurrency {
+			errs = append(errs, invalid("rates can not be given for the base currency"))
+			continue
+		}
+		rate := strconv.FormatFloat(definition.Rate, 'f', -1, 64)
+		if numerator, _, err := money.ParseDecimal(rate); err != nil || numerator <= 0 {
+			errs = append(errs, invalid("rate must be an exact decimal greater than 0"))
+			continue
+		}
+		compiled[currency] = append(compiled[currency], datedRate{
+			effectiveDate: effectiveDate,
+			rate:          Rate{From: currency, To: baseCurrency, EffectiveDate: effectiveDate.String(), Rate: rate},
+		})
+	}
+	for currency, rates := range compiled {
+		sort.SliceStable(rates, func(i, j int) bool { return rates[i].effectiveDate.Compare(rates[j].effectiveDate) < 0 })
+		for i := 1; i < len(rates); i++ {
+			if rates[i].effectiveDate.Equals(rates[i-1].effectiveDate) {
+				errs = append(errs, fmt.Errorf("%w for %s on %s", ErrDuplicateRate, currency, rates[i].rate.EffectiveDate))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	t.baseCurrency = baseCurrency
+	t.compiled = compiled
+	return nil
+}
+
+// Returns the rate for converting the currency into the base currency on the given day, which is the latest rate dated on or before it.
+// Returns nil if the currency is already the base currency, since there is nothing to convert.
+func (t *Table) Lookup(currency money.Currency, on date.Date) (*Rate, error) {
+	currency = currency.OrDefault()
+	if currency == t.baseCurrency {
+		return nil, nil
+	}
+	rates := t.compiled[currency]
+	index := sort.Search(len(rates), func(i int) bool { return rates[i].effectiveDate.Compare(on) > 0 })
+	if index == 0 {
+		return nil, fmt.Errorf("%w from %s to %s on %s", ErrRateNotFound, currency.Code(), t.baseCurrency.Code(), on.String())
+	}
+	rate := rates[index-1].rate
+	return &rate, nil
+}
+
+// Parses and checks an exchange rate table given in either json or yaml
+func ParseTable(data []byte, format string) (*Table, error) {
+	table := &Table{}
+	var err error
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(table)
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(table)
+	default:
+		return nil, fmt.Errorf("%w \"%s\" ( valid formats are %s and %s )", ErrUnknownFormat, format, FormatJSON, FormatYAML)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w ... %w", ErrParsingRateTable, err)
+	}
+	if err := table.compile(); err != nil {
+		return nil, fmt.Errorf("%w ... %w", ErrParsingRateTable, err)
+	}
+	return table, nil
+}
+
+// Loads an exchange rate table from a file, using the file extension ( .json, .yaml or .yml ) to decide its format
+func LoadTable(path string) (*Table, error) {
+	format := ""
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = FormatJSON
+	case ".yaml", ".yml":
+		format = FormatYAML
+	default:
+		return nil, fmt.Errorf("%w given \"%s\" ... %w \"%s\"", ErrParsingRateTable, path, ErrUnknownFormat, filepath.Ext(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrParsingRateTable, path, err)
+	}
+	return ParseTable(data, format)
+}
@@ -0,0 +1,100 @@
+package exchangerate
+
+import (
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+)
+
+func loadTestTable(t *testing.T) *Table {
+	table, err := LoadTable("testdata/rates.yaml")
+	if err != nil {
+		t.Fatalf("load table: unexpected error ( %v )", err)
+	}
+	return table
+}
+
+type lookupArguments struct {
+	Currency money.Currency
+	On       date.Date
+}
+
+func Test_Lookup(t *testing.T) {
+	table := loadTestTable(t)
+	var testCases []utils.CreationTestingData[lookupArguments, *Rate] = []utils.CreationTestingData[lookupArguments, *Rate]{
+		{Argument: lookupArguments{Currency: money.EUR, On: date.Date{Year: 2024, Month: 1, Day: 1}}, ExpectedResult: &Rate{From: money.EUR, To: money.USD, EffectiveDate: "2024-01-01", Rate: "1.1"}},
+		{Argument: lookupArguments{Currency: money.EUR, On: date.Date{Year: 2024, Month: 1, Day: 31}}, ExpectedResult: &Rate{From: money.EUR, To: money.USD, EffectiveDate: "2024-01-01", Rate: "1.1"}},
+		{Argument: lookupArguments{Currency: money.EUR, On: date.Date{Year: 2024, Month: 2, Day: 1}}, ExpectedResult: &Rate{From: money.EUR, To: money.USD, EffectiveDate: "2024-02-01", Rate: "1.08"}},
+		{Argument: lookupArguments{Currency: money.EUR, On: date.Date{Year: 2030, Month: 6, Day: 15}}, ExpectedResult: &Rate{From: money.EUR, To: money.USD, EffectiveDate: "2024-02-01", Rate: "1.08"}},
+		//Tests that no conversion is needed for the base currency
+		{Argument: lookupArguments{Currency: money.USD, On: date.Date{Year: 2024, Month: 1, Day: 1}}, ExpectedResult: nil},
+		{Argument: lookupArguments{Currency: "", On: date.Date{Year: 2024, Month: 1, Day: 1}}, ExpectedResult: nil},
+		//Tests dates before the first rate and currencies without any rates
+		{Argument: lookupArguments{Currency: money.EUR, On: date.Date{Year: 2023, Month: 12, Day: 31}}, ExpectedResult: nil, ExpectedErr: ErrRateNotFound},
+		{Argument: lookupArguments{Currency: money.GBP, On: date.Date{Year: 2024, Month: 1, Day: 1}}, ExpectedResult: nil, ExpectedErr: ErrRateNotFound},
+	}
+	for _, testCase := range testCases {
+		result, err := table.Lookup(testCase.Argument.Currency, testCase.Argument.On)
+		errCheck := testCase.CheckTestCase("look up exchange rate", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+type convertArguments struct {
+	Rate   Rate
+	Amount money.Money
+}
+
+func Test_Convert(t *testing.T) {
+	var testCases []utils.CreationTestingData[convertArguments, money.Money] = []utils.CreationTestingData[convertArguments, money.Money]{
+		{Argument: convertArguments{Rate: Rate{From: money.EUR, To: money.USD, Rate: "1.1"}, Amount: 1000}, ExpectedResult: 1100},
+		{Argument: convertArguments{Rate: Rate{From: money.EUR, To: money.USD, Rate: "1.08"}, Amount: 1225}, ExpectedResult: 1323},
+		{Argument: convertArguments{Rate: Rate{From: money.CAD, To: money.USD, Rate: "0.75"}, Amount: 1}, ExpectedResult: 1},         //Tests rounding to the nearest cent, 0.75 cents -> 1 cent
+		{Argument: convertArguments{Rate: Rate{From: money.CAD, To: money.USD, Rate: "0.5"}, Amount: 5}, ExpectedResult: 2},          //Tests that halves are rounded to even, 2.5 cents -> 2 cents
+		{Argument: convertArguments{Rate: Rate{From: money.JPY, To: money.USD, Rate: "0.0068"}, Amount: 1500}, ExpectedResult: 1020}, //Tests converting from a currency with no minor unit, 1500 yen -> 10.20 dollars
+		{Argument: convertArguments{Rate: Rate{From: money.KWD, To: money.USD, Rate: "3.25"}, Amount: 1000}, ExpectedResult: 325},    //Tests converting from a currency with 3 decimal places, 1.000 dinar -> 3.25 dollars
+		{Argument: convertArguments{Rate: Rate{From: money.EUR, To: money.USD, Rate: "1.1"}, Amount: -1000}, ExpectedResult: -1100},
+		{Argument: convertArguments{Rate: Rate{From: money.EUR, To: money.USD, Rate: "abc"}, Amount: 1000}, ExpectedResult: 0, ExpectedErr: ErrInvalidRate},
+	}
+	for _, testCase := range testCases {
+		result, err := testCase.Argument.Rate.Convert(testCase.Argument.Amount)
+		errCheck := testCase.CheckTestCase("convert with exchange rate", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_ParseTable(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, int] = []utils.CreationTestingData[string, int]{
+		{Argument: `{"base": "USD", "rates": []}`, ExpectedResult: 0},
+		{Argument: `{"base": "usd", "rates": [{"currency": "EUR", "date": "2024-01-01", "rate": 1.1}, {"currency": "CAD", "date": "2024-01-01", "rate": 0.75}]}`, ExpectedResult: 2},
+		{Argument: `{"rates": []}`, ExpectedErr: ErrMissingBaseCurrency},
+		{Argument: `{"base": "USD", "rates": [{"currency": "XYZ", "date": "2024-01-01", "rate": 1.1}]}`, ExpectedErr: ErrInvalidRate},
+		{Argument: `{"base": "USD", "rates": [{"currency": "EUR", "date": "2024-13-01", "rate": 1.1}]}`, ExpectedErr: ErrInvalidRate},
+		{Argument: `{"base": "USD", "rates": [{"currency": "EUR", "date": "2024-01-01", "rate": 0}]}`, ExpectedErr: ErrInvalidRate},
+		{Argument: `{"base": "USD", "rates": [{"currency": "USD", "date": "2024-01-01", "rate": 1}]}`, ExpectedErr: ErrInvalidRate},
+		{Argument: `{"base": "USD", "rates": [{"currency": "EUR", "date": "2024-01-01", "rate": 1.1}, {"currency": "EUR", "date": "2024-01-01", "rate": 1.2}]}`, ExpectedErr: ErrDuplicateRate},
+		{Argument: `{"base": "USD", "rates": [{"currency": "EUR", "date": "2024-01-01", "rtae": 1.1}]}`, ExpectedErr: ErrParsingRateTable},
+	}
+	for _, testCase := range testCases {
+		table, err := ParseTable([]byte(testCase.Argument), FormatJSON)
+		result := 0
+		if table != nil {
+			for _, rates := range table.compiled {
+				result += len(rates)
+			}
+		}
+		errCheck := testCase.CheckTestCase("parse exchange rate table", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	if _, err := LoadTable("testdata/rates.txt"); err == nil {
+		t.Fatalf("load table: expected an error for an unknown file extension")
+	}
+}
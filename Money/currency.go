@@ -0,0 +1,87 @@
+package money
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An ISO 4217 currency code, i.e. "USD". The zero value is treated as the default currency, so amounts recorded before currencies were supported are read as dollars.
+type Currency string
+
+const (
+	USD Currency = "USD"
+	CAD Currency = "CAD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	CHF Currency = "CHF"
+	SEK Currency = "SEK"
+	NOK Currency = "NOK"
+	DKK Currency = "DKK"
+	PLN Currency = "PLN"
+	CZK Currency = "CZK"
+	HUF Currency = "HUF"
+	MXN Currency = "MXN"
+	AUD Currency = "AUD"
+	NZD Currency = "NZD"
+	JPY Currency = "JPY"
+	KRW Currency = "KRW"
+	ISK Currency = "ISK"
+	BHD Currency = "BHD"
+	KWD Currency = "KWD"
+	JOD Currency = "JOD"
+
+	DefaultCurrency = USD
+)
+
+// The number of digits after the decimal point for each supported currency, as given by ISO 4217
+var minorUnits = map[Currency]int{
+	USD: 2, CAD: 2, EUR: 2, GBP: 2, CHF: 2, SEK: 2, NOK: 2, DKK: 2, PLN: 2, CZK: 2, HUF: 2, MXN: 2, AUD: 2, NZD: 2,
+	JPY: 0, KRW: 0, ISK: 0,
+	BHD: 3, KWD: 3, JOD: 3,
+}
+
+// Parses a currency code case insensitively, i.e. "cad" gives CAD
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(strings.Trim(code, " ")))
+	if _, isSupported := minorUnits[currency]; !isSupported {
+		return "", fmt.Errorf("%w given \"%s\" ( supported currencies are %s )", ErrUnknownCurrency, code, strings.Join(SupportedCurrencies(), ", "))
+	}
+	return currency, nil
+}
+
+// Returns the codes of every supported currency in alphabetical order
+func SupportedCurrencies() []string {
+	codes := make([]string, 0, len(minorUnits))
+	for currency := range minorUnits {
+		codes = append(codes, string(currency))
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Returns the default currency in place of the zero value
+func (c Currency) OrDefault() Currency {
+	if c == "" {
+		return DefaultCurrency
+	}
+	return c
+}
+
+func (c Currency) Code() string {
+	return string(c.OrDefault())
+}
+
+// The number of digits after the decimal point, i.e. 2 for USD and 0 for JPY
+func (c Currency) MinorUnits() int {
+	return minorUnits[c.OrDefault()]
+}
+
+// The amount of one whole unit of the currency, i.e. 100 cents for USD or 1 yen for JPY
+func (c Currency) MajorUnit() Money {
+	unit := Money(1)
+	for i := 0; i < c.MinorUnits(); i++ {
+		unit *= 10
+	}
+	return unit
+}
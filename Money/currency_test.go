@@ -0,0 +1,41 @@
+package money
+
+import (
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+)
+
+func Test_ParseCurrency(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, Currency] = []utils.CreationTestingData[string, Currency]{
+		{Argument: "USD", ExpectedResult: USD, ExpectedErr: nil},
+		{Argument: "cad", ExpectedResult: CAD, ExpectedErr: nil},
+		{Argument: " Eur ", ExpectedResult: EUR, ExpectedErr: nil},
+		{Argument: "JPY", ExpectedResult: JPY, ExpectedErr: nil},
+		{Argument: "", ExpectedResult: "", ExpectedErr: ErrUnknownCurrency},
+		{Argument: "XYZ", ExpectedResult: "", ExpectedErr: ErrUnknownCurrency},
+		{Argument: "dollars", ExpectedResult: "", ExpectedErr: ErrUnknownCurrency},
+	}
+	for _, testCase := range testCases {
+		result, err := ParseCurrency(testCase.Argument)
+		errCheck := testCase.CheckTestCase("parse currency", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_CurrencyMinorUnits(t *testing.T) {
+	var testCases []utils.CreationTestingData[Currency, Money] = []utils.CreationTestingData[Currency, Money]{
+		{Argument: USD, ExpectedResult: 100},
+		{Argument: EUR, ExpectedResult: 100},
+		{Argument: JPY, ExpectedResult: 1},
+		{Argument: KWD, ExpectedResult: 1000},
+		{Argument: "", ExpectedResult: 100}, //Tests that no currency is treated as the default currency
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("currency major unit", testCase.Argument.MajorUnit(), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrEmptyMoneyString     = errors.New("attempting to parse money variable from empty string")
+	ErrInvalidMoneySyntax   = errors.New("invalid money syntax")
+	ErrTooManyDecimalPlaces = errors.New("too many decimal places")
+	ErrMoneyOverflow        = errors.New("money value out of range")
+	ErrInvalidDecimalSyntax = errors.New("invalid decimal syntax")
+	ErrUnknownCurrency      = errors.New("unknown currency")
+)
+
+// An exact amount of money, stored as a whole number of the currency's minor unit ( i.e. cents ) so that sums and comparisons never pick up floating point error.
+// The currency is kept alongside the amount, i.e. on the receipt, and amounts are treated as the default currency when none is given.
+type Money int64
+
+const (
+	Cent   Money = 1
+	Dollar Money = 100
+)
+
+// How a value that falls between two representable values is rounded
+type RoundingMode int
+
+const (
+	RoundUp       RoundingMode = iota // towards positive infinity, i.e. 2.1 -> 3 and -2.1 -> -2
+	RoundDown                         // towards negative infinity, i.e. 2.9 -> 2 and -2.9 -> -3
+	RoundHalfUp                       // to the nearest value, with halves rounded away from zero
+	RoundHalfEven                     // to the nearest value, with halves rounded to the even neighbour
+)
+
+func FromCents(cents int64) Money {
+	return Money(cents)
+}
+
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Formats the amount in the default currency, with exactly two decimal places, i.e. "6.49", "-0.05" or "12.00"
+func (m Money) String() string {
+	return m.Format(DefaultCurrency)
+}
+
+// Formats the amount with exactly as many decimal places as the currency has, i.e. "6.49" for USD, "649" for JPY or "6.490" for KWD
+func (m Money) Format(currency Currency) string {
+	sign := ""
+	magnitude := uint64(m)
+	if m < 0 {
+		sign = "-"
+		magnitude = uint64(-m)
+	}
+	places := currency.MinorUnits()
+	if places == 0 {
+		return fmt.Sprintf("%s%d", sign, magnitude)
+	}
+	unit := uint64(currency.MajorUnit())
+	return fmt.Sprintf("%s%d.%0*d", sign, magnitude/unit, places, magnitude%unit)
+}
+
+// Checks that every digit group after the first has exactly 3 digits, i.e. "1", "234" and "567" from "1.234.567"
+func isValidDigitGrouping(groups []string) bool {
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// Rewrites amounts written with a locale's separators ( i.e. "12,50", "1.234,50" or "1,234.50" ) to use a decimal point and no digit grouping ( i.e. "12.50" or "1234.50" ).
+// When both separators are used, whichever comes last is the decimal separator. A single comma on its own is a decimal comma.
+func normalizeSeparators(str string) (string, bool) {
+	lastComma := strings.LastIndex(str, ",")
+	lastPoint := strings.LastIndex(str, ".")
+	switch {
+	case lastComma == -1:
+		return str, true
+	case lastPoint == -1 && strings.Count(str, ",") == 1:
+		return strings.Replace(str, ",", ".", 1), true
+	}
+
+	groupSeparator, decimalSeparator := ",", "."
+	if lastComma > lastPoint {
+		groupSeparator, decimalSeparator = ".", ","
+	}
+	whole, fraction, hasFraction := strings.Cut(str, decimalSeparator)
+	if strings.Contains(fraction, decimalSeparator) || strings.Contains(fraction, groupSeparator) {
+		return str, false
+	}
+	whole = strings.TrimLeft(whole, "+-")
+	if !isValidDigitGrouping(strings.Split(whole, groupSeparator)) {
+		return str, false
+	}
+	normalized := strings.ReplaceAll(str[:len(str)-len(fraction)], groupSeparator, "")
+	if hasFraction {
+		normalized = strings.Replace(normalized, decimalSeparator, ".", 1) + fraction
+	}
+	return normalized, true
+}
+
+// Splits a signed decimal string ( i.e. "-12.50" ) into its sign, whole digits and fractional digits
+func splitDecimal(str string) (bool, string, string, bool) {
+	negative := false
+	switch {
+	case strings.HasPrefix(str, "-"):
+		negative = true
+		str = str[1:]
+	case strings.HasPrefix(str, "+"):
+		str = str[1:]
+	}
+	whole, fraction, _ := strings.Cut(str, ".")
+	if whole == "" && fraction == "" {
+		return negative, whole, fraction, false
+	}
+	for _, digits := range []string{whole, fraction} {
+		for _, r := range digits {
+			if r < '0' || r > '9' {
+				return negative, whole, fraction, false
+			}
+		}
+	}
+	return negative, whole, fraction, true
+}
+
+// Parses a decimal amount in the default currency, such as "6.49", "-2.79", "12" or "12.5"
+func Parse(moneyString string) (Money, error) {
+	return ParseIn(moneyString, DefaultCurrency)
+}
+
+// Parses a decimal amount in the given currency, such as "6.49", "12,50" or "1.234,50". Amounts with more decimal places than the currency has are rejected unless the extra places are all zero.
+func ParseIn(moneyString string, currency Currency) (Money, error) {
+	if strings.Trim(moneyString, " ") == "" {
+		return 0, ErrEmptyMoneyString
+	}
+	normalizedString, isValidSyntax := normalizeSeparators(moneyString)
+	negative, whole, fraction, isValidDecimal := splitDecimal(normalizedString)
+	if !isValidSyntax || !isValidDecimal {
+		return 0, fmt.Errorf("%w given \"%s\" ( valid format is an optional sign followed by digits with an optional decimal point or comma, i.e. -12.34 or -12,34 )", ErrInvalidMoneySyntax, moneyString)
+	}
+	decimalPlaces := currency.MinorUnits()
+	if len(fraction) > decimalPlaces {
+		if strings.Trim(fraction[decimalPlaces:], "0") != "" {
+			return 0, fmt.Errorf("%w given \"%s\" ( amounts in %s can have at most %d decimal places )", ErrTooManyDecimalPlaces, moneyString, currency.Code(), decimalPlaces)
+		}
+		fraction = fraction[:decimalPlaces]
+	}
+	fraction += strings.Repeat("0", decimalPlaces-len(fraction))
+	if whole == "" {
+		whole = "0"
+	}
+
+	cents, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w given \"%s\" ... %w", ErrMoneyOverflow, moneyString, err)
+	}
+	if negative {
+		cents = -cents
+	}
+	return Money(cents), nil
+}
+
+// Parses a decimal string ( i.e. "0.2" ) into an exact fraction, returned as its numerator and denominator
+func ParseDecimal(decimalString string) (int64, int64, error) {
+	negative, whole, fraction, isValidSyntax := splitDecimal(strings.Trim(decimalString, " "))
+	if !isValidSyntax {
+		return 0, 1, fmt.Errorf("%w given \"%s\"", ErrInvalidDecimalSyntax, decimalString)
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	numerator, err := strconv.ParseInt("0"+whole+fraction, 10, 64)
+	if err != nil || len(fraction) > 18 {
+		return 0, 1, fmt.Errorf("%w given \"%s\" ... value out of range", ErrInvalidDecimalSyntax, decimalString)
+	}
+	if negative {
+		numerator = -numerator
+	}
+	return numerator, int64(math.Pow10(len(fraction))), nil
+}
+
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+func (m Money) Subtract(other Money) Money {
+	return m - other
+}
+
+func Sum(amounts ...Money) Money {
+	var total Money = 0
+	for _, amount := range amounts {
+		total += amount
+	}
+	return total
+}
+
+// Divides numerator by denominator, rounding the quotient with the given mode. The denominator must be positive.
+func divideAndRound(numerator *big.Int, denominator *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).DivMod(numerator, denominator, new(big.Int)) // euclidean, so the remainder is never negative and the quotient is the floor
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+	roundUp := false
+	switch mode {
+	case RoundUp:
+		roundUp = true
+	case RoundDown:
+		roundUp = false
+	case RoundHalfUp, RoundHalfEven:
+		comparison := new(big.Int).Mul(remainder, big.NewInt(2)).Cmp(denominator)
+		switch {
+		case comparison > 0:
+			roundUp = true
+		case comparison < 0:
+			roundUp = false
+		case mode == RoundHalfUp:
+			roundUp = numerator.Sign() > 0
+		default:
+			roundUp = quotient.Bit(0) == 1
+		}
+	}
+	if roundUp {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return quotient
+}
+
+// Multiplies the amount by numerator / denominator exactly, then rounds the result to a whole cent with the given mode
+func (m Money) Multiply(numerator int64, denominator int64, mode RoundingMode) Money {
+	if denominator < 0 {
+		numerator, denominator = -numerator, -denominator
+	}
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(numerator))
+	return Money(divideAndRound(product, big.NewInt(denominator), mode).Int64())
+}
+
+// Rounds the amount to a multiple of the unit with the given mode, i.e. rounding 12.25 to the nearest Dollar gives 12.00
+func (m Money) Round(unit Money, mode RoundingMode) Money {
+	if unit <= 0 {
+		return m
+	}
+	units := divideAndRound(big.NewInt(int64(m)), big.NewInt(int64(unit)), mode)
+	return Money(units.Int64()) * unit
+}
+
+// Returns the amount as a whole number of dollars, rounded with the given mode
+func (m Money) WholeUnits(mode RoundingMode) int64 {
+	return m.WholeUnitsIn(DefaultCurrency, mode)
+}
+
+// Returns the amount as a whole number of the currency's major unit ( i.e. dollars or euros ), rounded with the given mode
+func (m Money) WholeUnitsIn(currency Currency, mode RoundingMode) int64 {
+	unit := currency.MajorUnit()
+	return int64(m.Round(unit, mode) / unit)
+}
+
+// Checks if the amount is an exact multiple of the unit, i.e. 9.00 is a multiple of 0.25 but 9.10 is not
+func (m Money) IsMultipleOf(unit Money) bool {
+	if unit == 0 {
+		return m == 0
+	}
+	return m%unit == 0
+}
+
+// Checks if the amount is an exact multiple of a decimal number of the currency's major unit, given as numerator / denominator, i.e. 9.00 USD is a multiple of 1 / 4 dollars but 9.10 USD is not
+func (m Money) IsMultipleOfDecimal(numerator int64, denominator int64, currency Currency) bool {
+	if numerator == 0 {
+		return m == 0
+	}
+	scaledAmount := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(denominator))
+	scaledMultiple := new(big.Int).Mul(big.NewInt(int64(currency.MajorUnit())), big.NewInt(numerator))
+	return new(big.Int).Rem(scaledAmount, scaledMultiple).Sign() == 0
+}
+
+// Encodes the amount as a json number with two decimal places, i.e. 6.49
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Decodes the amount from either a json number or a json string, i.e. 6.49 or "6.49"
+func (m *Money) UnmarshalJSON(data []byte) error {
+	str := string(data)
+	if str == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(str); err == nil {
+		str = unquoted
+	}
+	parsed, err := Parse(str)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,258 @@
+package money
+
+import (
+	"encoding/json"
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+)
+
+func Test_Parse(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, Money] = []utils.CreationTestingData[string, Money]{
+		{Argument: "6.49", ExpectedResult: 649, ExpectedErr: nil},
+		{Argument: "12.00", ExpectedResult: 1200, ExpectedErr: nil},
+		{Argument: "12", ExpectedResult: 1200, ExpectedErr: nil},
+		{Argument: "12.5", ExpectedResult: 1250, ExpectedErr: nil},
+		{Argument: ".5", ExpectedResult: 50, ExpectedErr: nil},
+		{Argument: "0.05", ExpectedResult: 5, ExpectedErr: nil},
+		{Argument: "-2.79", ExpectedResult: -279, ExpectedErr: nil},
+		{Argument: "+2.79", ExpectedResult: 279, ExpectedErr: nil},
+		{Argument: "2.7900", ExpectedResult: 279, ExpectedErr: nil}, //Tests that extra decimal places are allowed as long as they are zero
+		//Tests amounts that can't be represented exactly in cents
+		{Argument: "2.795", ExpectedResult: 0, ExpectedErr: ErrTooManyDecimalPlaces},
+		{Argument: "0.001", ExpectedResult: 0, ExpectedErr: ErrTooManyDecimalPlaces},
+		//Tests inputs that have an invalid syntax will correctly throw an error
+		{Argument: "", ExpectedResult: 0, ExpectedErr: ErrEmptyMoneyString},
+		{Argument: "   ", ExpectedResult: 0, ExpectedErr: ErrEmptyMoneyString},
+		{Argument: "abc", ExpectedResult: 0, ExpectedErr: ErrInvalidMoneySyntax},
+		{Argument: "1e3", ExpectedResult: 0, ExpectedErr: ErrInvalidMoneySyntax},
+		{Argument: "$5.00", ExpectedResult: 0, ExpectedErr: ErrInvalidMoneySyntax},
+		{Argument: "5.00.00", ExpectedResult: 0, ExpectedErr: ErrInvalidMoneySyntax},
+		{Argument: ".", ExpectedResult: 0, ExpectedErr: ErrInvalidMoneySyntax},
+		{Argument: "-", ExpectedResult: 0, ExpectedErr: ErrInvalidMoneySyntax},
+		{Argument: "99999999999999999999", ExpectedResult: 0, ExpectedErr: ErrMoneyOverflow},
+		//Tests amounts written with a decimal comma or digit grouping
+		{Argument: "12,50", ExpectedResult: 1250, ExpectedErr: nil},
+		{Argument: "-2,79", ExpectedResult: -279, ExpectedErr: nil},
+		{Argument: "1.234,50", ExpectedResult: 123450, ExpectedErr: nil},
+		{Argument: "1,234.50", ExpectedResult: 123450, ExpectedErr: nil},
+		{Argument: "1.234.567,89", ExpectedResult: 123456789, ExpectedErr: nil},
+		{Argument: "1,234", ExpectedResult: 0, ExpectedErr: ErrTooManyDecimalPlaces}, //Tests that a lone comma is always read as a decimal comma
+		{Argument: "1.23,50", ExpectedResult: 0, ExpectedErr: ErrInvalidMoneySyntax},
+		{Argument: "1,234,50", ExpectedResult: 0, ExpectedErr: ErrInvalidMoneySyntax},
+		{Argument: "12,50.00", ExpectedResult: 0, ExpectedErr: ErrInvalidMoneySyntax},
+	}
+	for _, testCase := range testCases {
+		result, err := Parse(testCase.Argument)
+		errCheck := testCase.CheckTestCase("parse money", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+type parseInArguments struct {
+	MoneyString string
+	Currency    Currency
+}
+
+func Test_ParseIn(t *testing.T) {
+	var testCases []utils.CreationTestingData[parseInArguments, Money] = []utils.CreationTestingData[parseInArguments, Money]{
+		{Argument: parseInArguments{MoneyString: "12,50", Currency: EUR}, ExpectedResult: 1250, ExpectedErr: nil},
+		{Argument: parseInArguments{MoneyString: "1500", Currency: JPY}, ExpectedResult: 1500, ExpectedErr: nil},
+		{Argument: parseInArguments{MoneyString: "1500.00", Currency: JPY}, ExpectedResult: 1500, ExpectedErr: nil},
+		{Argument: parseInArguments{MoneyString: "1500.5", Currency: JPY}, ExpectedResult: 0, ExpectedErr: ErrTooManyDecimalPlaces},
+		{Argument: parseInArguments{MoneyString: "1,234", Currency: KWD}, ExpectedResult: 1234, ExpectedErr: nil},
+		{Argument: parseInArguments{MoneyString: "1.5", Currency: KWD}, ExpectedResult: 1500, ExpectedErr: nil},
+		{Argument: parseInArguments{MoneyString: "1.2345", Currency: KWD}, ExpectedResult: 0, ExpectedErr: ErrTooManyDecimalPlaces},
+		{Argument: parseInArguments{MoneyString: "6.49", Currency: ""}, ExpectedResult: 649, ExpectedErr: nil}, //Tests that no currency is treated as the default currency
+	}
+	for _, testCase := range testCases {
+		result, err := ParseIn(testCase.Argument.MoneyString, testCase.Argument.Currency)
+		errCheck := testCase.CheckTestCase("parse money in currency", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+type formatArguments struct {
+	Amount   Money
+	Currency Currency
+}
+
+func Test_Format(t *testing.T) {
+	var testCases []utils.CreationTestingData[formatArguments, string] = []utils.CreationTestingData[formatArguments, string]{
+		{Argument: formatArguments{Amount: 1250, Currency: EUR}, ExpectedResult: "12.50"},
+		{Argument: formatArguments{Amount: 1500, Currency: JPY}, ExpectedResult: "1500"},
+		{Argument: formatArguments{Amount: -1500, Currency: JPY}, ExpectedResult: "-1500"},
+		{Argument: formatArguments{Amount: 1234, Currency: KWD}, ExpectedResult: "1.234"},
+		{Argument: formatArguments{Amount: 5, Currency: KWD}, ExpectedResult: "0.005"},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("format money in currency", testCase.Argument.Amount.Format(testCase.Argument.Currency), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_String(t *testing.T) {
+	var testCases []utils.CreationTestingData[Money, string] = []utils.CreationTestingData[Money, string]{
+		{Argument: 649, ExpectedResult: "6.49"},
+		{Argument: 1200, ExpectedResult: "12.00"},
+		{Argument: 5, ExpectedResult: "0.05"},
+		{Argument: 0, ExpectedResult: "0.00"},
+		{Argument: -279, ExpectedResult: "-2.79"},
+		{Argument: -5, ExpectedResult: "-0.05"},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("format money", testCase.Argument.String(), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+// Sums that pick up error as float64 ( i.e. 0.1 + 0.2 ) should be exact
+func Test_Sum(t *testing.T) {
+	tenCents, _ := Parse("0.10")
+	twentyCents, _ := Parse("0.20")
+	thirtyCents, _ := Parse("0.30")
+	if Sum(tenCents, twentyCents) != thirtyCents {
+		t.Fatalf("sum money: expected ( %s ) got ( %s )", thirtyCents, Sum(tenCents, twentyCents))
+	}
+}
+
+type multiplyArguments struct {
+	Amount      Money
+	Numerator   int64
+	Denominator int64
+	Mode        RoundingMode
+}
+
+func Test_Multiply(t *testing.T) {
+	var testCases []utils.CreationTestingData[multiplyArguments, Money] = []utils.CreationTestingData[multiplyArguments, Money]{
+		{Argument: multiplyArguments{Amount: 1225, Numerator: 2, Denominator: 10, Mode: RoundUp}, ExpectedResult: 245},
+		{Argument: multiplyArguments{Amount: 1, Numerator: 1, Denominator: 2, Mode: RoundUp}, ExpectedResult: 1},
+		{Argument: multiplyArguments{Amount: 1, Numerator: 1, Denominator: 2, Mode: RoundDown}, ExpectedResult: 0},
+		{Argument: multiplyArguments{Amount: -1, Numerator: 1, Denominator: 2, Mode: RoundUp}, ExpectedResult: 0},
+		{Argument: multiplyArguments{Amount: -1, Numerator: 1, Denominator: 2, Mode: RoundDown}, ExpectedResult: -1},
+		{Argument: multiplyArguments{Amount: 1, Numerator: 1, Denominator: 2, Mode: RoundHalfUp}, ExpectedResult: 1},
+		{Argument: multiplyArguments{Amount: -1, Numerator: 1, Denominator: 2, Mode: RoundHalfUp}, ExpectedResult: -1},
+		{Argument: multiplyArguments{Amount: 1, Numerator: 1, Denominator: 2, Mode: RoundHalfEven}, ExpectedResult: 0},
+		{Argument: multiplyArguments{Amount: 3, Numerator: 1, Denominator: 2, Mode: RoundHalfEven}, ExpectedResult: 2},
+		{Argument: multiplyArguments{Amount: 1000, Numerator: 1, Denominator: 3, Mode: RoundHalfEven}, ExpectedResult: 333},
+		{Argument: multiplyArguments{Amount: 1000, Numerator: 2, Denominator: 3, Mode: RoundHalfUp}, ExpectedResult: 667},
+		{Argument: multiplyArguments{Amount: 1000, Numerator: -1, Denominator: -4, Mode: RoundUp}, ExpectedResult: 250},
+	}
+	for _, testCase := range testCases {
+		result := testCase.Argument.Amount.Multiply(testCase.Argument.Numerator, testCase.Argument.Denominator, testCase.Argument.Mode)
+		errCheck := testCase.CheckTestCase("multiply money", result, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_WholeUnits(t *testing.T) {
+	var testCases []utils.CreationTestingData[Money, int64] = []utils.CreationTestingData[Money, int64]{
+		{Argument: 245, ExpectedResult: 3},
+		{Argument: 300, ExpectedResult: 3},
+		{Argument: 301, ExpectedResult: 4},
+		{Argument: 0, ExpectedResult: 0},
+		{Argument: -240, ExpectedResult: -2},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("whole units of money", testCase.Argument.WholeUnits(RoundUp), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+	if yen := Money(1500).WholeUnitsIn(JPY, RoundUp); yen != 1500 {
+		t.Fatalf("whole units of money in JPY: expected ( 1500 ) got ( %d )", yen)
+	}
+	if dinars := Money(1001).WholeUnitsIn(KWD, RoundDown); dinars != 1 {
+		t.Fatalf("whole units of money in KWD: expected ( 1 ) got ( %d )", dinars)
+	}
+}
+
+func Test_IsMultipleOf(t *testing.T) {
+	var testCases []utils.CreationTestingData[Money, bool] = []utils.CreationTestingData[Money, bool]{
+		{Argument: 900, ExpectedResult: true},
+		{Argument: 925, ExpectedResult: true},
+		{Argument: 910, ExpectedResult: false},
+		{Argument: -925, ExpectedResult: true},
+		{Argument: 0, ExpectedResult: true},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("money is multiple of 0.25", testCase.Argument.IsMultipleOf(25*Cent), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+type isMultipleOfDecimalArguments struct {
+	Amount      Money
+	Numerator   int64
+	Denominator int64
+	Currency    Currency
+}
+
+func Test_IsMultipleOfDecimal(t *testing.T) {
+	var testCases []utils.CreationTestingData[isMultipleOfDecimalArguments, bool] = []utils.CreationTestingData[isMultipleOfDecimalArguments, bool]{
+		{Argument: isMultipleOfDecimalArguments{Amount: 900, Numerator: 1, Denominator: 4, Currency: USD}, ExpectedResult: true},
+		{Argument: isMultipleOfDecimalArguments{Amount: 910, Numerator: 1, Denominator: 4, Currency: USD}, ExpectedResult: false},
+		{Argument: isMultipleOfDecimalArguments{Amount: 900, Numerator: 1, Denominator: 1, Currency: EUR}, ExpectedResult: true},
+		{Argument: isMultipleOfDecimalArguments{Amount: 925, Numerator: 1, Denominator: 1, Currency: EUR}, ExpectedResult: false},
+		{Argument: isMultipleOfDecimalArguments{Amount: 925, Numerator: 1, Denominator: 1, Currency: JPY}, ExpectedResult: true},
+		{Argument: isMultipleOfDecimalArguments{Amount: 925, Numerator: 1, Denominator: 4, Currency: JPY}, ExpectedResult: true},
+		{Argument: isMultipleOfDecimalArguments{Amount: 1500, Numerator: 100, Denominator: 1, Currency: JPY}, ExpectedResult: true},
+		{Argument: isMultipleOfDecimalArguments{Amount: 1550, Numerator: 100, Denominator: 1, Currency: JPY}, ExpectedResult: false},
+		{Argument: isMultipleOfDecimalArguments{Amount: 2000, Numerator: 1, Denominator: 1, Currency: KWD}, ExpectedResult: true},
+		{Argument: isMultipleOfDecimalArguments{Amount: 2500, Numerator: 1, Denominator: 1, Currency: KWD}, ExpectedResult: false},
+	}
+	for _, testCase := range testCases {
+		result := testCase.Argument.Amount.IsMultipleOfDecimal(testCase.Argument.Numerator, testCase.Argument.Denominator, testCase.Argument.Currency)
+		errCheck := testCase.CheckTestCase("money is multiple of decimal", result, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_ParseDecimal(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, [2]int64] = []utils.CreationTestingData[string, [2]int64]{
+		{Argument: "0.2", ExpectedResult: [2]int64{2, 10}},
+		{Argument: "0.20", ExpectedResult: [2]int64{2, 10}},
+		{Argument: "1.5", ExpectedResult: [2]int64{15, 10}},
+		{Argument: "3", ExpectedResult: [2]int64{3, 1}},
+		{Argument: "-0.125", ExpectedResult: [2]int64{-125, 1000}},
+		{Argument: "1e-3", ExpectedResult: [2]int64{0, 1}, ExpectedErr: ErrInvalidDecimalSyntax},
+	}
+	for _, testCase := range testCases {
+		numerator, denominator, err := ParseDecimal(testCase.Argument)
+		errCheck := testCase.CheckTestCase("parse decimal", [2]int64{numerator, denominator}, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+// Amounts are written as json numbers, but older data with amounts written as strings should still be read
+func Test_JSON(t *testing.T) {
+	encoded, err := json.Marshal(struct{ Total Money }{Total: 3535})
+	if err != nil || string(encoded) != `{"Total":35.35}` {
+		t.Fatalf("marshal money: expected ( {\"Total\":35.35} ) got ( %s ) with error ( %v )", encoded, err)
+	}
+	for _, data := range []string{`{"Total":35.35}`, `{"Total":"35.35"}`} {
+		var decoded struct{ Total Money }
+		if err := json.Unmarshal([]byte(data), &decoded); err != nil || decoded.Total != 3535 {
+			t.Fatalf("unmarshal money ( %s ): expected ( 35.35 ) got ( %s ) with error ( %v )", data, decoded.Total, err)
+		}
+	}
+	var decoded struct{ Total Money }
+	if err := json.Unmarshal([]byte(`{"Total":35.355}`), &decoded); err == nil {
+		t.Fatalf("unmarshal money: expected an error for an amount with too many decimal places")
+	}
+}
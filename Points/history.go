@@ -0,0 +1,135 @@
+package points
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrDuplicateVersion = errors.New("duplicate ruleset version")
+	ErrVersionNotFound  = errors.New("no ruleset found for version")
+	ErrEmptyHistory     = errors.New("ruleset history has no rulesets")
+)
+
+// Receipts scored before ruleset versions were recorded were all scored with the original rules, which are version 1
+const OriginalRulesetVersion = 1
+
+// Every version of the rules that receipts may have been scored with. The highest version is the current one, which new receipts are scored with.
+type RulesetHistory struct {
+	versions map[int]*Ruleset
+	current  *Ruleset
+}
+
+func NewRulesetHistory(rulesets ...*Ruleset) (*RulesetHistory, error) {
+	if len(rulesets) == 0 {
+		return nil, ErrEmptyHistory
+	}
+	history := &RulesetHistory{versions: make(map[int]*Ruleset)}
+	for _, ruleset := range rulesets {
+		if _, containsKey := history.versions[ruleset.Version]; containsKey {
+			return nil, fmt.Errorf("%w %d given by ruleset \"%s\"", ErrDuplicateVersion, ruleset.Version, ruleset.Name)
+		}
+		history.versions[ruleset.Version] = ruleset
+		if history.current == nil || ruleset.Version > history.current.Version {
+			history.current = ruleset
+		}
+	}
+	return history, nil
+}
+
+// The ruleset new receipts are scored with
+func (h *RulesetHistory) Current() *Ruleset {
+	return h.current
+}
+
+// Returns the ruleset with the given version, or ErrVersionNotFound if there is none
+func (h *RulesetHistory) Version(version int) (*Ruleset, error) {
+	ruleset, containsKey := h.versions[version]
+	if !containsKey {
+		return nil, fmt.Errorf("%w %d ( available versions are %v )", ErrVersionNotFound, version, h.Versions())
+	}
+	return ruleset, nil
+}
+
+// Returns every available version, in ascending order
+func (h *RulesetHistory) Versions() []int {
+	versions := make([]int, 0, len(h.versions))
+	for version := range h.versions {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Loads a ruleset history from either a single ruleset file or a directory containing one file per version
+func LoadRulesetHistory(path string) (*RulesetHistory, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrParsingRuleset, path, err)
+	}
+	if !info.IsDir() {
+		ruleset, err := LoadRuleset(path)
+		if err != nil {
+			return nil, err
+		}
+		return NewRulesetHistory(ruleset)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrParsingRuleset, path, err)
+	}
+	rulesets := []*Ruleset{}
+	for _, entry := range entries {
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (extension != ".json" && extension != ".yaml" && extension != ".yml") {
+			continue
+		}
+		ruleset, err := LoadRuleset(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		rulesets = append(rulesets, ruleset)
+	}
+	return NewRulesetHistory(rulesets...)
+}
+
+var builtInRulesets *RulesetHistory = mustParseBuiltInRulesets()
+
+func mustParseBuiltInRulesets() *RulesetHistory {
+	entries, err := builtInRulesetFiles.ReadDir("rulesets")
+	if err != nil {
+		panic(err)
+	}
+	rulesets := []*Ruleset{}
+	for _, entry := range entries {
+		data, err := builtInRulesetFiles.ReadFile("rulesets/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		ruleset, err := ParseRuleset(data, FormatJSON)
+		if err != nil {
+			panic(err)
+		}
+		rulesets = append(rulesets, ruleset)
+	}
+	history, err := NewRulesetHistory(rulesets...)
+	if err != nil {
+		panic(err)
+	}
+	return history
+}
+
+// Every version of the rules shipped with the server
+func BuiltInRulesets() *RulesetHistory {
+	return builtInRulesets
+}
+
+// The latest built-in ruleset, receipts are scored with it unless another is configured
+func DefaultRuleset() *Ruleset {
+	return builtInRulesets.Current()
+}
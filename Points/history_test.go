@@ -0,0 +1,102 @@
+package points
+
+import (
+	receipt "go-receipt-processor/Receipt"
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	utils "go-receipt-processor/TestingUtils"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func mustParseRuleset(t *testing.T, rulesetJson string) *Ruleset {
+	ruleset, err := ParseRuleset([]byte(rulesetJson), FormatJSON)
+	if err != nil {
+		t.Fatalf("parse ruleset: unexpected error ( %v )", err)
+	}
+	return ruleset
+}
+
+func Test_RulesetHistory(t *testing.T) {
+	first := mustParseRuleset(t, `{"name": "first", "version": 1, "rules": [{"type": "oddPurchaseDay", "points": 6}]}`)
+	second := mustParseRuleset(t, `{"name": "second", "version": 2, "rules": [{"type": "oddPurchaseDay", "points": 60}]}`)
+
+	history, err := NewRulesetHistory(second, first)
+	if err != nil {
+		t.Fatalf("new ruleset history: unexpected error ( %v )", err)
+	}
+	if history.Current() != second {
+		t.Fatalf("new ruleset history: expected the highest version to be current, got ( %d )", history.Current().Version)
+	}
+	if !cmp.Equal(history.Versions(), []int{1, 2}) {
+		t.Fatalf("new ruleset history: expected versions ( [1 2] ) got ( %v )", history.Versions())
+	}
+
+	var testCases []utils.CreationTestingData[int, int64] = []utils.CreationTestingData[int, int64]{
+		{Argument: 1, ExpectedResult: 6},
+		{Argument: 2, ExpectedResult: 60},
+		{Argument: 3, ExpectedErr: ErrVersionNotFound},
+	}
+	oddDayReceipt := receipt.Receipt{Retailer: "Target", Items: []receiptitem.ReceiptItem{}}
+	oddDayReceipt.PurchaseDate.Day = 1
+	for _, testCase := range testCases {
+		var result int64 = 0
+		ruleset, err := history.Version(testCase.Argument)
+		if err == nil {
+			breakdown := ruleset.Calculate(oddDayReceipt)
+			result = breakdown.Points
+			if breakdown.RulesetVersion != testCase.Argument {
+				t.Fatalf("ruleset history: expected breakdown to record version ( %d ) got ( %d )", testCase.Argument, breakdown.RulesetVersion)
+			}
+		}
+		errCheck := testCase.CheckTestCase("ruleset history version", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	if _, err := NewRulesetHistory(first, mustParseRuleset(t, `{"name": "also first", "version": 1, "rules": []}`)); err == nil {
+		t.Fatalf("new ruleset history: expected an error for duplicate versions")
+	}
+	if _, err := NewRulesetHistory(); err == nil {
+		t.Fatalf("new ruleset history: expected an error when given no rulesets")
+	}
+}
+
+func Test_LoadRulesetHistory(t *testing.T) {
+	directory := t.TempDir()
+	os.WriteFile(filepath.Join(directory, "v1.json"), []byte(`{"name": "first", "version": 1, "rules": [{"type": "oddPurchaseDay", "points": 6}]}`), 0o644)
+	os.WriteFile(filepath.Join(directory, "v2.yaml"), []byte("name: second\nversion: 2\nrules:\n  - type: oddPurchaseDay\n    points: 60\n"), 0o644)
+	os.WriteFile(filepath.Join(directory, "README.md"), []byte("not a ruleset"), 0o644)
+
+	history, err := LoadRulesetHistory(directory)
+	if err != nil {
+		t.Fatalf("load ruleset history: unexpected error ( %v )", err)
+	}
+	if !cmp.Equal(history.Versions(), []int{1, 2}) || history.Current().Name != "second" {
+		t.Fatalf("load ruleset history: expected versions ( [1 2] ) with second current, got ( %v ) with %s current", history.Versions(), history.Current().Name)
+	}
+
+	history, err = LoadRulesetHistory(filepath.Join(directory, "v1.json"))
+	if err != nil || !cmp.Equal(history.Versions(), []int{1}) {
+		t.Fatalf("load ruleset history from file: expected versions ( [1] ) got ( %v ) with error ( %v )", history, err)
+	}
+}
+
+// Previously processed receipts were scored with the original rules, so they have to remain available as version 1
+func Test_CalculatePointsForVersion(t *testing.T) {
+	if _, err := BuiltInRulesets().Version(OriginalRulesetVersion); err != nil {
+		t.Fatalf("built in rulesets: the original rules are missing ... %v", err)
+	}
+	parsedReceipt, _ := receipt.ParseReceipt("0", receipt.UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Total: "6.49",
+		Items: []receiptitem.UnparsedReceiptItem{{ShortDescription: "Mountain Dew 12PK", Price: "6.49"}}}, true)
+	breakdown, err := CalculatePointsForVersion(parsedReceipt, OriginalRulesetVersion)
+	if err != nil || breakdown.Points != 12 {
+		t.Fatalf("calculate points for version: expected result ( 12 ) got result ( %d ) with error ( %v )", breakdown.Points, err)
+	}
+	if _, err := CalculatePointsForVersion(parsedReceipt, 0); err == nil {
+		t.Fatalf("calculate points for version: expected an error for version 0")
+	}
+}
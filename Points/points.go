@@ -1,93 +1,110 @@
 package points
 
 import (
-	date "go-receipt-processor/Date"
+	"fmt"
+	money "go-receipt-processor/Money"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
 	receipt "go-receipt-processor/Receipt"
 	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
-	time "go-receipt-processor/Time"
-	"math"
-	"regexp"
-	"strings"
 )
 
-var alphanumericRegex = regexp.MustCompile("[[:alnum:]]")
+// Names of each of the rules in the default ruleset
+const (
+	RuleAlphanumericRetailer  = "alphanumericRetailer"
+	RuleRoundDollarTotal      = "roundDollarTotal"
+	RuleMultipleOf25Cents     = "multipleOf25CentsTotal"
+	RuleItemPairs             = "itemPairs"
+	RuleItemDescriptionLength = "itemDescriptionLength"
+	RuleOddPurchaseDay        = "oddPurchaseDay"
+	RuleAfternoonPurchaseTime = "afternoonPurchaseTime"
+)
 
-func CalculatePoints(receipt receipt.Receipt) int64 {
-	var points int64 = 0
-	points += getPointsForAlphanumericalCharacters(receipt.Retailer)
-	points += getPointsForRoundDollarAmount(receipt.Total)
-	points += getPointsForMultipleOf25Cents(receipt.Total)
-	points += getPointsForNumberOfItems(receipt.Items)
-	points += getSumOfPointsForItemDescriptionAndPrice(receipt.Items)
-	points += getPointsForOddPurchaseDate(receipt.PurchaseDate)
-	points += getPointsForTimeOfDay(receipt.PurchaseTime)
-	return points
+// Points awarded to a receipt by a single rule
+type AwardedRule struct {
+	Rule        string       `json:"rule"`
+	Description string       `json:"description"`
+	Points      int64        `json:"points"`
+	ItemIndex   *int         `json:"itemIndex,omitempty"` // only set when the points were triggered by a specific item
+	Item        *AwardedItem `json:"item,omitempty"`
 }
 
-func getPointsForAlphanumericalCharacters(str string) int64 {
-	alphanumericString := alphanumericRegex.FindAllString(str, -1)
-	alphanumericCount := len(alphanumericString)
-	return int64(alphanumericCount)
+// The item that triggered a rule's points
+type AwardedItem struct {
+	ShortDescription string      `json:"shortDescription"`
+	Price            money.Money `json:"price"`
 }
 
-func isMultipleOfFloat(value float64, multiple float64) bool {
-	modValue := math.Mod(value, multiple)
-	return modValue == 0
+// Itemized explanation of how a receipt's points were calculated. Only rules that awarded a non-zero number of points are included.
+type Breakdown struct {
+	Points         int64              `json:"points"`
+	RulesetVersion int                `json:"rulesetVersion"`
+	ExchangeRate   *exchangerate.Rate `json:"exchangeRate,omitempty"` // only set when the receipt's amounts were converted into another currency before being scored
+	Rules          []AwardedRule      `json:"rules"`
 }
 
-func isMultipleOfUint(value uint, multiple uint) bool {
-	remainder := (value - uint(value/multiple)*multiple)
-	return remainder == 0
-}
-func getPointsForRoundDollarAmount(currency float64) int64 {
-	if isMultipleOfFloat(currency, 1) {
-		return 50
+func (b *Breakdown) award(rule string, description string, points int64) {
+	if points == 0 {
+		return
 	}
-	return 0
+	b.Points += points
+	b.Rules = append(b.Rules, AwardedRule{Rule: rule, Description: description, Points: points})
 }
 
-func getPointsForMultipleOf25Cents(currency float64) int64 {
-	if isMultipleOfFloat(currency, 0.25) {
-		return 25
+func (b *Breakdown) awardForItem(rule string, description string, points int64, index int, item receiptitem.ReceiptItem) {
+	if points == 0 {
+		return
 	}
-	return 0
+	b.Points += points
+	b.Rules = append(b.Rules, AwardedRule{Rule: rule, Description: description, Points: points, ItemIndex: &index, Item: &AwardedItem{ShortDescription: item.ShortDescription, Price: item.Price}})
 }
 
-func getPointsForNumberOfItems(items []receiptitem.ReceiptItem) int64 {
-	points := int64(len(items)/2) * 5
-	return points
+// Calculates the points for the receipt using the latest built-in ruleset
+func CalculatePoints(receipt receipt.Receipt) int64 {
+	return CalculatePointsBreakdown(receipt).Points
 }
 
-func getPointsForItemDescriptionAndPrice(item receiptitem.ReceiptItem) int64 {
-	trimmedDescription := strings.Trim(item.ShortDescription, " ")
-	if isMultipleOfUint(uint(len(trimmedDescription)), 3) {
-		points := int64(math.Ceil(item.Price * 0.2))
-		return points
-	}
-	return 0
+// Calculates the points for the receipt using the latest built-in ruleset, keeping track of which rule, and which item if any, each point came from
+func CalculatePointsBreakdown(receipt receipt.Receipt) Breakdown {
+	return DefaultRuleset().Calculate(receipt)
 }
 
-func getSumOfPointsForItemDescriptionAndPrice(items []receiptitem.ReceiptItem) int64 {
-	var points int64 = 0
-	for _, item := range items {
-		points += getPointsForItemDescriptionAndPrice(item)
+// Calculates the points for the receipt using a specific version of the built-in rulesets, i.e. to compare what a receipt would have been worth under older rules
+func CalculatePointsForVersion(receipt receipt.Receipt, version int) (Breakdown, error) {
+	ruleset, err := BuiltInRulesets().Version(version)
+	if err != nil {
+		return Breakdown{}, err
 	}
-	return points
+	return ruleset.Calculate(receipt), nil
 }
 
-func getPointsForOddPurchaseDate(date date.Date) int64 {
-	if !isMultipleOfUint(uint(date.Day), 2) {
-		return 6
+// Calculates the points for the receipt using the latest built-in ruleset, with its amounts first converted into the base currency of the exchange rate table
+func CalculatePointsNormalized(receipt receipt.Receipt, rates *exchangerate.Table) (Breakdown, error) {
+	rate, err := rates.Lookup(receipt.Currency, receipt.PurchaseDate)
+	if err != nil {
+		return Breakdown{}, err
 	}
-	return 0
+	return DefaultRuleset().CalculateWithRate(receipt, rate)
 }
 
-func getPointsForTimeOfDay(time time.Time) int64 {
-	if time.Hour < 14 || time.Hour >= 16 {
-		return 0
+// Converts the total and every item price of the receipt with the rate, so rules that measure amounts treat receipts in every currency the same
+func NormalizeReceipt(r receipt.Receipt, rate exchangerate.Rate) (receipt.Receipt, error) {
+	if r.Currency.OrDefault() != rate.From.OrDefault() {
+		return r, fmt.Errorf("%w, the receipt is in %s but the rate is from %s", exchangerate.ErrMismatchedCurrency, r.Currency.Code(), rate.From.Code())
+	}
+	normalized := r
+	normalized.Currency = rate.To
+	total, err := rate.Convert(r.Total)
+	if err != nil {
+		return r, err
 	}
-	if time.Hour == 14 && time.Minute == 0 {
-		return 0
+	normalized.Total = total
+	normalized.Items = make([]receiptitem.ReceiptItem, 0, len(r.Items))
+	for _, item := range r.Items {
+		price, err := rate.Convert(item.Price)
+		if err != nil {
+			return r, err
+		}
+		normalized.Items = append(normalized.Items, receiptitem.ReceiptItem{ShortDescription: item.ShortDescription, Price: price})
 	}
-	return 10
+	return normalized, nil
 }
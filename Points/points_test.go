@@ -1,7 +1,10 @@
 package points
 
 import (
+	"encoding/json"
 	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
 	receipt "go-receipt-processor/Receipt"
 	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
 	utils "go-receipt-processor/TestingUtils"
@@ -13,22 +16,22 @@ import (
 
 func Test_CalculatePoints(t *testing.T) {
 	var testCases []utils.CreationTestingData[receipt.Receipt, int64] = []utils.CreationTestingData[receipt.Receipt, int64]{
-		{Argument: receipt.Receipt{Retailer: "Target", PurchaseDate: date.Date{Year: 2022, Month: 01, Day: 01}, PurchaseTime: time.Time{Hour: 13, Minute: 01}, Total: 35.35,
+		{Argument: receipt.Receipt{Retailer: "Target", PurchaseDate: date.Date{Year: 2022, Month: 01, Day: 01}, PurchaseTime: time.Time{Hour: 13, Minute: 01}, Total: 3535,
 			Items: []receiptitem.ReceiptItem{
-				{ShortDescription: "Mountain Dew 12PK", Price: 6.49},
-				{ShortDescription: "Emils Cheese Pizza", Price: 12.25},
-				{ShortDescription: "Knorr Creamy Chicken", Price: 1.26},
-				{ShortDescription: "Doritos Nacho Cheese", Price: 3.35},
-				{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: 12.00}}},
+				{ShortDescription: "Mountain Dew 12PK", Price: 649},
+				{ShortDescription: "Emils Cheese Pizza", Price: 1225},
+				{ShortDescription: "Knorr Creamy Chicken", Price: 126},
+				{ShortDescription: "Doritos Nacho Cheese", Price: 335},
+				{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: 1200}}},
 			ExpectedResult: 28,
 			ExpectedErr:    nil,
 		},
-		{Argument: receipt.Receipt{Retailer: "M&M Corner Market", PurchaseDate: date.Date{Year: 2022, Month: 03, Day: 20}, PurchaseTime: time.Time{Hour: 14, Minute: 33}, Total: 9.00,
+		{Argument: receipt.Receipt{Retailer: "M&M Corner Market", PurchaseDate: date.Date{Year: 2022, Month: 03, Day: 20}, PurchaseTime: time.Time{Hour: 14, Minute: 33}, Total: 900,
 			Items: []receiptitem.ReceiptItem{
-				{ShortDescription: "Gatorade", Price: 2.25},
-				{ShortDescription: "Gatorade", Price: 2.25},
-				{ShortDescription: "Gatorade", Price: 2.25},
-				{ShortDescription: "Gatorade", Price: 2.25},
+				{ShortDescription: "Gatorade", Price: 225},
+				{ShortDescription: "Gatorade", Price: 225},
+				{ShortDescription: "Gatorade", Price: 225},
+				{ShortDescription: "Gatorade", Price: 225},
 			}},
 			ExpectedResult: 109,
 		},
@@ -42,7 +45,45 @@ func Test_CalculatePoints(t *testing.T) {
 	}
 }
 
-func Test_getPointsForAlphanumericalCharacters(t *testing.T) {
+func Test_CalculatePointsInCurrency(t *testing.T) {
+	atLawson := func(currency money.Currency, price money.Money) receipt.Receipt {
+		return receipt.Receipt{Retailer: "Lawson", PurchaseDate: date.Date{Year: 2022, Month: 01, Day: 02}, PurchaseTime: time.Time{Hour: 10, Minute: 15}, Currency: currency, Total: price,
+			Items: []receiptitem.ReceiptItem{{ShortDescription: "Bento Box", Price: price}}}
+	}
+	var testCases []utils.CreationTestingData[receipt.Receipt, int64] = []utils.CreationTestingData[receipt.Receipt, int64]{
+		{Argument: atLawson(money.USD, 1500), ExpectedResult: 6 + 50 + 25 + 3},
+		{Argument: atLawson(money.EUR, 1500), ExpectedResult: 6 + 50 + 25 + 3},
+		//Tests that the amount rules, written for currencies with a major unit worth about a dollar, are skipped for currencies they were not designed for
+		{Argument: atLawson(money.JPY, 1500), ExpectedResult: 6},
+		{Argument: atLawson(money.KRW, 15000), ExpectedResult: 6},
+		{Argument: atLawson(money.HUF, 150000), ExpectedResult: 6},
+	}
+	for _, testCase := range testCases {
+		result := CalculatePoints(testCase.Argument)
+		errCheck := testCase.CheckTestCase("calculate points in currency", result, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+// Finds the compiled rule with the given name in the default ruleset, so rule types are tested with the parameters they are shipped with
+func getDefaultRule(t *testing.T, name string) rule {
+	for i, definition := range DefaultRuleset().Rules {
+		if definition.Name == name {
+			compiledRule := DefaultRuleset().compiled[i]
+			if limited, isLimited := compiledRule.(currencyRule); isLimited {
+				return limited.rule // the rule itself, without the currencies it is limited to
+			}
+			return compiledRule
+		}
+	}
+	t.Fatalf("default ruleset is missing rule ( %s )", name)
+	return nil
+}
+
+func Test_alphanumericCharactersRule(t *testing.T) {
+	defaultRule := getDefaultRule(t, RuleAlphanumericRetailer).(alphanumericCharactersRule)
 	var testCases []utils.CreationTestingData[string, int64] = []utils.CreationTestingData[string, int64]{
 		{Argument: "", ExpectedResult: 0},
 		{Argument: "Target", ExpectedResult: 6},
@@ -53,7 +94,7 @@ func Test_getPointsForAlphanumericalCharacters(t *testing.T) {
 		{Argument: "?!____=-@=-=^^-?", ExpectedResult: 0},
 	}
 	for _, testCase := range testCases {
-		result := getPointsForAlphanumericalCharacters(testCase.Argument)
+		result := defaultRule.points(testCase.Argument)
 		if !cmp.Equal(result, testCase.ExpectedResult) {
 			t.Fatalf("get points for alphanumeric characters ( %+v ): expected result ( %+v ) got result ( %+v ), 1 point per alphanumeric character ( i.e. 0-9, a-z, A-Z )", testCase.Argument, testCase.ExpectedResult, result)
 		}
@@ -61,22 +102,23 @@ func Test_getPointsForAlphanumericalCharacters(t *testing.T) {
 	}
 }
 
-func Test_getPointsForRoundDollarAmount(t *testing.T) {
-	var testCases []utils.CreationTestingData[float64, int64] = []utils.CreationTestingData[float64, int64]{
-		{Argument: 0.00, ExpectedResult: 50},
-		{Argument: 1.00, ExpectedResult: 50},
-		{Argument: 5.25, ExpectedResult: 0},
-		{Argument: 10.50, ExpectedResult: 0},
-		{Argument: 5.00, ExpectedResult: 50},
-		{Argument: 3.76, ExpectedResult: 0},
-		{Argument: 20.29, ExpectedResult: 0},
-		{Argument: 100.50, ExpectedResult: 0},
-		{Argument: 0.50, ExpectedResult: 00},
-		{Argument: -6.30, ExpectedResult: 0},
-		{Argument: -9.00, ExpectedResult: 50},
+func Test_totalMultipleRule(t *testing.T) {
+	defaultRule := getDefaultRule(t, RuleRoundDollarTotal).(totalMultipleRule)
+	var testCases []utils.CreationTestingData[money.Money, int64] = []utils.CreationTestingData[money.Money, int64]{
+		{Argument: 0, ExpectedResult: 50},
+		{Argument: 100, ExpectedResult: 50},
+		{Argument: 525, ExpectedResult: 0},
+		{Argument: 1050, ExpectedResult: 0},
+		{Argument: 500, ExpectedResult: 50},
+		{Argument: 376, ExpectedResult: 0},
+		{Argument: 2029, ExpectedResult: 0},
+		{Argument: 10050, ExpectedResult: 0},
+		{Argument: 50, ExpectedResult: 00},
+		{Argument: -630, ExpectedResult: 0},
+		{Argument: -900, ExpectedResult: 50},
 	}
 	for _, testCase := range testCases {
-		result := getPointsForRoundDollarAmount(testCase.Argument)
+		result := defaultRule.points(testCase.Argument, money.USD)
 		if !cmp.Equal(result, testCase.ExpectedResult) {
 			t.Fatalf("get points for alphanumeric characters ( %+v ): expected result ( %+v ) got result ( %+v ), should get 50 points if it is a round dollar amount, and 0 points otherwise", testCase.Argument, testCase.ExpectedResult, result)
 		}
@@ -84,7 +126,8 @@ func Test_getPointsForRoundDollarAmount(t *testing.T) {
 	}
 }
 
-func Test_getPointsForNumberOfItems(t *testing.T) {
+func Test_itemCountRule(t *testing.T) {
+	defaultRule := getDefaultRule(t, RuleItemPairs).(itemCountRule)
 	var testCase []utils.CreationTestingData[[]receiptitem.ReceiptItem, int64] = []utils.CreationTestingData[[]receiptitem.ReceiptItem, int64]{
 		{Argument: []receiptitem.ReceiptItem{}, ExpectedResult: 0},
 		{Argument: []receiptitem.ReceiptItem{{}}, ExpectedResult: 0},
@@ -98,7 +141,7 @@ func Test_getPointsForNumberOfItems(t *testing.T) {
 	}
 
 	for _, testCase := range testCase {
-		result := getPointsForNumberOfItems(testCase.Argument)
+		result := defaultRule.points(len(testCase.Argument))
 		if !cmp.Equal(result, testCase.ExpectedResult) {
 			t.Fatalf("get points for number of items ( %+v ): expected result ( %+v ) got result ( %+v ), should be 5 points for every 2 items", testCase.Argument, testCase.ExpectedResult, result)
 		}
@@ -106,19 +149,22 @@ func Test_getPointsForNumberOfItems(t *testing.T) {
 	}
 }
 
-func Test_getPointsForItemDescriptionAndPrice(t *testing.T) {
+func Test_itemDescriptionLengthRule(t *testing.T) {
+	defaultRule := getDefaultRule(t, RuleItemDescriptionLength).(itemDescriptionLengthRule)
 	var testCases []utils.CreationTestingData[receiptitem.ReceiptItem, int64] = []utils.CreationTestingData[receiptitem.ReceiptItem, int64]{
 		{Argument: receiptitem.DefaultReceiptItem(), ExpectedResult: 0},
 		{Argument: receiptitem.ReceiptItem{}, ExpectedResult: 0},
-		{Argument: receiptitem.ReceiptItem{ShortDescription: "Mountain Dew 12PK", Price: 6.49}, ExpectedResult: 0},
-		{Argument: receiptitem.ReceiptItem{ShortDescription: "Emils Cheese Pizza", Price: 12.25}, ExpectedResult: 3},
-		{Argument: receiptitem.ReceiptItem{ShortDescription: "Knorr Creamy Chicken", Price: 1.26}, ExpectedResult: 0},
-		{Argument: receiptitem.ReceiptItem{ShortDescription: "Doritos Nacho Cheese", Price: 3.35}, ExpectedResult: 0},
-		{Argument: receiptitem.ReceiptItem{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: 12.00}, ExpectedResult: 3},
-		{Argument: receiptitem.ReceiptItem{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: -12.00}, ExpectedResult: -2}, //because price was never set to only be positive, the points that this receipt can return can actually be negative
+		{Argument: receiptitem.ReceiptItem{ShortDescription: "Mountain Dew 12PK", Price: 649}, ExpectedResult: 0},
+		{Argument: receiptitem.ReceiptItem{ShortDescription: "Emils Cheese Pizza", Price: 1225}, ExpectedResult: 3},
+		{Argument: receiptitem.ReceiptItem{ShortDescription: "Knorr Creamy Chicken", Price: 126}, ExpectedResult: 0},
+		{Argument: receiptitem.ReceiptItem{ShortDescription: "Doritos Nacho Cheese", Price: 335}, ExpectedResult: 0},
+		{Argument: receiptitem.ReceiptItem{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: 1200}, ExpectedResult: 3},
+		{Argument: receiptitem.ReceiptItem{ShortDescription: "Pepsi 6PK", Price: 1500}, ExpectedResult: 3},
+		{Argument: receiptitem.ReceiptItem{ShortDescription: "Pepsi 6PK", Price: 1501}, ExpectedResult: 4},                       //Tests that a fraction of a cent over a whole point is still rounded up
+		{Argument: receiptitem.ReceiptItem{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: -1200}, ExpectedResult: -2}, //because price was never set to only be positive, the points that this receipt can return can actually be negative
 	}
 	for _, testCase := range testCases {
-		result := getPointsForItemDescriptionAndPrice(testCase.Argument)
+		result := defaultRule.points(testCase.Argument.ShortDescription, testCase.Argument.Price, money.USD)
 		if !cmp.Equal(result, testCase.ExpectedResult) {
 			t.Fatalf("get points for item description and price ( %+v ): expected result ( %+v ) got result ( %+v ), if the trimmed description length is a multiple of 3, return points equal to the price * 0.2 rounded up to the nearest integer", testCase.Argument, testCase.ExpectedResult, result)
 		}
@@ -126,7 +172,8 @@ func Test_getPointsForItemDescriptionAndPrice(t *testing.T) {
 	}
 }
 
-func Test_getPointsForOddPurchaseDate(t *testing.T) {
+func Test_oddPurchaseDayRule(t *testing.T) {
+	defaultRule := getDefaultRule(t, RuleOddPurchaseDay).(oddPurchaseDayRule)
 	var testCases []utils.CreationTestingData[date.Date, int64] = []utils.CreationTestingData[date.Date, int64]{
 		{Argument: date.Date{}, ExpectedResult: 0},
 		{Argument: date.Date{Year: 2001, Month: 3, Day: 15}, ExpectedResult: 6},
@@ -135,14 +182,15 @@ func Test_getPointsForOddPurchaseDate(t *testing.T) {
 		{Argument: date.Date{Year: 7, Month: 1, Day: 1}, ExpectedResult: 6},
 	}
 	for _, testCase := range testCases {
-		result := getPointsForOddPurchaseDate(testCase.Argument)
+		result := defaultRule.points(testCase.Argument.Day)
 		if !cmp.Equal(result, testCase.ExpectedResult) {
 			t.Fatalf("get points for odd purchase date ( %+v ): expected result ( %+v ) got result ( %+v ), if the day of the purchase date is odd, return 6 points, and 0 points otherwise", testCase.Argument, testCase.ExpectedResult, result)
 		}
 	}
 }
 
-func Test_getPointsForTimeOfDay(t *testing.T) {
+func Test_purchaseTimeWindowRule(t *testing.T) {
+	defaultRule := getDefaultRule(t, RuleAfternoonPurchaseTime).(purchaseTimeWindowRule)
 	var testCases []utils.CreationTestingData[time.Time, int64] = []utils.CreationTestingData[time.Time, int64]{
 		{Argument: time.Time{}, ExpectedResult: 0},
 		{Argument: time.Time{}, ExpectedResult: 0},
@@ -151,18 +199,140 @@ func Test_getPointsForTimeOfDay(t *testing.T) {
 		{Argument: time.Time{Hour: 4, Minute: 59}, ExpectedResult: 0},
 		{Argument: time.Time{Hour: 9, Minute: 50}, ExpectedResult: 0},
 		{Argument: time.Time{Hour: 14, Minute: 00}, ExpectedResult: 0},
+		{Argument: time.Time{Hour: 14, Minute: 00, Second: 1}, ExpectedResult: 10}, //times with seconds are compared to the second
 		{Argument: time.Time{Hour: 14, Minute: 01}, ExpectedResult: 10},
 		{Argument: time.Time{Hour: 15, Minute: 00}, ExpectedResult: 10},
 		{Argument: time.Time{Hour: 15, Minute: 01}, ExpectedResult: 10},
 		{Argument: time.Time{Hour: 15, Minute: 59}, ExpectedResult: 10},
+		{Argument: time.Time{Hour: 15, Minute: 59, Second: 59}, ExpectedResult: 10},
 		{Argument: time.Time{Hour: 16, Minute: 00}, ExpectedResult: 0},
 		{Argument: time.Time{Hour: 16, Minute: 31}, ExpectedResult: 0},
 		{Argument: time.Time{Hour: 26, Minute: 31}, ExpectedResult: 0}, //all values that return 10 points are valid times, any invalid time, i.e. 50:106, will return 0
 	}
 	for _, testCase := range testCases {
-		result := getPointsForTimeOfDay(testCase.Argument)
+		result := defaultRule.points(testCase.Argument)
 		if !cmp.Equal(result, testCase.ExpectedResult) {
 			t.Fatalf("get points time of day ( %+v ): expected result ( %+v ) got result ( %+v ), if the purchase time is after 2:00 pm or 14:00 and before 4:00 pm or 16:00, return 10 points, and 0 points otherwise", testCase.Argument, testCase.ExpectedResult, result)
 		}
 	}
 }
+
+// Amounts are converted into the base currency on the purchase date before they are scored, so the same rules apply to every currency
+func Test_CalculatePointsNormalized(t *testing.T) {
+	rates, err := exchangerate.ParseTable([]byte(`{"base": "USD", "rates": [{"currency": "EUR", "date": "2024-01-01", "rate": 1.10}, {"currency": "EUR", "date": "2024-02-01", "rate": 1.08}]}`), exchangerate.FormatJSON)
+	if err != nil {
+		t.Fatalf("parse exchange rates: unexpected error ( %v )", err)
+	}
+	coffee := []receiptitem.ReceiptItem{{ShortDescription: "Coffee Beans", Price: 1000}}
+	var testCases []utils.CreationTestingData[receipt.Receipt, Breakdown] = []utils.CreationTestingData[receipt.Receipt, Breakdown]{
+		{ //10.00 EUR is 10.80 USD, so is no longer a round amount, and the item is worth ceil(2.16) points rather than 2
+			Argument:       receipt.Receipt{Retailer: "Lidl", PurchaseDate: date.Date{Year: 2024, Month: 2, Day: 10}, PurchaseTime: time.Time{Hour: 10, Minute: 15}, Total: 1000, Currency: money.EUR, Items: coffee},
+			ExpectedResult: Breakdown{Points: 4 + 3, RulesetVersion: 1, ExchangeRate: &exchangerate.Rate{From: money.EUR, To: money.USD, EffectiveDate: "2024-02-01", Rate: "1.08"}},
+		},
+		{ //10.00 EUR is 11.00 USD at the earlier rate
+			Argument:       receipt.Receipt{Retailer: "Lidl", PurchaseDate: date.Date{Year: 2024, Month: 1, Day: 10}, PurchaseTime: time.Time{Hour: 10, Minute: 15}, Total: 1000, Currency: money.EUR, Items: coffee},
+			ExpectedResult: Breakdown{Points: 4 + 50 + 25 + 3, RulesetVersion: 1, ExchangeRate: &exchangerate.Rate{From: money.EUR, To: money.USD, EffectiveDate: "2024-01-01", Rate: "1.1"}},
+		},
+		{ //Tests that receipts already in the base currency are scored as they are
+			Argument:       receipt.Receipt{Retailer: "Lidl", PurchaseDate: date.Date{Year: 2024, Month: 2, Day: 10}, PurchaseTime: time.Time{Hour: 10, Minute: 15}, Total: 1000, Currency: money.USD, Items: coffee},
+			ExpectedResult: Breakdown{Points: 4 + 50 + 25 + 2, RulesetVersion: 1},
+		},
+		{
+			Argument:       receipt.Receipt{Retailer: "Lidl", PurchaseDate: date.Date{Year: 2024, Month: 2, Day: 10}, PurchaseTime: time.Time{Hour: 10, Minute: 15}, Total: 1000, Currency: money.GBP, Items: coffee},
+			ExpectedResult: Breakdown{},
+			ExpectedErr:    exchangerate.ErrRateNotFound,
+		},
+	}
+	for _, testCase := range testCases {
+		result, err := CalculatePointsNormalized(testCase.Argument, rates)
+		result.Rules = nil
+		errCheck := testCase.CheckTestCase("calculate normalized points", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	if _, err := DefaultRuleset().CalculateWithRate(receipt.Receipt{Currency: money.CAD}, &exchangerate.Rate{From: money.EUR, To: money.USD, Rate: "1.1"}); err == nil {
+		t.Fatalf("calculate points with rate: expected an error for a rate from a different currency")
+	}
+}
+
+func Test_CalculatePointsInStoreTimeZone(t *testing.T) {
+	//a receipt with no retailer name, items or round total, so only the purchase date and time rules can award it points
+	purchasedAt := func(day uint8, hour uint8, minute uint8, timeZone time.Zone, storeTimeZone time.Zone) receipt.Receipt {
+		return receipt.Receipt{PurchaseDate: date.Date{Year: 2023, Month: 10, Day: day}, PurchaseTime: time.Time{Hour: hour, Minute: minute}, Total: 1, Currency: money.USD, TimeZone: timeZone, StoreTimeZone: storeTimeZone}
+	}
+	var testCases []utils.CreationTestingData[receipt.Receipt, int64] = []utils.CreationTestingData[receipt.Receipt, int64]{
+		{Argument: purchasedAt(15, 20, 30, "", ""), ExpectedResult: 6},
+		{Argument: purchasedAt(15, 20, 30, time.UTC, time.UTC), ExpectedResult: 6},
+		//20:30 UTC is 15:30 at a store in Chicago, which is during the afternoon window
+		{Argument: purchasedAt(15, 20, 30, time.UTC, "America/Chicago"), ExpectedResult: 6 + 10},
+		//01:30 UTC on the 16th is 20:30 on the 15th in Chicago, which is an odd day
+		{Argument: purchasedAt(16, 1, 30, time.UTC, "America/Chicago"), ExpectedResult: 6},
+		{Argument: purchasedAt(16, 1, 30, time.UTC, time.UTC), ExpectedResult: 0},
+		//written in the store's zone already, so nothing is converted
+		{Argument: purchasedAt(15, 15, 30, "America/Chicago", "America/Chicago"), ExpectedResult: 6 + 10},
+	}
+	for _, testCase := range testCases {
+		result := CalculatePoints(testCase.Argument)
+		if !cmp.Equal(result, testCase.ExpectedResult) {
+			t.Fatalf("calculate points in store time zone ( %+v ): expected result ( %+v ) got result ( %+v )", testCase.Argument, testCase.ExpectedResult, result)
+		}
+	}
+
+	breakdown := CalculatePointsBreakdown(purchasedAt(15, 20, 30, time.UTC, "America/Chicago"))
+	for _, rule := range breakdown.Rules {
+		if rule.Rule == RuleAfternoonPurchaseTime && rule.Description != "10 points because the time of purchase, 15:30 in America/Chicago, is after 14:00 and before 16:00" {
+			t.Fatalf("calculate points breakdown in store time zone: unexpected description ( %s )", rule.Description)
+		}
+	}
+}
+
+func Test_CalculatePointsBreakdown(t *testing.T) {
+	items := []receiptitem.ReceiptItem{
+		{ShortDescription: "Mountain Dew 12PK", Price: 649},
+		{ShortDescription: "Emils Cheese Pizza", Price: 1225},
+		{ShortDescription: "Knorr Creamy Chicken", Price: 126},
+		{ShortDescription: "Doritos Nacho Cheese", Price: 335},
+		{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: 1200},
+	}
+	breakdown := CalculatePointsBreakdown(receipt.Receipt{Retailer: "Target", PurchaseDate: date.Date{Year: 2022, Month: 01, Day: 01}, PurchaseTime: time.Time{Hour: 13, Minute: 01}, Total: 3535, Items: items})
+
+	type awarded struct {
+		Rule      string
+		Points    int64
+		ItemIndex int
+	}
+	expected := []awarded{
+		{Rule: RuleAlphanumericRetailer, Points: 6, ItemIndex: -1},
+		{Rule: RuleItemPairs, Points: 10, ItemIndex: -1},
+		{Rule: RuleItemDescriptionLength, Points: 3, ItemIndex: 1},
+		{Rule: RuleItemDescriptionLength, Points: 3, ItemIndex: 4},
+		{Rule: RuleOddPurchaseDay, Points: 6, ItemIndex: -1},
+	}
+	actual := []awarded{}
+	for _, rule := range breakdown.Rules {
+		itemIndex := -1
+		if rule.ItemIndex != nil {
+			itemIndex = *rule.ItemIndex
+			if rule.Item.ShortDescription != items[itemIndex].ShortDescription || rule.Item.Price != items[itemIndex].Price {
+				t.Fatalf("calculate points breakdown: rule ( %s ) expected item ( %+v ) got item ( %+v )", rule.Rule, items[itemIndex], *rule.Item)
+			}
+		}
+		if rule.Description == "" {
+			t.Fatalf("calculate points breakdown: rule ( %s ) is missing a description", rule.Rule)
+		}
+		actual = append(actual, awarded{Rule: rule.Rule, Points: rule.Points, ItemIndex: itemIndex})
+	}
+	if !cmp.Equal(expected, actual) {
+		t.Fatalf("calculate points breakdown: expected rules ( %+v ) got rules ( %+v )", expected, actual)
+	}
+	if breakdown.Points != 28 {
+		t.Fatalf("calculate points breakdown: expected total ( 28 ) got total ( %d )", breakdown.Points)
+	}
+	encoded, _ := json.Marshal(breakdown.Rules[2].Item)
+	expectedJson := `{"shortDescription":"Emils Cheese Pizza","price":12.25}`
+	if string(encoded) != expectedJson {
+		t.Fatalf("calculate points breakdown: expected json ( %s ) got json ( %s )", expectedJson, encoded)
+	}
+}
@@ -0,0 +1,190 @@
+package points
+
+import (
+	"fmt"
+	money "go-receipt-processor/Money"
+	receipt "go-receipt-processor/Receipt"
+	time "go-receipt-processor/Time"
+	"regexp"
+	"strings"
+)
+
+var alphanumericRegex = regexp.MustCompile("[[:alnum:]]")
+
+// Types of rules that can be used within a ruleset, each one is configured by the parameters of its RuleDefinition
+const (
+	RuleTypeAlphanumericCharacters = "alphanumericCharacters"
+	RuleTypeTotalMultiple          = "totalMultiple"
+	RuleTypeItemCount              = "itemCount"
+	RuleTypeItemDescriptionLength  = "itemDescriptionLength"
+	RuleTypeOddPurchaseDay         = "oddPurchaseDay"
+	RuleTypePurchaseTimeWindow     = "purchaseTimeWindow"
+)
+
+// A single compiled rule, adds any points it awards to the breakdown
+type rule interface {
+	apply(receipt receipt.Receipt, breakdown *Breakdown)
+}
+
+func pluralize(count int64, singular string, plural string) string {
+	if count == 1 || count == -1 {
+		return singular
+	}
+	return plural
+}
+
+func isMultipleOfUint(value uint, multiple uint) bool {
+	remainder := (value - uint(value/multiple)*multiple)
+	return remainder == 0
+}
+
+// Awards points for every alphanumeric character in the retailer name
+type alphanumericCharactersRule struct {
+	name               string
+	pointsPerCharacter int64
+}
+
+func (r alphanumericCharactersRule) points(str string) int64 {
+	alphanumericString := alphanumericRegex.FindAllString(str, -1)
+	alphanumericCount := len(alphanumericString)
+	return int64(alphanumericCount) * r.pointsPerCharacter
+}
+
+func (r alphanumericCharactersRule) apply(receipt receipt.Receipt, breakdown *Breakdown) {
+	breakdown.award(r.name,
+		fmt.Sprintf("%d %s for every alphanumeric character in the retailer name \"%s\"", r.pointsPerCharacter, pluralize(r.pointsPerCharacter, "point", "points"), receipt.Retailer),
+		r.points(receipt.Retailer))
+}
+
+// Only applies the wrapped rule to receipts in one of the given currencies
+type currencyRule struct {
+	rule
+	currencies map[money.Currency]bool
+}
+
+func (r currencyRule) apply(receipt receipt.Receipt, breakdown *Breakdown) {
+	if r.currencies[receipt.Currency.OrDefault()] {
+		r.rule.apply(receipt, breakdown)
+	}
+}
+
+// Awards a flat number of points if the total is a multiple of the given amount of the receipt's currency ( i.e. 0.25 is a quarter of a dollar for USD, or of a euro for EUR )
+type totalMultipleRule struct {
+	name                string
+	multiple            string
+	multipleNumerator   int64
+	multipleDenominator int64
+	reward              int64
+}
+
+func (r totalMultipleRule) points(total money.Money, currency money.Currency) int64 {
+	if total.IsMultipleOfDecimal(r.multipleNumerator, r.multipleDenominator, currency) {
+		return r.reward
+	}
+	return 0
+}
+
+func (r totalMultipleRule) apply(receipt receipt.Receipt, breakdown *Breakdown) {
+	breakdown.award(r.name,
+		fmt.Sprintf("%d points because the total, %s %s, is a multiple of %s", r.reward, receipt.Total.Format(receipt.Currency), receipt.Currency.Code(), r.multiple),
+		r.points(receipt.Total, receipt.Currency))
+}
+
+// Awards points for every complete group of items on the receipt
+type itemCountRule struct {
+	name      string
+	groupSize int
+	reward    int64
+}
+
+func (r itemCountRule) points(itemCount int) int64 {
+	return int64(itemCount/r.groupSize) * r.reward
+}
+
+func (r itemCountRule) apply(receipt receipt.Receipt, breakdown *Breakdown) {
+	breakdown.award(r.name,
+		fmt.Sprintf("%d points for every %d items on the receipt ( %d items )", r.reward, r.groupSize, len(receipt.Items)),
+		r.points(len(receipt.Items)))
+}
+
+// Awards each item whose trimmed description length is a multiple of the given length a fraction of its price, rounded up to a whole unit of the receipt's currency.
+// The multiplier is kept as an exact fraction so that prices like 12.25 * 0.2 never round up because of floating point error.
+type itemDescriptionLengthRule struct {
+	name                       string
+	lengthMultiple             uint
+	priceMultiplier            string
+	priceMultiplierNumerator   int64
+	priceMultiplierDenominator int64
+}
+
+func (r itemDescriptionLengthRule) points(shortDescription string, price money.Money, currency money.Currency) int64 {
+	trimmedDescription := strings.Trim(shortDescription, " ")
+	if isMultipleOfUint(uint(len(trimmedDescription)), r.lengthMultiple) {
+		points := price.Multiply(r.priceMultiplierNumerator, r.priceMultiplierDenominator, money.RoundUp).WholeUnitsIn(currency, money.RoundUp)
+		return points
+	}
+	return 0
+}
+
+func (r itemDescriptionLengthRule) apply(receipt receipt.Receipt, breakdown *Breakdown) {
+	for i, item := range receipt.Items {
+		breakdown.awardForItem(r.name,
+			fmt.Sprintf("the trimmed description \"%s\" has a length that is a multiple of %d, so the price, %s %s, is multiplied by %s and rounded up", strings.Trim(item.ShortDescription, " "), r.lengthMultiple, item.Price.Format(receipt.Currency), receipt.Currency.Code(), r.priceMultiplier),
+			r.points(item.ShortDescription, item.Price, receipt.Currency), i, item)
+	}
+}
+
+// Awards a flat number of points if the day of the purchase date is odd
+type oddPurchaseDayRule struct {
+	name   string
+	reward int64
+}
+
+func (r oddPurchaseDayRule) points(day uint8) int64 {
+	if !isMultipleOfUint(uint(day), 2) {
+		return r.reward
+	}
+	return 0
+}
+
+func (r oddPurchaseDayRule) apply(receipt receipt.Receipt, breakdown *Breakdown) {
+	purchaseDate, _ := receipt.LocalPurchaseDateTime()
+	breakdown.award(r.name,
+		fmt.Sprintf("%d points because the day of the purchase date, %s%s, is odd", r.reward, purchaseDate, storeZoneDescription(receipt)),
+		r.points(purchaseDate.Day))
+}
+
+// Awards a flat number of points if the purchase time is strictly after one time and strictly before another
+type purchaseTimeWindowRule struct {
+	name   string
+	after  time.Time
+	before time.Time
+	reward int64
+}
+
+func secondsSinceMidnight(t time.Time) int {
+	return int(t.Hour)*60*60 + int(t.Minute)*60 + int(t.Second)
+}
+
+func (r purchaseTimeWindowRule) points(purchaseTime time.Time) int64 {
+	seconds := secondsSinceMidnight(purchaseTime)
+	if seconds > secondsSinceMidnight(r.after) && seconds < secondsSinceMidnight(r.before) {
+		return r.reward
+	}
+	return 0
+}
+
+func (r purchaseTimeWindowRule) apply(receipt receipt.Receipt, breakdown *Breakdown) {
+	_, purchaseTime := receipt.LocalPurchaseDateTime()
+	breakdown.award(r.name,
+		fmt.Sprintf("%d points because the time of purchase, %s%s, is after %s and before %s", r.reward, purchaseTime, storeZoneDescription(receipt), r.after, r.before),
+		r.points(purchaseTime))
+}
+
+// Names the store's zone in descriptions of rules that use the local purchase date or time, so it is clear when they were converted
+func storeZoneDescription(receipt receipt.Receipt) string {
+	if receipt.StoreTimeZone.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" in %s", receipt.StoreTimeZone)
+}
@@ -0,0 +1,223 @@
+package points
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	money "go-receipt-processor/Money"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
+	receipt "go-receipt-processor/Receipt"
+	time "go-receipt-processor/Time"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrParsingRuleset    = errors.New("parsing ruleset")
+	ErrUnknownFormat     = errors.New("unknown ruleset format")
+	ErrUnknownRuleType   = errors.New("unknown rule type")
+	ErrInvalidRule       = errors.New("invalid rule")
+	ErrDuplicateRuleName = errors.New("duplicate rule name")
+	ErrInvalidVersion    = errors.New("invalid ruleset version")
+)
+
+// Supported ruleset file formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Every version of the rules that has been shipped, receipts keep the version they were scored with so these should never be edited once released
+//
+//go:embed rulesets/*.json
+var builtInRulesetFiles embed.FS
+
+// Configuration for a single rule. Which parameters are used depends on the rule's type, any parameters that a type does not use are ignored.
+type RuleDefinition struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+
+	Points             int64   `json:"points,omitempty" yaml:"points,omitempty"`                         // totalMultiple, itemCount, oddPurchaseDay, purchaseTimeWindow
+	PointsPerCharacter int64   `json:"pointsPerCharacter,omitempty" yaml:"pointsPerCharacter,omitempty"` // alphanumericCharacters
+	Multiple           float64 `json:"multiple,omitempty" yaml:"multiple,omitempty"`                     // totalMultiple
+	GroupSize          int     `json:"groupSize,omitempty" yaml:"groupSize,omitempty"`                   // itemCount
+	LengthMultiple     uint    `json:"lengthMultiple,omitempty" yaml:"lengthMultiple,omitempty"`         // itemDescriptionLength
+	PriceMultiplier    float64 `json:"priceMultiplier,omitempty" yaml:"priceMultiplier,omitempty"`       // itemDescriptionLength
+	After              string  `json:"after,omitempty" yaml:"after,omitempty"`                           // purchaseTimeWindow, formatted HH:MM
+	Before             string  `json:"before,omitempty" yaml:"before,omitempty"`                         // purchaseTimeWindow, formatted HH:MM
+
+	Currencies []string `json:"currencies,omitempty" yaml:"currencies,omitempty"` // any type, limits the rule to receipts in these ISO 4217 currencies, otherwise it applies to every receipt
+}
+
+// An ordered set of rules that together decide how many points a receipt is worth.
+// The version identifies the rules a receipt was scored with, so any change to the rules should be released as a new version rather than by editing an existing one.
+type Ruleset struct {
+	Name    string           `json:"name" yaml:"name"`
+	Version int              `json:"version" yaml:"version"`
+	Rules   []RuleDefinition `json:"rules" yaml:"rules"`
+
+	compiled []rule
+}
+
+func (d RuleDefinition) compile() (rule, error) {
+	compiledRule, err := d.compileType()
+	if err != nil || len(d.Currencies) == 0 {
+		return compiledRule, err
+	}
+	currencies := map[money.Currency]bool{}
+	for _, code := range d.Currencies {
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			return nil, fmt.Errorf("%w \"%s\" of type \"%s\" ... %w", ErrInvalidRule, d.Name, d.Type, err)
+		}
+		currencies[currency] = true
+	}
+	return currencyRule{rule: compiledRule, currencies: currencies}, nil
+}
+
+func (d RuleDefinition) compileType() (rule, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w \"%s\" of type \"%s\", %s", ErrInvalidRule, d.Name, d.Type, reason)
+	}
+	switch d.Type {
+	case RuleTypeAlphanumericCharacters:
+		return alphanumericCharactersRule{name: d.Name, pointsPerCharacter: d.PointsPerCharacter}, nil
+	case RuleTypeTotalMultiple:
+		multiple := strconv.FormatFloat(d.Multiple, 'f', -1, 64)
+		numerator, denominator, err := money.ParseDecimal(multiple)
+		if err != nil || numerator <= 0 {
+			return nil, invalid("multiple must be an exact decimal greater than 0")
+		}
+		return totalMultipleRule{name: d.Name, multiple: multiple, multipleNumerator: numerator, multipleDenominator: denominator, reward: d.Points}, nil
+	case RuleTypeItemCount:
+		if d.GroupSize <= 0 {
+			return nil, invalid("groupSize must be greater than 0")
+		}
+		return itemCountRule{name: d.Name, groupSize: d.GroupSize, reward: d.Points}, nil
+	case RuleTypeItemDescriptionLength:
+		if d.LengthMultiple == 0 {
+			return nil, invalid("lengthMultiple must be greater than 0")
+		}
+		priceMultiplier := strconv.FormatFloat(d.PriceMultiplier, 'f', -1, 64)
+		numerator, denominator, err := money.ParseDecimal(priceMultiplier)
+		if err != nil {
+			return nil, fmt.Errorf("%w ... %w", invalid("priceMultiplier must be an exact decimal"), err)
+		}
+		return itemDescriptionLengthRule{name: d.Name, lengthMultiple: d.LengthMultiple, priceMultiplier: priceMultiplier, priceMultiplierNumerator: numerator, priceMultiplierDenominator: denominator}, nil
+	case RuleTypeOddPurchaseDay:
+		return oddPurchaseDayRule{name: d.Name, reward: d.Points}, nil
+	case RuleTypePurchaseTimeWindow:
+		after, afterErr := time.ParseTime(d.After, true)
+		before, beforeErr := time.ParseTime(d.Before, true)
+		if err := errors.Join(afterErr, beforeErr); err != nil {
+			return nil, fmt.Errorf("%w ... %w", invalid("after and before must be valid times"), err)
+		}
+		return purchaseTimeWindowRule{name: d.Name, after: after, before: before, reward: d.Points}, nil
+	default:
+		return nil, fmt.Errorf("%w \"%s\" given for rule \"%s\"", ErrUnknownRuleType, d.Type, d.Name)
+	}
+}
+
+// Checks every rule definition and prepares them to be applied to receipts
+func (r *Ruleset) compile() error {
+	compiled := make([]rule, 0, len(r.Rules))
+	names := map[string]bool{}
+	errs := []error{}
+	if r.Version <= 0 {
+		errs = append(errs, fmt.Errorf("%w given %d, versions must be greater than 0", ErrInvalidVersion, r.Version))
+	}
+	for i, definition := range r.Rules {
+		if definition.Name == "" {
+			definition.Name = definition.Type
+			r.Rules[i].Name = definition.Type
+		}
+		if names[definition.Name] {
+			errs = append(errs, fmt.Errorf("%w \"%s\"", ErrDuplicateRuleName, definition.Name))
+			continue
+		}
+		names[definition.Name] = true
+		compiledRule, err := definition.compile()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		compiled = append(compiled, compiledRule)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	r.compiled = compiled
+	return nil
+}
+
+// Calculates the points for the receipt, keeping track of which rule, and which item if any, each point came from
+func (r *Ruleset) Calculate(receipt receipt.Receipt) Breakdown {
+	breakdown := Breakdown{RulesetVersion: r.Version, Rules: []AwardedRule{}}
+	for _, compiledRule := range r.compiled {
+		compiledRule.apply(receipt, &breakdown)
+	}
+	return breakdown
+}
+
+// Calculates the points for the receipt after converting its amounts with the rate, recording the rate on the breakdown. A nil rate scores the receipt as it is.
+func (r *Ruleset) CalculateWithRate(receipt receipt.Receipt, rate *exchangerate.Rate) (Breakdown, error) {
+	if rate == nil {
+		return r.Calculate(receipt), nil
+	}
+	normalized, err := NormalizeReceipt(receipt, *rate)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	breakdown := r.Calculate(normalized)
+	breakdown.ExchangeRate = rate
+	return breakdown, nil
+}
+
+// Parses and compiles a ruleset given in either json or yaml. Unknown fields are rejected so typos in rule parameters are caught when loading.
+func ParseRuleset(data []byte, format string) (*Ruleset, error) {
+	ruleset := &Ruleset{}
+	var err error
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(ruleset)
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(ruleset)
+	default:
+		return nil, fmt.Errorf("%w \"%s\" ( valid formats are %s and %s )", ErrUnknownFormat, format, FormatJSON, FormatYAML)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w ... %w", ErrParsingRuleset, err)
+	}
+	if err := ruleset.compile(); err != nil {
+		return nil, fmt.Errorf("%w \"%s\" ... %w", ErrParsingRuleset, ruleset.Name, err)
+	}
+	return ruleset, nil
+}
+
+// Loads a ruleset from a file, using the file extension ( .json, .yaml or .yml ) to decide its format
+func LoadRuleset(path string) (*Ruleset, error) {
+	format := ""
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = FormatJSON
+	case ".yaml", ".yml":
+		format = FormatYAML
+	default:
+		return nil, fmt.Errorf("%w given \"%s\" ... %w \"%s\"", ErrParsingRuleset, path, ErrUnknownFormat, filepath.Ext(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrParsingRuleset, path, err)
+	}
+	return ParseRuleset(data, format)
+}
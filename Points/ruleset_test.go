@@ -0,0 +1,184 @@
+package points
+
+import (
+	"encoding/json"
+	receipt "go-receipt-processor/Receipt"
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	utils "go-receipt-processor/TestingUtils"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// A receipt and the number of points it was awarded before the rules were made configurable
+type goldenReceipt struct {
+	receipt.UnparsedReceipt
+	Points int64
+}
+
+func loadGoldenReceipts(t *testing.T) []goldenReceipt {
+	data, err := os.ReadFile("testdata/golden.json")
+	if err != nil {
+		t.Fatalf("load golden receipts: %v", err)
+	}
+	goldenReceipts := []goldenReceipt{}
+	if err := json.Unmarshal(data, &goldenReceipts); err != nil {
+		t.Fatalf("load golden receipts: %v", err)
+	}
+	return goldenReceipts
+}
+
+// Every golden receipt has to be given exactly the same score as the hard-coded rules gave it
+func checkGoldenScores(t *testing.T, ruleset *Ruleset) {
+	for i, golden := range loadGoldenReceipts(t) {
+		parsedReceipt, err := receipt.ParseReceipt("golden", golden.UnparsedReceipt, true)
+		if err != nil {
+			t.Fatalf("golden receipt %d: unexpected error ( %v )", i, err)
+		}
+		breakdown := ruleset.Calculate(parsedReceipt)
+		if breakdown.Points != golden.Points {
+			t.Fatalf("golden receipt %d ( %+v ): expected result ( %d ) got result ( %d )\n    breakdown: %+v", i, golden.UnparsedReceipt, golden.Points, breakdown.Points, breakdown.Rules)
+		}
+		var sum int64 = 0
+		for _, awarded := range breakdown.Rules {
+			sum += awarded.Points
+		}
+		if sum != breakdown.Points {
+			t.Fatalf("golden receipt %d: breakdown rules sum to ( %d ) but total is ( %d )", i, sum, breakdown.Points)
+		}
+	}
+}
+
+func Test_DefaultRulesetGoldenScores(t *testing.T) {
+	checkGoldenScores(t, DefaultRuleset())
+}
+
+func Test_LoadRulesetYAMLGoldenScores(t *testing.T) {
+	ruleset, err := LoadRuleset("testdata/default.yaml")
+	if err != nil {
+		t.Fatalf("load ruleset: unexpected error ( %v )", err)
+	}
+	if !cmp.Equal(ruleset.Rules, DefaultRuleset().Rules) {
+		t.Fatalf("load ruleset: expected yaml rules ( %+v ) to match the default rules ( %+v )", ruleset.Rules, DefaultRuleset().Rules)
+	}
+	checkGoldenScores(t, ruleset)
+}
+
+func Test_ParseRuleset(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, int] = []utils.CreationTestingData[string, int]{
+		{Argument: `{"name": "empty", "version": 1, "rules": []}`, ExpectedResult: 0},
+		{Argument: `{"name": "one", "version": 1, "rules": [{"type": "oddPurchaseDay", "points": 6}]}`, ExpectedResult: 1},
+		{Argument: `{"name": "typo", "version": 1, "rules": [{"type": "oddPurchaseDay", "pionts": 6}]}`, ExpectedErr: ErrParsingRuleset},
+		{Argument: `{"name": "unknown", "version": 1, "rules": [{"type": "evenPurchaseDay", "points": 6}]}`, ExpectedErr: ErrUnknownRuleType},
+		{Argument: `{"name": "zero multiple", "version": 1, "rules": [{"type": "totalMultiple", "points": 6}]}`, ExpectedErr: ErrInvalidRule},
+		{Argument: `{"name": "zero group", "version": 1, "rules": [{"type": "itemCount", "points": 5}]}`, ExpectedErr: ErrInvalidRule},
+		{Argument: `{"name": "zero length", "version": 1, "rules": [{"type": "itemDescriptionLength", "priceMultiplier": 0.2}]}`, ExpectedErr: ErrInvalidRule},
+		{Argument: `{"name": "bad window", "version": 1, "rules": [{"type": "purchaseTimeWindow", "after": "2pm", "before": "16:00", "points": 10}]}`, ExpectedErr: ErrInvalidRule},
+		{Argument: `{"name": "duplicate", "version": 1, "rules": [{"type": "oddPurchaseDay", "points": 6}, {"type": "oddPurchaseDay", "points": 3}]}`, ExpectedErr: ErrDuplicateRuleName},
+		{Argument: `{"name": "broken",`, ExpectedErr: ErrParsingRuleset},
+		{Argument: `{"name": "unversioned", "rules": []}`, ExpectedErr: ErrInvalidVersion},
+		{Argument: `{"name": "negative", "version": -2, "rules": []}`, ExpectedErr: ErrInvalidVersion},
+		{Argument: `{"name": "currencies", "version": 1, "rules": [{"type": "oddPurchaseDay", "points": 6, "currencies": ["CAD", "eur"]}]}`, ExpectedResult: 1},
+		{Argument: `{"name": "unknown currency", "version": 1, "rules": [{"type": "oddPurchaseDay", "points": 6, "currencies": ["dollars"]}]}`, ExpectedErr: ErrInvalidRule},
+		{Argument: `{"name": "inexact multiple", "version": 1, "rules": [{"type": "totalMultiple", "multiple": 1e-30, "points": 6}]}`, ExpectedErr: ErrInvalidRule},
+	}
+	for _, testCase := range testCases {
+		ruleset, err := ParseRuleset([]byte(testCase.Argument), FormatJSON)
+		result := 0
+		if ruleset != nil {
+			result = len(ruleset.compiled)
+		}
+		errCheck := testCase.CheckTestCase("parse ruleset", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	if _, err := ParseRuleset([]byte(`{}`), "toml"); err == nil {
+		t.Fatalf("parse ruleset: expected an error for an unknown format")
+	}
+	if _, err := LoadRuleset("testdata/golden.txt"); err == nil {
+		t.Fatalf("load ruleset: expected an error for an unknown file extension")
+	}
+}
+
+// Changing a parameter in the ruleset should change the score without any code changes
+func Test_CustomRuleset(t *testing.T) {
+	ruleset, err := ParseRuleset([]byte(`
+name: double points weekend promotion
+version: 2
+rules:
+  - name: retailer
+    type: alphanumericCharacters
+    pointsPerCharacter: 2
+  - name: threeItems
+    type: itemCount
+    groupSize: 3
+    points: 20
+  - name: lunch
+    type: purchaseTimeWindow
+    after: "11:00"
+    before: "13:30"
+    points: 15
+`), FormatYAML)
+	if err != nil {
+		t.Fatalf("parse ruleset: unexpected error ( %v )", err)
+	}
+	items := []receiptitem.UnparsedReceiptItem{{ShortDescription: "Gatorade", Price: "2.25"}, {ShortDescription: "Gatorade", Price: "2.25"}, {ShortDescription: "Gatorade", Price: "2.25"}}
+	parsedReceipt, _ := receipt.ParseReceipt("0", receipt.UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "12:15", Total: "6.75", Items: items}, true)
+	breakdown := ruleset.Calculate(parsedReceipt)
+	if breakdown.Points != 12+20+15 {
+		t.Fatalf("custom ruleset: expected result ( %d ) got result ( %d )", 12+20+15, breakdown.Points)
+	}
+}
+
+// Amounts are measured in whole units of each receipt's own currency, and rules can be limited to only some currencies
+func Test_RulesetCurrencies(t *testing.T) {
+	ruleset, err := ParseRuleset([]byte(`
+name: per currency rules
+version: 2
+rules:
+  - name: roundTotal
+    type: totalMultiple
+    multiple: 1
+    points: 50
+    currencies: [USD, CAD, EUR]
+  - name: roundYenTotal
+    type: totalMultiple
+    multiple: 100
+    points: 50
+    currencies: [JPY]
+  - name: itemPrice
+    type: itemDescriptionLength
+    lengthMultiple: 1
+    priceMultiplier: 0.2
+`), FormatYAML)
+	if err != nil {
+		t.Fatalf("parse ruleset: unexpected error ( %v )", err)
+	}
+	type currencyTestCase struct {
+		Currency string
+		Price    string
+	}
+	var testCases []utils.CreationTestingData[currencyTestCase, int64] = []utils.CreationTestingData[currencyTestCase, int64]{
+		{Argument: currencyTestCase{Currency: "", Price: "12.00"}, ExpectedResult: 50 + 3},
+		{Argument: currencyTestCase{Currency: "CAD", Price: "12.00"}, ExpectedResult: 50 + 3},
+		{Argument: currencyTestCase{Currency: "EUR", Price: "12,50"}, ExpectedResult: 0 + 3},
+		{Argument: currencyTestCase{Currency: "GBP", Price: "12.00"}, ExpectedResult: 0 + 3}, //Tests that rules limited to other currencies are skipped
+		{Argument: currencyTestCase{Currency: "JPY", Price: "1500"}, ExpectedResult: 50 + 300},
+		{Argument: currencyTestCase{Currency: "JPY", Price: "1501"}, ExpectedResult: 0 + 301},
+		{Argument: currencyTestCase{Currency: "KWD", Price: "12.001"}, ExpectedResult: 0 + 3},
+	}
+	for _, testCase := range testCases {
+		items := []receiptitem.UnparsedReceiptItem{{ShortDescription: "Gatorade", Price: testCase.Argument.Price}}
+		parsedReceipt, err := receipt.ParseReceipt("0", receipt.UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-02", PurchaseTime: "10:15", Total: testCase.Argument.Price, Currency: testCase.Argument.Currency, Items: items}, true)
+		if err != nil {
+			t.Fatalf("ruleset currencies ( %+v ): unexpected error ( %v )", testCase.Argument, err)
+		}
+		errCheck := testCase.CheckTestCase("ruleset currencies", ruleset.Calculate(parsedReceipt).Points, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
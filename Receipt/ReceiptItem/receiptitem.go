@@ -3,7 +3,7 @@ package receiptitem
 import (
 	"errors"
 	"fmt"
-	"strconv"
+	money "go-receipt-processor/Money"
 	"strings"
 )
 
@@ -21,7 +21,7 @@ type UnparsedReceiptItem struct {
 
 type ReceiptItem struct {
 	ShortDescription string
-	Price            float64
+	Price            money.Money
 }
 
 func DefaultReceiptItem() ReceiptItem {
@@ -32,6 +32,11 @@ func DefaultReceiptItem() ReceiptItem {
 }
 
 func ParseReceiptItem(unparsedItem UnparsedReceiptItem) (ReceiptItem, error) {
+	return ParseReceiptItemIn(unparsedItem, money.DefaultCurrency)
+}
+
+// Parses the item with its price given in the currency, so the price can have as many decimal places as the currency does
+func ParseReceiptItemIn(unparsedItem UnparsedReceiptItem, currency money.Currency) (ReceiptItem, error) {
 	receiptItem := DefaultReceiptItem()
 	if strings.ReplaceAll(unparsedItem.Price, " ", "") == "" {
 		return receiptItem, ErrEmptyPriceString
@@ -39,12 +44,12 @@ func ParseReceiptItem(unparsedItem UnparsedReceiptItem) (ReceiptItem, error) {
 	receiptItem.ShortDescription = unparsedItem.ShortDescription
 
 	var parsingPriceErr error = nil
-	priceValue, err := strconv.ParseFloat(unparsedItem.Price, 64)
+	priceValue, err := money.ParseIn(unparsedItem.Price, currency)
 	if err == nil {
 		receiptItem.Price = priceValue
 	} else {
-		parsingPriceErr = fmt.Errorf("%w from \"%s\" ... %s", ErrParsingPrice, unparsedItem.Price, err.Error())
-		return receiptItem, fmt.Errorf("%w from %+v ... %s", ErrParsingReceiptItem, unparsedItem, parsingPriceErr.Error())
+		parsingPriceErr = fmt.Errorf("%w from \"%s\" ... %w", ErrParsingPrice, unparsedItem.Price, err)
+		return receiptItem, fmt.Errorf("%w from %+v ... %w", ErrParsingReceiptItem, unparsedItem, parsingPriceErr)
 	}
 
 	return receiptItem, nil
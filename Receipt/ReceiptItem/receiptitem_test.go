@@ -9,9 +9,10 @@ import (
 func Test_ParseReceiptItem(t *testing.T) {
 	var testCases []utils.CreationTestingData[UnparsedReceiptItem, ReceiptItem] = []utils.CreationTestingData[UnparsedReceiptItem, ReceiptItem]{
 		{Argument: UnparsedReceiptItem{ShortDescription: "", Price: ""}, ExpectedResult: DefaultReceiptItem(), ExpectedErr: ErrEmptyPriceString},
-		{Argument: UnparsedReceiptItem{ShortDescription: "Gatorade", Price: "2.79"}, ExpectedResult: ReceiptItem{ShortDescription: "Gatorade", Price: 2.79}, ExpectedErr: nil},
-		{Argument: UnparsedReceiptItem{ShortDescription: "Powerade", Price: "1.50"}, ExpectedResult: ReceiptItem{ShortDescription: "Powerade", Price: 1.50}, ExpectedErr: nil},
-		{Argument: UnparsedReceiptItem{ShortDescription: "Gatorade Refund", Price: "-2.79"}, ExpectedResult: ReceiptItem{ShortDescription: "Gatorade Refund", Price: -2.79}, ExpectedErr: nil},
+		{Argument: UnparsedReceiptItem{ShortDescription: "Gatorade", Price: "2.79"}, ExpectedResult: ReceiptItem{ShortDescription: "Gatorade", Price: 279}, ExpectedErr: nil},
+		{Argument: UnparsedReceiptItem{ShortDescription: "Powerade", Price: "1.50"}, ExpectedResult: ReceiptItem{ShortDescription: "Powerade", Price: 150}, ExpectedErr: nil},
+		{Argument: UnparsedReceiptItem{ShortDescription: "Gatorade Refund", Price: "-2.79"}, ExpectedResult: ReceiptItem{ShortDescription: "Gatorade Refund", Price: -279}, ExpectedErr: nil},
+		{Argument: UnparsedReceiptItem{ShortDescription: "Gatorade", Price: "2.795"}, ExpectedResult: ReceiptItem{ShortDescription: "Gatorade"}, ExpectedErr: ErrParsingPrice}, //Tests that prices with fractions of a cent are rejected
 	}
 	for _, testCase := range testCases {
 		result, err := ParseReceiptItem(testCase.Argument)
@@ -0,0 +1,46 @@
+package receipt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// The fields of a receipt that identify what was purchased, in a fixed order and with every value in a single canonical form
+type canonicalReceipt struct {
+	Retailer      string          `json:"retailer"`
+	PurchaseDate  string          `json:"purchaseDate"`
+	PurchaseTime  string          `json:"purchaseTime"`
+	TimeZone      string          `json:"timeZone"`
+	StoreTimeZone string          `json:"storeTimeZone"`
+	Currency      string          `json:"currency"`
+	Total         int64           `json:"total"`
+	Items         []canonicalItem `json:"items"`
+}
+
+type canonicalItem struct {
+	ShortDescription string `json:"shortDescription"`
+	Price            int64  `json:"price"`
+}
+
+// Returns a hex encoded SHA-256 hash of everything on the receipt except its id, so the same receipt submitted twice hashes the same however its date, time and amounts were written
+// ( i.e. "Jan 02 2022" and "2022-01-02", or "6.5" and "6.50" ).
+func (r Receipt) ContentHash() string {
+	canonical := canonicalReceipt{
+		Retailer:      r.Retailer,
+		PurchaseDate:  r.PurchaseDate.String(),
+		PurchaseTime:  fmt.Sprintf("%02d:%02d:%02d", r.PurchaseTime.Hour, r.PurchaseTime.Minute, r.PurchaseTime.Second),
+		TimeZone:      r.TimeZone.String(),
+		StoreTimeZone: r.StoreTimeZone.String(),
+		Currency:      r.Currency.Code(),
+		Total:         r.Total.Cents(),
+		Items:         make([]canonicalItem, 0, len(r.Items)),
+	}
+	for _, item := range r.Items {
+		canonical.Items = append(canonical.Items, canonicalItem{ShortDescription: item.ShortDescription, Price: item.Price.Cents()})
+	}
+	encoded, _ := json.Marshal(canonical) // only strings and integers, which always encode
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:])
+}
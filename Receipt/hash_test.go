@@ -0,0 +1,49 @@
+package receipt
+
+import (
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+)
+
+func Test_ContentHash(t *testing.T) {
+	original := UnparsedReceipt{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-02",
+		PurchaseTime: "13:01",
+		Items:        []receiptitem.UnparsedReceiptItem{{ShortDescription: "Mountain Dew 12PK", Price: "6.5"}},
+		Total:        "6.50",
+	}
+	rewritten := original
+	rewritten.PurchaseDate = "Jan 02 2022"
+	rewritten.PurchaseTime = "1:01 PM"
+	rewritten.Items = []receiptitem.UnparsedReceiptItem{{ShortDescription: "Mountain Dew 12PK", Price: "6.50"}}
+	rewritten.Total = "6.5"
+	rewritten.Currency = "USD"
+	otherDay := original
+	otherDay.PurchaseDate = "2022-01-03"
+	otherItem := original
+	otherItem.Items = []receiptitem.UnparsedReceiptItem{{ShortDescription: "Mountain Dew 24PK", Price: "6.50"}}
+	otherZone := original
+	otherZone.TimeZone = "America/Chicago"
+
+	// each argument is compared against the original receipt, and the expected result is whether the two hash the same
+	var testCases []utils.CreationTestingData[UnparsedReceipt, bool] = []utils.CreationTestingData[UnparsedReceipt, bool]{
+		{Argument: original, ExpectedResult: true},
+		{Argument: rewritten, ExpectedResult: true},
+		{Argument: otherDay, ExpectedResult: false},
+		{Argument: otherItem, ExpectedResult: false},
+		{Argument: otherZone, ExpectedResult: false},
+	}
+	originalReceipt, err := ParseReceipt("original", original, true)
+	if err != nil {
+		t.Fatalf("parsing original receipt: unexpected error ( %v )", err)
+	}
+	for _, testCase := range testCases {
+		parsedReceipt, err := ParseReceipt("other", testCase.Argument, true)
+		errCheck := testCase.CheckTestCase("content hash", parsedReceipt.ContentHash() == originalReceipt.ContentHash(), err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
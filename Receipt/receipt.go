@@ -1,12 +1,13 @@
 package receipt
 
 import (
+	"errors"
+	"fmt"
 	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
 	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
 	time "go-receipt-processor/Time"
-	"errors"
-	"fmt"
-	"strconv"
+	"strings"
 )
 
 var (
@@ -15,26 +16,81 @@ var (
 	ErrParsingReceipt     error = errors.New("parsing receipt")
 	ErrParsingReceiptItem error = errors.New("parsing receipt")
 	ErrParsingTotal       error = errors.New("parsing receipt total")
+	ErrParsingCurrency    error = errors.New("parsing receipt currency")
+	ErrParsingTimeZone    error = errors.New("parsing receipt time zone")
 	ErrInvalidTotal       error = errors.New("invalid receipt total")
 	ErrInvalidReceipt     error = errors.New("invalid receipt")
 	// all invalid syntax provided will be considered a parsing error
 )
 
+// Associates an error with the field of the unparsed receipt that caused it, using the same names the receipt is submitted with ( i.e. "purchaseDate" or "items[2].price" ).
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err.Error())
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Walks the tree of wrapped and joined errors, returning every FieldError in it in the order the fields were checked
+func FieldErrors(err error) []*FieldError {
+	if fieldErr, isFieldErr := err.(*FieldError); isFieldErr {
+		return []*FieldError{fieldErr}
+	}
+
+	fieldErrs := []*FieldError{}
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapped.Unwrap() {
+			fieldErrs = append(fieldErrs, FieldErrors(inner)...)
+		}
+	case interface{ Unwrap() error }:
+		if inner := wrapped.Unwrap(); inner != nil {
+			fieldErrs = append(fieldErrs, FieldErrors(inner)...)
+		}
+	}
+	return fieldErrs
+}
+
+// Wraps the error in a FieldError for the field, leaving nil errors as nil so the results can be passed directly to errors.Join
+func newFieldError(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FieldError{Field: field, Err: err}
+}
+
 type UnparsedReceipt struct {
-	Retailer     string
-	PurchaseDate string
-	PurchaseTime string
-	Items        []receiptitem.UnparsedReceiptItem
-	Total        string
+	Retailer      string
+	PurchaseDate  string
+	PurchaseTime  string
+	Items         []receiptitem.UnparsedReceiptItem
+	Total         string
+	Currency      string // optional ISO 4217 code, receipts without one are in the default currency
+	TimeZone      string // optional IANA zone or UTC offset the purchase date and time are written in
+	StoreTimeZone string // optional zone of the store, when it differs from the one the purchase date and time are written in
 }
 
 type Receipt struct {
-	Id           string
-	Retailer     string
-	PurchaseDate date.Date
-	PurchaseTime time.Time
-	Items        []receiptitem.ReceiptItem
-	Total        float64
+	Id            string
+	Retailer      string
+	PurchaseDate  date.Date
+	PurchaseTime  time.Time
+	Items         []receiptitem.ReceiptItem
+	Total         money.Money
+	Currency      money.Currency
+	TimeZone      time.Zone
+	StoreTimeZone time.Zone // the zone the points rules evaluate the purchase date and time in, the same as TimeZone unless one was given
+}
+
+// Returns the purchase date and time as they were on the clock at the store, converting them from the zone they were written in when the two differ
+func (r Receipt) LocalPurchaseDateTime() (date.Date, time.Time) {
+	return time.Convert(r.PurchaseDate, r.PurchaseTime, r.TimeZone, r.StoreTimeZone)
 }
 
 func (r Receipt) isValid() error {
@@ -43,52 +99,84 @@ func (r Receipt) isValid() error {
 	timeValidation := r.PurchaseTime.IsValid()
 
 	calculatedTotal := calculateSumOfReceiptItems(r.Items)
-	diff := r.Total - calculatedTotal
 	var totalValidation error = nil
-	if diff >= 0.001 || diff <= -0.001 {
-		totalValidation = fmt.Errorf("%w ... calculated total, %f, does not match parsed total, %f", ErrInvalidTotal, calculatedTotal, r.Total)
+	if calculatedTotal != r.Total { // amounts are exact, so there is no tolerance for rounding error
+		totalValidation = fmt.Errorf("%w ... calculated total, %s, does not match parsed total, %s", ErrInvalidTotal, calculatedTotal.Format(r.Currency), r.Total.Format(r.Currency))
 	}
 
-	joinedValidationErr := errors.Join(dateValidation, timeValidation, totalValidation)
+	joinedValidationErr := errors.Join(newFieldError("purchaseDate", dateValidation), newFieldError("purchaseTime", timeValidation), newFieldError("total", totalValidation))
 	if joinedValidationErr != nil {
-		return fmt.Errorf("%w given %+v ... %v", ErrInvalidReceipt, r, joinedValidationErr)
+		return fmt.Errorf("%w given %+v ... %w", ErrInvalidReceipt, r, joinedValidationErr)
 	}
 	return nil
 }
 
-func calculateSumOfReceiptItems(items []receiptitem.ReceiptItem) float64 {
-	var total float64 = 0.0
+func calculateSumOfReceiptItems(items []receiptitem.ReceiptItem) money.Money {
+	var total money.Money = 0
 	for _, item := range items {
-		total += item.Price
+		total = total.Add(item.Price)
 	}
 	return total
 }
 
+// The layouts the purchase date and time of a receipt are accepted in
+type Formats struct {
+	Date date.Formats
+	Time time.Formats
+}
+
+// Accepts every date and time layout, i.e. "10/15/2023" and "3:30 PM" as well as "2023-10-15" and "15:30"
+var DefaultFormats = Formats{Date: date.DefaultFormats, Time: time.DefaultFormats}
+
 func ParseReceipt(id string, unparsedReceipt UnparsedReceipt, validateResults bool) (Receipt, error) {
+	return ParseReceiptWith(id, unparsedReceipt, DefaultFormats, validateResults)
+}
+
+// Parses the receipt, accepting its purchase date and time in the given layouts
+func ParseReceiptWith(id string, unparsedReceipt UnparsedReceipt, formats Formats, validateResults bool) (Receipt, error) {
 	receipt := Receipt{
 		Id:       id,
 		Retailer: unparsedReceipt.Retailer,
 	}
-	purchaseDate, purchaseDateErr := date.ParseDate(unparsedReceipt.PurchaseDate, false)
+	purchaseDate, purchaseDateErr := formats.Date.Parse(unparsedReceipt.PurchaseDate, false)
 	receipt.PurchaseDate = purchaseDate
 
-	purchaseTime, purchaseTimeErr := time.ParseTime(unparsedReceipt.PurchaseTime, false)
+	purchaseTime, purchaseTimeErr := formats.Time.Parse(unparsedReceipt.PurchaseTime, false)
 	receipt.PurchaseTime = purchaseTime
 
-	receiptItems, receiptItemsErr := ParseAllReceiptItems(unparsedReceipt.Items)
+	var parseCurrencyErr error = nil
+	receipt.Currency = money.DefaultCurrency
+	if strings.Trim(unparsedReceipt.Currency, " ") != "" {
+		currency, err := money.ParseCurrency(unparsedReceipt.Currency)
+		if err != nil {
+			parseCurrencyErr = fmt.Errorf("%w ... %w", ErrParsingCurrency, err)
+		} else {
+			receipt.Currency = currency
+		}
+	}
+
+	timeZone, parseTimeZoneErr := parseOptionalZone(unparsedReceipt.TimeZone)
+	receipt.TimeZone = timeZone
+	storeTimeZone, parseStoreTimeZoneErr := parseOptionalZone(unparsedReceipt.StoreTimeZone)
+	receipt.StoreTimeZone = storeTimeZone
+	if storeTimeZone.IsZero() {
+		receipt.StoreTimeZone = timeZone
+	}
+
+	receiptItems, receiptItemsErr := ParseAllReceiptItems(unparsedReceipt.Items, receipt.Currency)
 	receipt.Items = receiptItems
 
 	var parseTotalErr error = nil
-	total, err := strconv.ParseFloat(unparsedReceipt.Total, 64)
+	total, err := money.ParseIn(unparsedReceipt.Total, receipt.Currency)
 	if err != nil {
-		parseTotalErr = fmt.Errorf("%w given \"%s\" ... %s", ErrParsingTotal, unparsedReceipt.Total, err.Error())
+		parseTotalErr = fmt.Errorf("%w given \"%s\" ... %w", ErrParsingTotal, unparsedReceipt.Total, err)
 	} else {
 		receipt.Total = total
 	}
 
-	joinedErrs := errors.Join(purchaseDateErr, purchaseTimeErr, receiptItemsErr, parseTotalErr)
+	joinedErrs := errors.Join(newFieldError("purchaseDate", purchaseDateErr), newFieldError("purchaseTime", purchaseTimeErr), receiptItemsErr, newFieldError("total", parseTotalErr), newFieldError("currency", parseCurrencyErr), newFieldError("timeZone", parseTimeZoneErr), newFieldError("storeTimeZone", parseStoreTimeZoneErr))
 	if joinedErrs != nil {
-		return receipt, fmt.Errorf("( %s ) %w from %+v ... %s", id, ErrParsingReceipt, unparsedReceipt, joinedErrs.Error())
+		return receipt, fmt.Errorf("( %s ) %w from %+v ... %w", id, ErrParsingReceipt, unparsedReceipt, joinedErrs)
 	}
 	if validateResults {
 		return receipt, receipt.isValid()
@@ -96,15 +184,27 @@ func ParseReceipt(id string, unparsedReceipt UnparsedReceipt, validateResults bo
 	return receipt, nil
 }
 
-func ParseAllReceiptItems(unparsedItems []receiptitem.UnparsedReceiptItem) ([]receiptitem.ReceiptItem, error) {
+// Parses a time zone that is allowed to be left out, returning the zero zone when it is
+func parseOptionalZone(zoneString string) (time.Zone, error) {
+	if strings.Trim(zoneString, " ") == "" {
+		return "", nil
+	}
+	zone, err := time.ParseZone(zoneString)
+	if err != nil {
+		return "", fmt.Errorf("%w ... %w", ErrParsingTimeZone, err)
+	}
+	return zone, nil
+}
+
+func ParseAllReceiptItems(unparsedItems []receiptitem.UnparsedReceiptItem, currency money.Currency) ([]receiptitem.ReceiptItem, error) {
 	parsedItems := []receiptitem.ReceiptItem{}
 	errs := []error{}
-	for _, item := range unparsedItems {
-		parsedItem, err := receiptitem.ParseReceiptItem(item)
+	for i, item := range unparsedItems {
+		parsedItem, err := receiptitem.ParseReceiptItemIn(item, currency)
 		if err == nil {
 			parsedItems = append(parsedItems, parsedItem)
 		} else {
-			errs = append(errs, err)
+			errs = append(errs, newFieldError(fmt.Sprintf("items[%d].price", i), err)) // the price is the only part of an item that can fail to parse
 		}
 	}
 	if len(errs) > 0 {
@@ -1,7 +1,9 @@
 package receipt
 
 import (
+	"errors"
 	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
 	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
 	utils "go-receipt-processor/TestingUtils"
 	time "go-receipt-processor/Time"
@@ -32,13 +34,14 @@ func Test_ParseReceipt(t *testing.T) {
 				Retailer:     "Target",
 				PurchaseDate: date.Date{Year: 2022, Month: 01, Day: 01},
 				PurchaseTime: time.Time{Hour: 13, Minute: 01},
-				Total:        35.35,
+				Total:        3535,
+				Currency:     money.USD,
 				Items: []receiptitem.ReceiptItem{
-					{ShortDescription: "Mountain Dew 12PK", Price: 6.49},
-					{ShortDescription: "Emils Cheese Pizza", Price: 12.25},
-					{ShortDescription: "Knorr Creamy Chicken", Price: 1.26},
-					{ShortDescription: "Doritos Nacho Cheese", Price: 3.35},
-					{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: 12.00},
+					{ShortDescription: "Mountain Dew 12PK", Price: 649},
+					{ShortDescription: "Emils Cheese Pizza", Price: 1225},
+					{ShortDescription: "Knorr Creamy Chicken", Price: 126},
+					{ShortDescription: "Doritos Nacho Cheese", Price: 335},
+					{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: 1200},
 				}},
 		},
 		{
@@ -60,12 +63,13 @@ func Test_ParseReceipt(t *testing.T) {
 				Retailer:     "M&M Corner Market",
 				PurchaseDate: date.Date{Year: 2022, Month: 03, Day: 20},
 				PurchaseTime: time.Time{Hour: 14, Minute: 33},
-				Total:        9.00,
+				Total:        900,
+				Currency:     money.USD,
 				Items: []receiptitem.ReceiptItem{
-					{ShortDescription: "Gatorade", Price: 2.25},
-					{ShortDescription: "Gatorade", Price: 2.25},
-					{ShortDescription: "Gatorade", Price: 2.25},
-					{ShortDescription: "Gatorade", Price: 2.25},
+					{ShortDescription: "Gatorade", Price: 225},
+					{ShortDescription: "Gatorade", Price: 225},
+					{ShortDescription: "Gatorade", Price: 225},
+					{ShortDescription: "Gatorade", Price: 225},
 				}},
 		},
 		{
@@ -85,12 +89,13 @@ func Test_ParseReceipt(t *testing.T) {
 				Retailer:     "Walmart",
 				PurchaseDate: date.Date{Year: 2024, Month: 2, Day: 29},
 				PurchaseTime: time.Time{Hour: 14, Minute: 30},
-				Total:        10.37,
+				Total:        1037,
+				Currency:     money.USD,
 				Items: []receiptitem.ReceiptItem{
-					{ShortDescription: "Gatorade", Price: 2.25},
-					{ShortDescription: "Banana", Price: 1.12},
-					{ShortDescription: "Sandwich", Price: 5.50},
-					{ShortDescription: "Chips", Price: 1.50},
+					{ShortDescription: "Gatorade", Price: 225},
+					{ShortDescription: "Banana", Price: 112},
+					{ShortDescription: "Sandwich", Price: 550},
+					{ShortDescription: "Chips", Price: 150},
 				}},
 		},
 		{
@@ -109,11 +114,12 @@ func Test_ParseReceipt(t *testing.T) {
 				Retailer:     "Target",
 				PurchaseDate: date.Date{Year: 2021, Month: 04, Day: 05},
 				PurchaseTime: time.Time{Hour: 12, Minute: 0},
-				Total:        8.03,
+				Total:        803,
+				Currency:     money.USD,
 				Items: []receiptitem.ReceiptItem{
-					{ShortDescription: "Water", Price: 1.00},
-					{ShortDescription: "Apple", Price: 0.75},
-					{ShortDescription: "Sandwich", Price: 6.28},
+					{ShortDescription: "Water", Price: 100},
+					{ShortDescription: "Apple", Price: 75},
+					{ShortDescription: "Sandwich", Price: 628},
 				}},
 		},
 		{
@@ -131,9 +137,10 @@ func Test_ParseReceipt(t *testing.T) {
 				Retailer:     "Best Buy",
 				PurchaseDate: date.Date{Year: 2012, Month: 11, Day: 20},
 				PurchaseTime: time.Time{Hour: 15, Minute: 45},
-				Total:        50.00,
+				Total:        5000,
+				Currency:     money.USD,
 				Items: []receiptitem.ReceiptItem{
-					{ShortDescription: "Headphones", Price: 50.00},
+					{ShortDescription: "Headphones", Price: 5000},
 				},
 			},
 		},
@@ -153,10 +160,11 @@ func Test_ParseReceipt(t *testing.T) {
 				Retailer:     "Costco",
 				PurchaseDate: date.Date{Year: 1999, Month: 9, Day: 30},
 				PurchaseTime: time.Time{Hour: 17, Minute: 30},
-				Total:        25.50,
+				Total:        2550,
+				Currency:     money.USD,
 				Items: []receiptitem.ReceiptItem{
-					{ShortDescription: "Toilet Paper", Price: 15.00},
-					{ShortDescription: "Paper Towels", Price: 10.50},
+					{ShortDescription: "Toilet Paper", Price: 1500},
+					{ShortDescription: "Paper Towels", Price: 1050},
 				},
 			},
 		},
@@ -176,11 +184,12 @@ func Test_ParseReceipt(t *testing.T) {
 				Retailer:     "Walmart",
 				PurchaseDate: date.Date{Year: 2024, Month: 2, Day: 29},
 				PurchaseTime: time.Time{Hour: 14, Minute: 30},
-				Total:        10.00,
+				Total:        1000,
+				Currency:     money.USD,
 				Items: []receiptitem.ReceiptItem{
-					{ShortDescription: "Gatorade", Price: 2.25},
-					{ShortDescription: "Banana", Price: 1.12},
-					{ShortDescription: "Sandwich", Price: 5.50},
+					{ShortDescription: "Gatorade", Price: 225},
+					{ShortDescription: "Banana", Price: 112},
+					{ShortDescription: "Sandwich", Price: 550},
 				}},
 			ExpectedErr: ErrInvalidReceipt,
 		},
@@ -200,10 +209,11 @@ func Test_ParseReceipt(t *testing.T) {
 				Retailer:     "Costco",
 				PurchaseDate: date.Date{Year: 1999, Month: 9, Day: 30},
 				PurchaseTime: time.Time{},
-				Total:        25.50,
+				Total:        2550,
+				Currency:     money.USD,
 				Items: []receiptitem.ReceiptItem{
-					{ShortDescription: "Toilet Paper", Price: 15.00},
-					{ShortDescription: "Paper Towels", Price: 10.50},
+					{ShortDescription: "Toilet Paper", Price: 1500},
+					{ShortDescription: "Paper Towels", Price: 1050},
 				},
 			},
 			ExpectedErr: ErrParsingReceipt,
@@ -224,11 +234,152 @@ func Test_ParseReceipt(t *testing.T) {
 				Retailer:     "Walmart",
 				PurchaseDate: date.Date{Year: 2024, Month: 2, Day: 29},
 				PurchaseTime: time.Time{Hour: 14, Minute: 30},
-				Total:        8.87,
+				Total:        887,
+				Currency:     money.USD,
 				Items: []receiptitem.ReceiptItem{
-					{ShortDescription: "Gatorade", Price: 2.25},
-					{ShortDescription: "Banana", Price: 1.12},
-					{ShortDescription: "Sandwich", Price: 5.50},
+					{ShortDescription: "Gatorade", Price: 225},
+					{ShortDescription: "Banana", Price: 112},
+					{ShortDescription: "Sandwich", Price: 550},
+				}},
+			ExpectedErr: nil,
+		},
+		{ //Tests that totals are compared exactly, 0.10 + 0.20 is not exactly 0.30 as a float64
+			Argument: UnparsedReceipt{
+				Retailer:     "Walgreens",
+				PurchaseDate: "2022-01-02",
+				PurchaseTime: "08:13",
+				Total:        "0.30",
+				Items: []receiptitem.UnparsedReceiptItem{
+					{ShortDescription: "Gum", Price: "0.10"},
+					{ShortDescription: "Mints", Price: "0.20"},
+				}},
+			ExpectedResult: Receipt{
+				Id:           "9",
+				Retailer:     "Walgreens",
+				PurchaseDate: date.Date{Year: 2022, Month: 1, Day: 2},
+				PurchaseTime: time.Time{Hour: 8, Minute: 13},
+				Total:        30,
+				Currency:     money.USD,
+				Items: []receiptitem.ReceiptItem{
+					{ShortDescription: "Gum", Price: 10},
+					{ShortDescription: "Mints", Price: 20},
+				}},
+			ExpectedErr: nil,
+		},
+		{ //Tests a receipt in euros, written with decimal commas
+			Argument: UnparsedReceipt{
+				Retailer:     "Lidl",
+				PurchaseDate: "2023-05-11",
+				PurchaseTime: "10:15",
+				Total:        "1.012,50",
+				Currency:     "eur",
+				Items: []receiptitem.UnparsedReceiptItem{
+					{ShortDescription: "Espresso Machine", Price: "999,99"},
+					{ShortDescription: "Coffee", Price: "12,51"},
+				}},
+			ExpectedResult: Receipt{
+				Id:           "10",
+				Retailer:     "Lidl",
+				PurchaseDate: date.Date{Year: 2023, Month: 5, Day: 11},
+				PurchaseTime: time.Time{Hour: 10, Minute: 15},
+				Total:        101250,
+				Currency:     money.EUR,
+				Items: []receiptitem.ReceiptItem{
+					{ShortDescription: "Espresso Machine", Price: 99999},
+					{ShortDescription: "Coffee", Price: 1251},
+				}},
+			ExpectedErr: nil,
+		},
+		{ //Tests a receipt in yen, which has no minor unit
+			Argument: UnparsedReceipt{
+				Retailer:     "Lawson",
+				PurchaseDate: "2023-05-11",
+				PurchaseTime: "10:15",
+				Total:        "1500",
+				Currency:     "JPY",
+				Items: []receiptitem.UnparsedReceiptItem{
+					{ShortDescription: "Onigiri", Price: "150"},
+					{ShortDescription: "Bento", Price: "1350"},
+				}},
+			ExpectedResult: Receipt{
+				Id:           "11",
+				Retailer:     "Lawson",
+				PurchaseDate: date.Date{Year: 2023, Month: 5, Day: 11},
+				PurchaseTime: time.Time{Hour: 10, Minute: 15},
+				Total:        1500,
+				Currency:     money.JPY,
+				Items: []receiptitem.ReceiptItem{
+					{ShortDescription: "Onigiri", Price: 150},
+					{ShortDescription: "Bento", Price: 1350},
+				}},
+			ExpectedErr: nil,
+		},
+		{ //Tests that amounts with more decimal places than the currency has are caught
+			Argument: UnparsedReceipt{
+				Retailer:     "Lawson",
+				PurchaseDate: "2023-05-11",
+				PurchaseTime: "10:15",
+				Total:        "1500.50",
+				Currency:     "JPY",
+				Items: []receiptitem.UnparsedReceiptItem{
+					{ShortDescription: "Bento", Price: "1500.50"},
+				}},
+			ExpectedResult: Receipt{
+				Id:           "12",
+				Retailer:     "Lawson",
+				PurchaseDate: date.Date{Year: 2023, Month: 5, Day: 11},
+				PurchaseTime: time.Time{Hour: 10, Minute: 15},
+				Currency:     money.JPY,
+				Items:        []receiptitem.ReceiptItem{},
+			},
+			ExpectedErr: ErrParsingReceipt,
+		},
+		{ //Tests that the store's zone defaults to the zone the purchase date and time are written in
+			Argument: UnparsedReceipt{
+				Retailer:     "Target",
+				PurchaseDate: "2022-01-01",
+				PurchaseTime: "13:01",
+				Total:        "2.25",
+				TimeZone:     "America/Chicago",
+				Items: []receiptitem.UnparsedReceiptItem{
+					{ShortDescription: "Gatorade", Price: "2.25"},
+				}},
+			ExpectedResult: Receipt{
+				Id:            "13",
+				Retailer:      "Target",
+				PurchaseDate:  date.Date{Year: 2022, Month: 1, Day: 1},
+				PurchaseTime:  time.Time{Hour: 13, Minute: 1},
+				Total:         225,
+				Currency:      money.USD,
+				TimeZone:      "America/Chicago",
+				StoreTimeZone: "America/Chicago",
+				Items: []receiptitem.ReceiptItem{
+					{ShortDescription: "Gatorade", Price: 225},
+				}},
+			ExpectedErr: nil,
+		},
+		{ //Tests that UTC offsets are normalized
+			Argument: UnparsedReceipt{
+				Retailer:      "Target",
+				PurchaseDate:  "2022-01-01",
+				PurchaseTime:  "13:01",
+				Total:         "2.25",
+				TimeZone:      "Z",
+				StoreTimeZone: "-0500",
+				Items: []receiptitem.UnparsedReceiptItem{
+					{ShortDescription: "Gatorade", Price: "2.25"},
+				}},
+			ExpectedResult: Receipt{
+				Id:            "14",
+				Retailer:      "Target",
+				PurchaseDate:  date.Date{Year: 2022, Month: 1, Day: 1},
+				PurchaseTime:  time.Time{Hour: 13, Minute: 1},
+				Total:         225,
+				Currency:      money.USD,
+				TimeZone:      time.UTC,
+				StoreTimeZone: "-05:00",
+				Items: []receiptitem.ReceiptItem{
+					{ShortDescription: "Gatorade", Price: 225},
 				}},
 			ExpectedErr: nil,
 		},
@@ -241,3 +392,57 @@ func Test_ParseReceipt(t *testing.T) {
 		}
 	}
 }
+
+type localPurchaseDateTime struct {
+	Date date.Date
+	Time time.Time
+}
+
+func Test_LocalPurchaseDateTime(t *testing.T) {
+	var testCases []utils.CreationTestingData[Receipt, localPurchaseDateTime] = []utils.CreationTestingData[Receipt, localPurchaseDateTime]{
+		{ //Tests that a receipt without zones is left as it was written
+			Argument:       Receipt{PurchaseDate: date.Date{Year: 2023, Month: 10, Day: 15}, PurchaseTime: time.Time{Hour: 1, Minute: 30}},
+			ExpectedResult: localPurchaseDateTime{Date: date.Date{Year: 2023, Month: 10, Day: 15}, Time: time.Time{Hour: 1, Minute: 30}},
+		},
+		{ //Tests that a purchase written in UTC is moved to the previous day at a store in Chicago
+			Argument:       Receipt{PurchaseDate: date.Date{Year: 2023, Month: 10, Day: 15}, PurchaseTime: time.Time{Hour: 1, Minute: 30}, TimeZone: time.UTC, StoreTimeZone: "America/Chicago"},
+			ExpectedResult: localPurchaseDateTime{Date: date.Date{Year: 2023, Month: 10, Day: 14}, Time: time.Time{Hour: 20, Minute: 30}},
+		},
+	}
+	for _, testCase := range testCases {
+		resultDate, resultTime := testCase.Argument.LocalPurchaseDateTime()
+		errCheck := testCase.CheckTestCase("local purchase date and time", localPurchaseDateTime{Date: resultDate, Time: resultTime}, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_ParseReceiptFieldErrors(t *testing.T) {
+	validItems := []receiptitem.UnparsedReceiptItem{{ShortDescription: "Gatorade", Price: "2.25"}, {ShortDescription: "Gatorade", Price: "2.25"}}
+	var testCases []utils.CreationTestingData[UnparsedReceipt, string] = []utils.CreationTestingData[UnparsedReceipt, string]{
+		{Argument: UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-0a", PurchaseTime: "13:01", Total: "4.50", Items: validItems}, ExpectedResult: "purchaseDate", ExpectedErr: ErrParsingReceipt},
+		{Argument: UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "25:01", Total: "4.50", Items: validItems}, ExpectedResult: "purchaseTime", ExpectedErr: ErrInvalidReceipt},
+		{Argument: UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Total: "4.5.0", Items: validItems}, ExpectedResult: "total", ExpectedErr: ErrParsingTotal},
+		{Argument: UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Total: "4.501", Items: validItems}, ExpectedResult: "total", ExpectedErr: ErrParsingTotal},
+		{Argument: UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Total: "4.50", Currency: "dollars", Items: validItems}, ExpectedResult: "currency", ExpectedErr: ErrParsingCurrency},
+		{Argument: UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Total: "4.50", TimeZone: "America/Springfield", Items: validItems}, ExpectedResult: "timeZone", ExpectedErr: ErrParsingTimeZone},
+		{Argument: UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Total: "4.50", StoreTimeZone: "+25:00", Items: validItems}, ExpectedResult: "storeTimeZone", ExpectedErr: ErrParsingTimeZone},
+		{Argument: UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Total: "4.00", Items: validItems}, ExpectedResult: "total", ExpectedErr: ErrInvalidTotal},
+		{Argument: UnparsedReceipt{Retailer: "Target", PurchaseDate: "2022-01-01", PurchaseTime: "13:01", Total: "4.50",
+			Items: []receiptitem.UnparsedReceiptItem{{ShortDescription: "Gatorade", Price: "2.25"}, {ShortDescription: "Gatorade", Price: "two"}}},
+			ExpectedResult: "items[1].price", ExpectedErr: receiptitem.ErrParsingPrice},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseReceipt("0", testCase.Argument, true)
+		field := ""
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			field = fieldErr.Field
+		}
+		errCheck := testCase.CheckTestCase("parse receipt field errors", field, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
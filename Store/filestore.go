@@ -0,0 +1,464 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	logFileName      = "receipts.wal"
+	snapshotFileName = "receipts.snapshot"
+
+	// every record on disk is prefixed by the length of its payload and the CRC32 checksum of the payload, 4 bytes each
+	recordHeaderSize = 8
+
+	operationPut      = "put"
+	operationDelete   = "delete"
+	operationReplace  = "replace"
+	operationRevision = "revision" // a single earlier version of a receipt, written to snapshots so histories survive compaction
+	operationSnapshot = "snapshot" // the first record of a snapshot, holding the sequence number of the last log record it includes
+)
+
+var (
+	ErrCorruptRecord   = errors.New("corrupt write-ahead log record")
+	ErrCorruptLog      = errors.New("corrupt record followed by complete records")
+	ErrOpeningStore    = errors.New("opening file store")
+	ErrWritingLog      = errors.New("writing to write-ahead log")
+	ErrCompactingStore = errors.New("compacting file store")
+	ErrStoreClosed     = errors.New("file store is closed")
+)
+
+// A single entry in the write-ahead log or snapshot
+type logRecord struct {
+	Operation     string           `json:"op"`
+	StoredReceipt *StoredReceipt   `json:"storedReceipt,omitempty"`
+	Id            string           `json:"id,omitempty"`
+	RevisedAt     *time.Time       `json:"revisedAt,omitempty"` // when the replaced or deleted version was revised, missing from deletes logged before histories were kept
+	Revision      *ReceiptRevision `json:"revision,omitempty"`
+	Sequence      int64            `json:"seq,omitempty"` // numbers every record appended to the log, missing from records logged before sequence numbers were kept
+}
+
+// Optional configuration applied when opening a FileStore
+type FileStoreOption func(*FileStore)
+
+// Sets how often the write-ahead log is compacted into a snapshot in the background. A zero or negative interval disables background compaction.
+func WithCompactionInterval(interval time.Duration) FileStoreOption {
+	return func(s *FileStore) {
+		s.compactionInterval = interval
+	}
+}
+
+// ReceiptStore that keeps every receipt in memory and appends each change to a write-ahead log on disk before applying it.
+// On open, the latest snapshot is loaded and the log is replayed on top of it, so processed receipts survive a restart.
+// A log that ends in a partially written record ( i.e. the process crashed mid-write ) is truncated back to the last complete record.
+type FileStore struct {
+	directory          string
+	compactionInterval time.Duration
+
+	memory *MemoryStore
+
+	mutex    sync.Mutex // serializes writes to the log file and compaction
+	logFile  *os.File
+	sequence int64 // the sequence number of the last record appended to the log or included in the snapshot
+	closed   bool
+
+	stopCompaction chan struct{}
+	compactionDone chan struct{}
+}
+
+func OpenFileStore(directory string, options ...FileStoreOption) (*FileStore, error) {
+	s := &FileStore{
+		directory:          directory,
+		compactionInterval: 5 * time.Minute,
+		memory:             NewMemoryStore(),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningStore, directory, err)
+	}
+
+	if _, err := s.replayFile(s.snapshotPath(), false); err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningStore, directory, err)
+	}
+	validLength, err := s.replayFile(s.logPath(), true)
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningStore, directory, err)
+	}
+
+	logFile, err := os.OpenFile(s.logPath(), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningStore, directory, err)
+	}
+	// drops any partially written record left behind by a crash, so new records are appended after the last complete one
+	if err := logFile.Truncate(validLength); err != nil {
+		logFile.Close()
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningStore, directory, err)
+	}
+	if _, err := logFile.Seek(validLength, io.SeekStart); err != nil {
+		logFile.Close()
+		return nil, fmt.Errorf("%w given \"%s\" ... %w", ErrOpeningStore, directory, err)
+	}
+	s.logFile = logFile
+
+	if s.compactionInterval > 0 {
+		s.stopCompaction = make(chan struct{})
+		s.compactionDone = make(chan struct{})
+		go s.compactPeriodically()
+	}
+	return s, nil
+}
+
+func (s *FileStore) logPath() string {
+	return filepath.Join(s.directory, logFileName)
+}
+
+func (s *FileStore) snapshotPath() string {
+	return filepath.Join(s.directory, snapshotFileName)
+}
+
+// Applies every complete record in the file to the in-memory state and returns the length of the file up to the end of the last complete record.
+// When allowTornTail is set, a corrupt record is accepted as the end of the file if no complete record follows it, since only the record being appended when the process crashed can be torn.
+// Any other corrupt record returns ErrCorruptLog, so the file is left for inspection instead of being cut short. A missing file is treated as empty.
+// Records numbered at or below the sequence already reached are skipped, since a crash part way through compaction can leave records in the log that the snapshot already includes.
+func (s *FileStore) replayFile(path string, allowTornTail bool) (int64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	reader := bytes.NewReader(data)
+	var validLength int64 = 0
+	for {
+		record, recordLength, err := readRecord(reader)
+		if err == io.EOF {
+			return validLength, nil
+		} else if errors.Is(err, ErrCorruptRecord) {
+			if allowTornTail && !containsCompleteRecord(data[validLength+1:]) {
+				return validLength, nil
+			}
+			return validLength, fmt.Errorf("%w at byte %d of \"%s\" ... %w", ErrCorruptLog, validLength, path, err)
+		} else if err != nil {
+			return validLength, err
+		}
+		validLength += recordLength
+		if record.Sequence > 0 {
+			if record.Sequence <= s.sequence {
+				continue
+			}
+			s.sequence = record.Sequence
+		}
+		if err := s.apply(record); err != nil {
+			return validLength, err
+		}
+	}
+}
+
+// Reports whether a record with a matching checksum and a valid json payload starts anywhere in the data
+func containsCompleteRecord(data []byte) bool {
+	for start := 0; start+recordHeaderSize <= len(data); start++ {
+		payloadLength := int(binary.LittleEndian.Uint32(data[start : start+4]))
+		payloadStart := start + recordHeaderSize
+		if payloadLength > len(data)-payloadStart {
+			continue
+		}
+		payload := data[payloadStart : payloadStart+payloadLength]
+		if crc32.ChecksumIEEE(payload) == binary.LittleEndian.Uint32(data[start+4:start+8]) && json.Valid(payload) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *FileStore) apply(record logRecord) error {
+	switch record.Operation {
+	case operationPut:
+		if record.StoredReceipt == nil {
+			return fmt.Errorf("%w put record is missing its receipt", ErrCorruptRecord)
+		}
+		return s.memory.Put(*record.StoredReceipt)
+	case operationDelete:
+		_, err := s.memory.delete(record.Id, record.revisedAt())
+		if errors.Is(err, ErrReceiptNotFound) {
+			return nil // the receipt may have already been removed before the last compaction
+		}
+		return err
+	case operationReplace:
+		if record.StoredReceipt == nil {
+			return fmt.Errorf("%w replace record is missing its receipt", ErrCorruptRecord)
+		}
+		_, _, err := s.memory.replace(*record.StoredReceipt, record.revisedAt())
+		return err
+	case operationRevision:
+		if record.Revision == nil {
+			return fmt.Errorf("%w revision record is missing its revision", ErrCorruptRecord)
+		}
+		s.memory.addRevision(record.Id, *record.Revision)
+		return nil
+	case operationSnapshot:
+		return nil // only carries its sequence number
+	default:
+		return fmt.Errorf("%w unknown operation \"%s\"", ErrCorruptRecord, record.Operation)
+	}
+}
+
+func (record logRecord) revisedAt() time.Time {
+	if record.RevisedAt == nil {
+		return time.Time{}
+	}
+	return *record.RevisedAt
+}
+
+// Reads a single record, returning io.EOF if the reader is exhausted cleanly and ErrCorruptRecord if the record is incomplete or fails its checksum.
+func readRecord(reader io.Reader) (logRecord, int64, error) {
+	record := logRecord{}
+	header := make([]byte, recordHeaderSize)
+	n, err := io.ReadFull(reader, header)
+	if err == io.EOF {
+		return record, 0, io.EOF
+	} else if err != nil {
+		return record, 0, fmt.Errorf("%w incomplete header ( read %d of %d bytes )", ErrCorruptRecord, n, recordHeaderSize)
+	}
+	payloadLength := binary.LittleEndian.Uint32(header[0:4])
+	checksum := binary.LittleEndian.Uint32(header[4:8])
+
+	payload := make([]byte, payloadLength)
+	n, err = io.ReadFull(reader, payload)
+	if err != nil {
+		return record, 0, fmt.Errorf("%w incomplete payload ( read %d of %d bytes )", ErrCorruptRecord, n, payloadLength)
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return record, 0, fmt.Errorf("%w checksum mismatch", ErrCorruptRecord)
+	}
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return record, 0, fmt.Errorf("%w ... %w", ErrCorruptRecord, err)
+	}
+	return record, int64(recordHeaderSize + len(payload)), nil
+}
+
+func writeRecord(writer io.Writer, record logRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	buffer := make([]byte, recordHeaderSize, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(buffer[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buffer[4:8], crc32.ChecksumIEEE(payload))
+	buffer = append(buffer, payload...)
+	_, err = writer.Write(buffer)
+	return err
+}
+
+// Durably appends the record to the log, then applies it to the in-memory state
+func (s *FileStore) appendAndApply(record logRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.appendAndApplyLocked(record)
+}
+
+// Same as appendAndApply, for callers that already hold the mutex
+func (s *FileStore) appendAndApplyLocked(record logRecord) error {
+	if s.closed {
+		return ErrStoreClosed
+	}
+	offset, err := s.logFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrWritingLog, err)
+	}
+	record.Sequence = s.sequence + 1
+	err = writeRecord(s.logFile, record)
+	if err == nil {
+		err = s.logFile.Sync()
+	}
+	if err != nil {
+		// cuts off whatever part of the record was written, so a torn record can only ever be the last one in the log
+		s.logFile.Truncate(offset)
+		s.logFile.Seek(offset, io.SeekStart)
+		return fmt.Errorf("%w ... %w", ErrWritingLog, err)
+	}
+	s.sequence = record.Sequence
+	return s.apply(record)
+}
+
+func (s *FileStore) Put(storedReceipt StoredReceipt) error {
+	if storedReceipt.Receipt.Id == "" {
+		return ErrEmptyReceiptId
+	}
+	return s.appendAndApply(logRecord{Operation: operationPut, StoredReceipt: &storedReceipt})
+}
+
+func (s *FileStore) PutIfAbsent(storedReceipt StoredReceipt) (StoredReceipt, bool, error) {
+	if storedReceipt.Receipt.Id == "" {
+		return StoredReceipt{}, false, ErrEmptyReceiptId
+	}
+	// every write goes through the mutex, so nothing can be stored between checking for the receipt and logging it
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if existing, found := s.memory.duplicates.find(storedReceipt); found {
+		return existing, true, nil
+	}
+	return storedReceipt, false, s.appendAndApplyLocked(logRecord{Operation: operationPut, StoredReceipt: &storedReceipt})
+}
+
+func (s *FileStore) Get(id string) (StoredReceipt, error) {
+	return s.memory.Get(id)
+}
+
+func (s *FileStore) List() ([]StoredReceipt, error) {
+	return s.memory.List()
+}
+
+func (s *FileStore) Search(query Query) (Page, error) {
+	return s.memory.Search(query)
+}
+
+func (s *FileStore) Replace(storedReceipt StoredReceipt) (StoredReceipt, StoredReceipt, error) {
+	if storedReceipt.Receipt.Id == "" {
+		return StoredReceipt{}, StoredReceipt{}, ErrEmptyReceiptId
+	}
+	// every write goes through the mutex, so the receipt can not change between looking it up and logging its replacement
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	previous, err := s.memory.Get(storedReceipt.Receipt.Id)
+	if err != nil {
+		return StoredReceipt{}, StoredReceipt{}, err
+	}
+	storedReceipt.Version = previous.EffectiveVersion() + 1
+	storedReceipt.IdempotencyKey = previous.IdempotencyKey
+	storedReceipt.UserId = previous.UserId
+	revisedAt := time.Now().UTC()
+	if err := s.appendAndApplyLocked(logRecord{Operation: operationReplace, StoredReceipt: &storedReceipt, RevisedAt: &revisedAt}); err != nil {
+		return StoredReceipt{}, StoredReceipt{}, err
+	}
+	return previous, storedReceipt, nil
+}
+
+func (s *FileStore) Delete(id string) (StoredReceipt, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	storedReceipt, err := s.memory.Get(id)
+	if err != nil {
+		return StoredReceipt{}, err
+	}
+	revisedAt := time.Now().UTC()
+	return storedReceipt, s.appendAndApplyLocked(logRecord{Operation: operationDelete, Id: id, RevisedAt: &revisedAt})
+}
+
+func (s *FileStore) History(id string) ([]ReceiptRevision, error) {
+	return s.memory.History(id)
+}
+
+// Writes the current state to a new snapshot and empties the write-ahead log.
+// The snapshot is written to a temporary file and renamed into place, so a crash at any point leaves either the old or the new snapshot.
+// A crash after the rename but before the log is emptied leaves records in the log that the snapshot already includes, which are skipped on replay by their sequence numbers.
+func (s *FileStore) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrStoreClosed
+	}
+
+	storedReceipts, err := s.memory.List()
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrCompactingStore, err)
+	}
+	temporaryPath := s.snapshotPath() + ".tmp"
+	snapshotFile, err := os.Create(temporaryPath)
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrCompactingStore, err)
+	}
+	writer := bufio.NewWriter(snapshotFile)
+	for _, record := range snapshotRecords(s.sequence, storedReceipts, s.memory.allHistories()) {
+		if err = writeRecord(writer, record); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = snapshotFile.Sync()
+	}
+	closeErr := snapshotFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temporaryPath, s.snapshotPath())
+	}
+	if err != nil {
+		os.Remove(temporaryPath)
+		return fmt.Errorf("%w ... %w", ErrCompactingStore, err)
+	}
+
+	if err := s.logFile.Truncate(0); err != nil {
+		return fmt.Errorf("%w ... %w", ErrCompactingStore, err)
+	}
+	if _, err := s.logFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("%w ... %w", ErrCompactingStore, err)
+	}
+	return s.logFile.Sync()
+}
+
+// The records a snapshot is made of, the sequence number of the last log record it includes, then the history of every amended or deleted receipt followed by every current receipt
+func snapshotRecords(sequence int64, storedReceipts []StoredReceipt, histories map[string][]ReceiptRevision) []logRecord {
+	historyIds := make([]string, 0, len(histories))
+	for id := range histories {
+		historyIds = append(historyIds, id)
+	}
+	sort.Strings(historyIds)
+	records := []logRecord{{Operation: operationSnapshot, Sequence: sequence}}
+	for _, id := range historyIds {
+		for i := range histories[id] {
+			records = append(records, logRecord{Operation: operationRevision, Id: id, Revision: &histories[id][i]})
+		}
+	}
+	for i := range storedReceipts {
+		records = append(records, logRecord{Operation: operationPut, StoredReceipt: &storedReceipts[i]})
+	}
+	return records
+}
+
+func (s *FileStore) compactPeriodically() {
+	defer close(s.compactionDone)
+	ticker := time.NewTicker(s.compactionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Compact() // a failed compaction leaves the log intact, so it is retried on the next tick
+		case <-s.stopCompaction:
+			return
+		}
+	}
+}
+
+// Stops background compaction and closes the write-ahead log. The store cannot be written to afterwards.
+func (s *FileStore) Close() error {
+	if s.stopCompaction != nil {
+		close(s.stopCompaction)
+		<-s.compactionDone
+		s.stopCompaction = nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.logFile.Close()
+}
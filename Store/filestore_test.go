@@ -0,0 +1,299 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	utils "go-receipt-processor/TestingUtils"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func openTestFileStore(t *testing.T, directory string) *FileStore {
+	fileStore, err := OpenFileStore(directory, WithCompactionInterval(0))
+	if err != nil {
+		t.Fatalf("open file store ( %s ): unexpected error ( %v )", directory, err)
+	}
+	return fileStore
+}
+
+func Test_FileStore(t *testing.T) {
+	fileStore := openTestFileStore(t, t.TempDir())
+	defer fileStore.Close()
+	testReceiptStore(t, fileStore)
+}
+
+func Test_FileStoreReplaysLogOnOpen(t *testing.T) {
+	directory := t.TempDir()
+	fileStore := openTestFileStore(t, directory)
+	for _, storedReceipt := range storedReceipts {
+		fileStore.Put(storedReceipt)
+	}
+	fileStore.Delete("b")
+	fileStore.Close()
+
+	reopened := openTestFileStore(t, directory)
+	defer reopened.Close()
+	listed, _ := reopened.List()
+	if !cmp.Equal(listed, []StoredReceipt{storedReceipts[1]}) {
+		t.Fatalf("replay log: expected only receipt ( a ) after reopening, got ( %+v )", listed)
+	}
+}
+
+func Test_FileStoreReplaysDuplicateIndexesOnOpen(t *testing.T) {
+	directory := t.TempDir()
+	fileStore := openTestFileStore(t, directory)
+	fileStore.PutIfAbsent(newSubmittedReceipt("a", "hash-1", "key-1"))
+	fileStore.Close()
+
+	reopened := openTestFileStore(t, directory)
+	defer reopened.Close()
+	for _, submitted := range []StoredReceipt{newSubmittedReceipt("b", "hash-1", ""), newSubmittedReceipt("c", "hash-2", "key-1")} {
+		result, duplicate, err := reopened.PutIfAbsent(submitted)
+		if err != nil || !duplicate || result.Receipt.Id != "a" {
+			t.Fatalf("put if absent ( %s ): expected receipt ( a ) as a duplicate after reopening got ( %s ) duplicate ( %t ) error ( %v )", submitted.Receipt.Id, result.Receipt.Id, duplicate, err)
+		}
+	}
+}
+
+// Dates and times were written to the log as objects before they had their own json format
+func Test_FileStoreReadsLegacyDatesAndTimes(t *testing.T) {
+	directory := t.TempDir()
+	payload := []byte(`{"op":"put","storedReceipt":{"Receipt":{"Id":"legacy","Retailer":"Target","PurchaseDate":{"Year":2022,"Month":3,"Day":20},"PurchaseTime":{"Hour":14,"Minute":33},"Items":[],"Total":0,"Currency":"USD"},"Points":6,"RulesetVersion":1}}`)
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	if err := os.WriteFile(filepath.Join(directory, logFileName), append(record, payload...), 0o644); err != nil {
+		t.Fatalf("write legacy log: unexpected error ( %v )", err)
+	}
+
+	fileStore := openTestFileStore(t, directory)
+	defer fileStore.Close()
+	storedReceipt, err := fileStore.Get("legacy")
+	if err != nil {
+		t.Fatalf("read legacy log: unexpected error ( %v )", err)
+	}
+	if storedReceipt.Receipt.PurchaseDate.String() != "2022-03-20" || storedReceipt.Receipt.PurchaseTime.String() != "14:33" {
+		t.Fatalf("read legacy log: expected the purchase date and time 2022-03-20 14:33 got ( %s %s )", storedReceipt.Receipt.PurchaseDate, storedReceipt.Receipt.PurchaseTime)
+	}
+}
+
+func Test_FileStoreKeepsHistoriesAcrossCompaction(t *testing.T) {
+	directory := t.TempDir()
+	fileStore := openTestFileStore(t, directory)
+	for _, storedReceipt := range storedReceipts {
+		fileStore.Put(storedReceipt)
+	}
+	amended := storedReceipts[0]
+	amended.Points = 30
+	fileStore.Replace(amended)
+	fileStore.Delete("a")
+	expected, _ := fileStore.History("b")
+	if err := fileStore.Compact(); err != nil {
+		t.Fatalf("compact: unexpected error ( %v )", err)
+	}
+	fileStore.Replace(amended) // written to the log after the snapshot
+	fileStore.Close()
+
+	reopened := openTestFileStore(t, directory)
+	defer reopened.Close()
+	history, _ := reopened.History("b")
+	if len(history) != 2 || !cmp.Equal(history[0], expected[0]) || history[1].Version != 2 || history[1].Points != 30 {
+		t.Fatalf("history ( b ): expected the original and the first amendment after reopening got ( %+v )", history)
+	}
+	if stored, _ := reopened.Get("b"); stored.Version != 3 {
+		t.Fatalf("get ( b ): expected version 3 after reopening got %d", stored.Version)
+	}
+	if history, _ := reopened.History("a"); len(history) != 1 || !history[0].Deleted {
+		t.Fatalf("history ( a ): expected the deleted receipt after reopening got ( %+v )", history)
+	}
+}
+
+// Simulates a crash after the snapshot is renamed into place but before the log is emptied, by putting the log back as it was before compacting
+func Test_FileStoreSkipsLogRecordsIncludedInSnapshot(t *testing.T) {
+	directory := t.TempDir()
+	fileStore := openTestFileStore(t, directory)
+	for _, storedReceipt := range storedReceipts {
+		fileStore.Put(storedReceipt)
+	}
+	amended := storedReceipts[0]
+	amended.Points = 30
+	fileStore.Replace(amended)
+	expected, _ := fileStore.History("b")
+	logPath := filepath.Join(directory, logFileName)
+	log, _ := os.ReadFile(logPath)
+	fileStore.Compact()
+	fileStore.Close()
+	os.WriteFile(logPath, log, 0o644)
+
+	reopened := openTestFileStore(t, directory)
+	if history, _ := reopened.History("b"); !cmp.Equal(history, expected) {
+		t.Fatalf("history ( b ): expected the original and the amendment once each after reopening got ( %+v )", history)
+	}
+	reopened.Delete("a") // written to the log after the records the snapshot already includes
+	reopened.Close()
+
+	reopened = openTestFileStore(t, directory)
+	defer reopened.Close()
+	if _, err := reopened.Get("a"); !errors.Is(err, ErrReceiptNotFound) {
+		t.Fatalf("get ( a ): expected error ( %v ) after reopening got error ( %v )", ErrReceiptNotFound, err)
+	}
+	if history, _ := reopened.History("b"); !cmp.Equal(history, expected) {
+		t.Fatalf("history ( b ): expected the original and the amendment once each after reopening again got ( %+v )", history)
+	}
+}
+
+func Test_FileStoreCompaction(t *testing.T) {
+	directory := t.TempDir()
+	fileStore := openTestFileStore(t, directory)
+	for _, storedReceipt := range storedReceipts {
+		fileStore.Put(storedReceipt)
+	}
+	if err := fileStore.Compact(); err != nil {
+		t.Fatalf("compact: unexpected error ( %v )", err)
+	}
+	if info, _ := os.Stat(filepath.Join(directory, logFileName)); info.Size() != 0 {
+		t.Fatalf("compact: expected the log to be emptied, it has %d bytes", info.Size())
+	}
+	fileStore.Delete("a") // written to the log after the snapshot
+	fileStore.Close()
+
+	reopened := openTestFileStore(t, directory)
+	defer reopened.Close()
+	listed, _ := reopened.List()
+	if !cmp.Equal(listed, []StoredReceipt{storedReceipts[0]}) {
+		t.Fatalf("compaction: expected snapshot and log to be combined into receipt ( b ), got ( %+v )", listed)
+	}
+}
+
+func Test_FileStoreBackgroundCompaction(t *testing.T) {
+	directory := t.TempDir()
+	fileStore, err := OpenFileStore(directory, WithCompactionInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("open file store: unexpected error ( %v )", err)
+	}
+	fileStore.Put(storedReceipts[0])
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if info, err := os.Stat(filepath.Join(directory, snapshotFileName)); err == nil && info.Size() > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("background compaction: no snapshot was written")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	fileStore.Close()
+	if err := fileStore.Put(storedReceipts[1]); err != ErrStoreClosed {
+		t.Fatalf("put after close: expected error ( %v ) got error ( %v )", ErrStoreClosed, err)
+	}
+}
+
+// Simulates a crash part way through appending a record by cutting the log at every possible byte within the last record
+func Test_FileStoreRecoversFromTruncatedLog(t *testing.T) {
+	directory := t.TempDir()
+	fileStore := openTestFileStore(t, directory)
+	fileStore.Put(storedReceipts[0])
+	logPath := filepath.Join(directory, logFileName)
+	info, _ := os.Stat(logPath)
+	firstRecordLength := info.Size()
+	fileStore.Put(storedReceipts[1])
+	fileStore.Close()
+	fullLog, _ := os.ReadFile(logPath)
+
+	for cut := firstRecordLength; cut < int64(len(fullLog)); cut++ {
+		os.WriteFile(logPath, fullLog[:cut], 0o644)
+
+		recovered := openTestFileStore(t, directory)
+		listed, _ := recovered.List()
+		if !cmp.Equal(listed, []StoredReceipt{storedReceipts[0]}) {
+			t.Fatalf("truncated log ( cut at %d of %d bytes ): expected only the first receipt, got ( %+v )", cut, len(fullLog), listed)
+		}
+		if info, _ := os.Stat(logPath); info.Size() != firstRecordLength {
+			t.Fatalf("truncated log ( cut at %d of %d bytes ): expected the partial record to be dropped, log has %d bytes", cut, len(fullLog), info.Size())
+		}
+
+		// new records have to land after the last complete record, not after the garbage
+		recovered.Put(storedReceipts[1])
+		recovered.Close()
+		reopened := openTestFileStore(t, directory)
+		listed, _ = reopened.List()
+		reopened.Close()
+		if !cmp.Equal(listed, []StoredReceipt{storedReceipts[1], storedReceipts[0]}) {
+			t.Fatalf("truncated log ( cut at %d of %d bytes ): expected both receipts after writing again, got ( %+v )", cut, len(fullLog), listed)
+		}
+	}
+}
+
+func Test_FileStoreRefusesCorruptionBeforeTheTail(t *testing.T) {
+	directory := t.TempDir()
+	fileStore := openTestFileStore(t, directory)
+	for _, storedReceipt := range storedReceipts {
+		fileStore.Put(storedReceipt)
+	}
+	fileStore.Close()
+
+	logPath := filepath.Join(directory, logFileName)
+	log, _ := os.ReadFile(logPath)
+	firstPayloadLength := int(binary.LittleEndian.Uint32(log[0:4]))
+	var testCases []utils.CreationTestingData[int, error] = []utils.CreationTestingData[int, error]{
+		{Argument: recordHeaderSize + firstPayloadLength/2, ExpectedErr: ErrCorruptLog}, // a byte in the first payload, so its checksum no longer matches
+		{Argument: 1, ExpectedErr: ErrCorruptLog},                                       // a byte in the first length, so the record seems to run past the end of the log
+	}
+	for _, testCase := range testCases {
+		corrupted := append([]byte{}, log...)
+		corrupted[testCase.Argument] ^= 0xFF
+		os.WriteFile(logPath, corrupted, 0o644)
+
+		_, err := OpenFileStore(directory, WithCompactionInterval(0))
+		errCheck := testCase.CheckTestCase("open corrupt log", nil, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+		if left, _ := os.ReadFile(logPath); !cmp.Equal(left, corrupted) {
+			t.Fatalf("open corrupt log ( byte %d ): expected the log to be left as it was, it has %d of %d bytes", testCase.Argument, len(left), len(corrupted))
+		}
+	}
+}
+
+func Test_FileStoreRefusesCorruptSnapshot(t *testing.T) {
+	directory := t.TempDir()
+	fileStore := openTestFileStore(t, directory)
+	for _, storedReceipt := range storedReceipts {
+		fileStore.Put(storedReceipt)
+	}
+	fileStore.Compact()
+	fileStore.Close()
+
+	snapshotPath := filepath.Join(directory, snapshotFileName)
+	snapshot, _ := os.ReadFile(snapshotPath)
+	snapshot[len(snapshot)-2] ^= 0xFF
+	os.WriteFile(snapshotPath, snapshot, 0o644)
+	if _, err := OpenFileStore(directory, WithCompactionInterval(0)); !errors.Is(err, ErrCorruptRecord) {
+		t.Fatalf("open corrupt snapshot: expected error ( %v ) got error ( %v )", ErrCorruptRecord, err)
+	}
+}
+
+func Test_FileStoreIgnoresCorruptTail(t *testing.T) {
+	directory := t.TempDir()
+	fileStore := openTestFileStore(t, directory)
+	fileStore.Put(storedReceipts[0])
+	fileStore.Put(storedReceipts[1])
+	fileStore.Close()
+
+	logPath := filepath.Join(directory, logFileName)
+	log, _ := os.ReadFile(logPath)
+	log[len(log)-2] ^= 0xFF // flips a byte in the last payload so its checksum no longer matches
+	os.WriteFile(logPath, log, 0o644)
+
+	recovered := openTestFileStore(t, directory)
+	defer recovered.Close()
+	listed, _ := recovered.List()
+	if !cmp.Equal(listed, []StoredReceipt{storedReceipts[0]}) {
+		t.Fatalf("corrupt log: expected only the first receipt, got ( %+v )", listed)
+	}
+}
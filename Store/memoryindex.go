@@ -0,0 +1,380 @@
+package store
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+)
+
+// Secondary index of the in-memory store, mapping each distinct value of a field to the ids of the receipts that have it.
+// The distinct values are kept in order, so ranges of them ( i.e. a span of dates or every retailer with a prefix ) can be found with a binary search.
+// Most fields have far fewer distinct values than there are receipts, so keeping them sorted stays cheap as receipts are added.
+// The ids under each value are kept in order too, so a search can seek to where its last page ended and read only as many ids as the next page needs.
+// Safe for concurrent use, each index has its own lock so writes to different indexes never wait on each other.
+type sortedIndex[K comparable] struct {
+	mutex sync.RWMutex
+	less  func(a K, b K) bool
+	keys  []K
+	ids   map[K][]string
+}
+
+func newSortedIndex[K comparable](less func(a K, b K) bool) *sortedIndex[K] {
+	return &sortedIndex[K]{less: less, ids: make(map[K][]string)}
+}
+
+// The position of the first key that is not less than the key. The mutex must be held.
+func (index *sortedIndex[K]) search(key K) int {
+	return sort.Search(len(index.keys), func(i int) bool {
+		return !index.less(index.keys[i], key)
+	})
+}
+
+func (index *sortedIndex[K]) add(key K, id string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	ids, containsKey := index.ids[key]
+	if !containsKey {
+		position := index.search(key)
+		index.keys = append(index.keys, key)
+		copy(index.keys[position+1:], index.keys[position:])
+		index.keys[position] = key
+	}
+	index.ids[key] = insertId(ids, id)
+}
+
+func (index *sortedIndex[K]) remove(key K, id string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	ids, containsKey := index.ids[key]
+	if !containsKey {
+		return
+	}
+	ids = removeId(ids, id)
+	if len(ids) > 0 {
+		index.ids[key] = ids
+		return
+	}
+	delete(index.ids, key)
+	position := index.search(key)
+	index.keys = append(index.keys[:position], index.keys[position+1:]...)
+}
+
+// A copy of the keys from the first key up to and including the last key, where a nil end leaves that side of the range open
+func (index *sortedIndex[K]) keyRange(first *K, last *K) []K {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	start, end := 0, len(index.keys)
+	if first != nil {
+		start = index.search(*first)
+	}
+	if last != nil {
+		end = sort.Search(len(index.keys), func(i int) bool {
+			return index.less(*last, index.keys[i])
+		})
+	}
+	if start >= end {
+		return nil
+	}
+	return append([]K{}, index.keys[start:end]...)
+}
+
+// How many ids are indexed under the keys
+func (index *sortedIndex[K]) count(keys []K) int {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	total := 0
+	for _, key := range keys {
+		total += len(index.ids[key])
+	}
+	return total
+}
+
+// At most count ids indexed under any of the keys that come after the id, in order.
+// Seeks into the ids of each key rather than reading them from the start, so the cost follows the number of keys and ids read, not how many ids come before the id.
+func (index *sortedIndex[K]) after(keys []K, id string, count int) []string {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	heads := make(idHeads, 0, len(keys))
+	for _, key := range keys {
+		ids := index.ids[key]
+		if start := searchIdsAfter(ids, id); start < len(ids) {
+			heads = append(heads, ids[start:])
+		}
+	}
+	heap.Init(&heads)
+	ids := []string{}
+	for len(heads) > 0 && len(ids) < count {
+		ids = append(ids, heads[0][0])
+		if len(heads[0]) == 1 {
+			heap.Pop(&heads)
+		} else {
+			heads[0] = heads[0][1:]
+			heap.Fix(&heads, 0)
+		}
+	}
+	return ids
+}
+
+// The unread ids of each key being merged by sortedIndex.after, as a heap ordered by the next id of each, so the smallest next id is always first
+type idHeads [][]string
+
+func (heads idHeads) Len() int           { return len(heads) }
+func (heads idHeads) Less(i, j int) bool { return heads[i][0] < heads[j][0] }
+func (heads idHeads) Swap(i, j int)      { heads[i], heads[j] = heads[j], heads[i] }
+func (heads *idHeads) Push(ids any)      { *heads = append(*heads, ids.([]string)) }
+func (heads *idHeads) Pop() any {
+	last := (*heads)[len(*heads)-1]
+	*heads = (*heads)[:len(*heads)-1]
+	return last
+}
+
+// The position of the first id in the ordered ids that comes after the id
+func searchIdsAfter(ids []string, id string) int {
+	return sort.Search(len(ids), func(i int) bool {
+		return ids[i] > id
+	})
+}
+
+// Adds the id to the ordered ids, unless they already contain it
+func insertId(ids []string, id string) []string {
+	position := sort.SearchStrings(ids, id)
+	if position < len(ids) && ids[position] == id {
+		return ids
+	}
+	ids = append(ids, "")
+	copy(ids[position+1:], ids[position:])
+	ids[position] = id
+	return ids
+}
+
+// Removes the id from the ordered ids, if they contain it
+func removeId(ids []string, id string) []string {
+	position := sort.SearchStrings(ids, id)
+	if position < len(ids) && ids[position] == id {
+		return append(ids[:position], ids[position+1:]...)
+	}
+	return ids
+}
+
+// The longest run of characters the item text index is keyed by
+const itemTextRunLength = 3
+
+// Index of the receipts by every run of up to three characters in the case folded descriptions of their items.
+// A description that contains some text contains every run of three characters in it ( or the text itself when it is shorter ),
+// so a search for items containing text only reads the receipts indexed under all of those runs instead of every receipt.
+// Safe for concurrent use.
+type itemTextIndex struct {
+	mutex sync.RWMutex
+	ids   map[string][]string
+}
+
+func newItemTextIndex() *itemTextIndex {
+	return &itemTextIndex{ids: make(map[string][]string)}
+}
+
+// Every distinct run of up to three characters in the case folded item descriptions
+func itemTextRuns(items []receiptitem.ReceiptItem) map[string]struct{} {
+	runs := make(map[string]struct{})
+	for _, item := range items {
+		description := []rune(foldCase(item.ShortDescription))
+		for start := range description {
+			for end := start + 1; end <= len(description) && end-start <= itemTextRunLength; end++ {
+				runs[string(description[start:end])] = struct{}{}
+			}
+		}
+	}
+	return runs
+}
+
+// The runs every description that contains the text must be indexed under
+func textSearchRuns(text string) []string {
+	folded := []rune(foldCase(text))
+	if len(folded) <= itemTextRunLength {
+		return []string{string(folded)}
+	}
+	runs := []string{}
+	for start := 0; start+itemTextRunLength <= len(folded); start++ {
+		runs = append(runs, string(folded[start:start+itemTextRunLength]))
+	}
+	return runs
+}
+
+func (index *itemTextIndex) add(items []receiptitem.ReceiptItem, id string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	for run := range itemTextRuns(items) {
+		index.ids[run] = insertId(index.ids[run], id)
+	}
+}
+
+func (index *itemTextIndex) remove(items []receiptitem.ReceiptItem, id string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	for run := range itemTextRuns(items) {
+		ids := removeId(index.ids[run], id)
+		if len(ids) == 0 {
+			delete(index.ids, run)
+		} else {
+			index.ids[run] = ids
+		}
+	}
+}
+
+// How many ids are indexed under the least common of the runs, which is the most that can be indexed under all of them
+func (index *itemTextIndex) count(runs []string) int {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	least := -1
+	for _, run := range runs {
+		if count := len(index.ids[run]); least < 0 || count < least {
+			least = count
+		}
+	}
+	return least
+}
+
+// At most count ids indexed under every one of the runs that come after the id, in order.
+// Seeks through the ids of each run in turn to the next id they could all share, so ids only some of the runs are indexed under are skipped over rather than read one at a time.
+func (index *itemTextIndex) after(runs []string, id string, count int) []string {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	lists := make([][]string, 0, len(runs))
+	for _, run := range runs {
+		ids, containsKey := index.ids[run]
+		if !containsKey {
+			return []string{}
+		}
+		lists = append(lists, ids)
+	}
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+	ids := []string{}
+	for start := searchIdsAfter(lists[0], id); start < len(lists[0]) && len(ids) < count; {
+		candidate, shared := lists[0][start], true
+		for _, other := range lists[1:] {
+			position := sort.SearchStrings(other, candidate)
+			if position == len(other) {
+				return ids
+			}
+			if other[position] != candidate {
+				// no id before this one is indexed under every run, so skip ahead to it
+				start, shared = sort.SearchStrings(lists[0], other[position]), false
+				break
+			}
+		}
+		if shared {
+			ids = append(ids, candidate)
+			start++
+		}
+	}
+	return ids
+}
+
+// Index of the receipts resubmissions are matched to, by idempotency key and by content hash.
+// Holds the receipts themselves instead of their ids, so a match can be returned without locking the shard it is stored in.
+type duplicateIndex struct {
+	mutex            sync.Mutex
+	byIdempotencyKey map[string]StoredReceipt
+	byContentHash    map[string]StoredReceipt
+}
+
+func newDuplicateIndex() *duplicateIndex {
+	return &duplicateIndex{byIdempotencyKey: make(map[string]StoredReceipt), byContentHash: make(map[string]StoredReceipt)}
+}
+
+// Looks up the receipt already stored with the same idempotency key, or failing that the same content hash
+func (index *duplicateIndex) find(storedReceipt StoredReceipt) (StoredReceipt, bool) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	return index.findLocked(storedReceipt)
+}
+
+// Same as find, for callers that already hold the mutex
+func (index *duplicateIndex) findLocked(storedReceipt StoredReceipt) (StoredReceipt, bool) {
+	if storedReceipt.IdempotencyKey != "" {
+		if existing, found := index.byIdempotencyKey[storedReceipt.IdempotencyKey]; found {
+			return existing, true
+		}
+	}
+	if storedReceipt.ContentHash != "" {
+		if existing, found := index.byContentHash[storedReceipt.ContentHash]; found {
+			return existing, true
+		}
+	}
+	return StoredReceipt{}, false
+}
+
+// Returns the receipt already stored with the same idempotency key or content hash, or if there is none indexes the receipt in its place,
+// so any receipt submitted after it is matched to it even before it has been written to its shard
+func (index *duplicateIndex) claim(storedReceipt StoredReceipt) (StoredReceipt, bool) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	if existing, found := index.findLocked(storedReceipt); found {
+		return existing, true
+	}
+	index.addLocked(storedReceipt)
+	return storedReceipt, false
+}
+
+// Removes the previous version of the receipt, if there was one, and indexes its current version
+func (index *duplicateIndex) update(previous *StoredReceipt, current *StoredReceipt) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	if previous != nil {
+		id := previous.Receipt.Id
+		if existing, found := index.byIdempotencyKey[previous.IdempotencyKey]; found && existing.Receipt.Id == id {
+			delete(index.byIdempotencyKey, previous.IdempotencyKey)
+		}
+		if existing, found := index.byContentHash[previous.ContentHash]; found && existing.Receipt.Id == id {
+			delete(index.byContentHash, previous.ContentHash)
+		}
+	}
+	if current != nil {
+		index.addLocked(*current)
+	}
+}
+
+// The mutex must be held
+func (index *duplicateIndex) addLocked(storedReceipt StoredReceipt) {
+	if storedReceipt.IdempotencyKey != "" {
+		index.byIdempotencyKey[storedReceipt.IdempotencyKey] = storedReceipt
+	}
+	if storedReceipt.ContentHash != "" {
+		// the first receipt stored with the content is the one resubmissions are matched to
+		if existing, found := index.byContentHash[storedReceipt.ContentHash]; !found || existing.Receipt.Id == storedReceipt.Receipt.Id {
+			index.byContentHash[storedReceipt.ContentHash] = storedReceipt
+		}
+	}
+}
+
+// Every stored id in order, so a search that no other index narrows down can page through the receipts from where the last page ended instead of sorting every id on every page.
+// Safe for concurrent use.
+type idIndex struct {
+	mutex sync.RWMutex
+	ids   []string
+}
+
+func (index *idIndex) add(id string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.ids = insertId(index.ids, id)
+}
+
+func (index *idIndex) remove(id string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.ids = removeId(index.ids, id)
+}
+
+// A copy of at most count ids that come after the id, in order
+func (index *idIndex) after(id string, count int) []string {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	start := searchIdsAfter(index.ids, id)
+	end := start + count
+	if end > len(index.ids) {
+		end = len(index.ids)
+	}
+	return append([]string{}, index.ids[start:end]...)
+}
@@ -0,0 +1,365 @@
+package store
+
+import (
+	"fmt"
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
+	receipt "go-receipt-processor/Receipt"
+	time "go-receipt-processor/Time"
+	"hash/fnv"
+	"sort"
+	"sync"
+	systime "time"
+)
+
+// Number of independently locked shards the in-memory store is split across, so concurrent requests for different ids rarely wait on each other.
+const memoryStoreShardCount = 32
+
+// How many candidate ids a search reads from an index at a time
+const memorySearchChunkSize = 256
+
+// The points a receipt was awarded, the version of the rules that awarded them, the exchange rate used if any, and what and who the receipt was submitted with
+type storedPoints struct {
+	Points         int64
+	RulesetVersion int
+	ExchangeRate   *exchangerate.Rate
+	ContentHash    string
+	IdempotencyKey string
+	UserId         string
+	Version        int
+}
+
+type memoryStoreShard struct {
+	sync.RWMutex
+	receiptMap map[string]receipt.Receipt
+	pointsMap  map[string]storedPoints
+}
+
+func (shard *memoryStoreShard) get(id string) (StoredReceipt, bool) {
+	receipt, containsKey := shard.receiptMap[id]
+	if !containsKey {
+		return StoredReceipt{}, false
+	}
+	points := shard.pointsMap[id]
+	return StoredReceipt{Receipt: receipt, Points: points.Points, RulesetVersion: points.RulesetVersion, ExchangeRate: points.ExchangeRate, ContentHash: points.ContentHash, IdempotencyKey: points.IdempotencyKey, UserId: points.UserId, Version: points.Version}, true
+}
+
+// In-memory ReceiptStore, nothing is kept between restarts. Safe for concurrent use by multiple goroutines.
+type MemoryStore struct {
+	shards [memoryStoreShardCount]*memoryStoreShard
+
+	// Each index has its own lock, and is updated while the shard of the receipt being written is locked, so a receipt's entries are always updated in the same order as the receipt itself.
+	// Writes to different shards only wait on each other for as long as it takes to update an index, and searches only lock the indexes they read.
+	duplicates          *duplicateIndex
+	idIndex             *idIndex
+	retailerIndex       *sortedIndex[string]
+	foldedRetailerIndex *sortedIndex[string] // retailers with their case folded, for case-insensitive searches
+	purchaseDateIndex   *sortedIndex[date.Date]
+	totalIndex          *sortedIndex[money.Money]
+	pointsIndex         *sortedIndex[int64]
+	purchaseTimeIndex   *sortedIndex[time.Time]
+	itemTextIndex       *itemTextIndex
+
+	historyMutex sync.RWMutex
+	histories    map[string][]ReceiptRevision // the earlier versions of each amended or deleted receipt, oldest first
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		duplicates:          newDuplicateIndex(),
+		idIndex:             &idIndex{},
+		retailerIndex:       newSortedIndex(func(a string, b string) bool { return a < b }),
+		foldedRetailerIndex: newSortedIndex(func(a string, b string) bool { return a < b }),
+		purchaseDateIndex:   newSortedIndex(date.Date.Before),
+		totalIndex:          newSortedIndex(func(a money.Money, b money.Money) bool { return a < b }),
+		pointsIndex:         newSortedIndex(func(a int64, b int64) bool { return a < b }),
+		purchaseTimeIndex:   newSortedIndex(func(a time.Time, b time.Time) bool { return a.Compare(b) < 0 }),
+		itemTextIndex:       newItemTextIndex(),
+		histories:           make(map[string][]ReceiptRevision),
+	}
+	for i := range s.shards {
+		s.shards[i] = &memoryStoreShard{
+			receiptMap: make(map[string]receipt.Receipt),
+			pointsMap:  make(map[string]storedPoints),
+		}
+	}
+	return s
+}
+
+func (s *MemoryStore) getShard(id string) *memoryStoreShard {
+	hash := fnv.New32a()
+	hash.Write([]byte(id))
+	return s.shards[hash.Sum32()%memoryStoreShardCount]
+}
+
+func (s *MemoryStore) Put(storedReceipt StoredReceipt) error {
+	if storedReceipt.Receipt.Id == "" {
+		return ErrEmptyReceiptId
+	}
+	s.put(storedReceipt)
+	return nil
+}
+
+func (s *MemoryStore) PutIfAbsent(storedReceipt StoredReceipt) (StoredReceipt, bool, error) {
+	if storedReceipt.Receipt.Id == "" {
+		return StoredReceipt{}, false, ErrEmptyReceiptId
+	}
+	// claimed before the receipt is written, so a receipt submitted at the same time is matched to this one instead of being stored as well
+	if existing, found := s.duplicates.claim(storedReceipt); found {
+		return existing, true, nil
+	}
+	s.put(storedReceipt)
+	return storedReceipt, false, nil
+}
+
+// Stores the receipt and indexes it, replacing any existing entry with the same id
+func (s *MemoryStore) put(storedReceipt StoredReceipt) {
+	shard := s.getShard(storedReceipt.Receipt.Id)
+	shard.Lock()
+	defer shard.Unlock()
+	s.putLocked(shard, storedReceipt)
+}
+
+// Same as put, for callers that already hold the lock of the receipt's shard
+func (s *MemoryStore) putLocked(shard *memoryStoreShard, storedReceipt StoredReceipt) {
+	id := storedReceipt.Receipt.Id
+	var previousReceipt *StoredReceipt
+	if previous, containsKey := shard.get(id); containsKey {
+		previousReceipt = &previous
+	}
+	shard.receiptMap[id] = storedReceipt.Receipt
+	shard.pointsMap[id] = storedPoints{Points: storedReceipt.Points, RulesetVersion: storedReceipt.RulesetVersion, ExchangeRate: storedReceipt.ExchangeRate, ContentHash: storedReceipt.ContentHash, IdempotencyKey: storedReceipt.IdempotencyKey, UserId: storedReceipt.UserId, Version: storedReceipt.Version}
+	s.reindex(previousReceipt, &storedReceipt)
+}
+
+// Moves a receipt's entries in every index from its previous version to its current one, where either is nil when the receipt is being added or removed.
+// Entries that now point at a different receipt are left alone. The lock of the receipt's shard must be held.
+func (s *MemoryStore) reindex(previous *StoredReceipt, current *StoredReceipt) {
+	s.duplicates.update(previous, current)
+	if previous != nil {
+		id := previous.Receipt.Id
+		if current == nil {
+			s.idIndex.remove(id)
+		}
+		s.retailerIndex.remove(previous.Receipt.Retailer, id)
+		s.foldedRetailerIndex.remove(foldCase(previous.Receipt.Retailer), id)
+		s.purchaseDateIndex.remove(previous.Receipt.PurchaseDate, id)
+		s.totalIndex.remove(previous.Receipt.Total, id)
+		s.pointsIndex.remove(previous.Points, id)
+		s.purchaseTimeIndex.remove(previous.Receipt.PurchaseTime, id)
+		s.itemTextIndex.remove(previous.Receipt.Items, id)
+	}
+	if current != nil {
+		id := current.Receipt.Id
+		s.idIndex.add(id)
+		s.retailerIndex.add(current.Receipt.Retailer, id)
+		s.foldedRetailerIndex.add(foldCase(current.Receipt.Retailer), id)
+		s.purchaseDateIndex.add(current.Receipt.PurchaseDate, id)
+		s.totalIndex.add(current.Receipt.Total, id)
+		s.pointsIndex.add(current.Points, id)
+		s.purchaseTimeIndex.add(current.Receipt.PurchaseTime, id)
+		s.itemTextIndex.add(current.Receipt.Items, id)
+	}
+}
+
+func (s *MemoryStore) Get(id string) (StoredReceipt, error) {
+	shard := s.getShard(id)
+	shard.RLock()
+	defer shard.RUnlock()
+	storedReceipt, containsKey := shard.get(id)
+	if !containsKey {
+		return StoredReceipt{}, fmt.Errorf("%w given \"%s\"", ErrReceiptNotFound, id)
+	}
+	return storedReceipt, nil
+}
+
+func (s *MemoryStore) List() ([]StoredReceipt, error) {
+	storedReceipts := []StoredReceipt{}
+	for _, shard := range s.shards {
+		shard.RLock()
+		for id := range shard.receiptMap {
+			storedReceipt, _ := shard.get(id)
+			storedReceipts = append(storedReceipts, storedReceipt)
+		}
+		shard.RUnlock()
+	}
+	sort.Slice(storedReceipts, func(i, j int) bool {
+		return storedReceipts[i].Receipt.Id < storedReceipts[j].Receipt.Id
+	})
+	return storedReceipts, nil
+}
+
+// Finds the matching receipts using whichever index narrows the query down to the fewest candidates, or the id index when none of them apply.
+// Candidates are read in order from where the last page ended a chunk at a time, stopping at the first match past the end of the page,
+// so a page costs about as much however many receipts match before or after it.
+func (s *MemoryStore) Search(query Query) (Page, error) {
+	page := Page{Receipts: []StoredReceipt{}}
+	// adds the matching receipts to the page in the order of their ids, returning true once the page is full and there is at least one more match
+	fill := func(ids []string) bool {
+		for _, id := range ids {
+			shard := s.getShard(id)
+			shard.RLock()
+			storedReceipt, containsKey := shard.get(id)
+			shard.RUnlock()
+			if !containsKey || !query.Matches(storedReceipt) { // the receipt may have changed since the index was read
+				continue
+			}
+			if query.Limit > 0 && len(page.Receipts) == query.Limit {
+				page.NextAfterId = page.Receipts[len(page.Receipts)-1].Receipt.Id
+				return true
+			}
+			page.Receipts = append(page.Receipts, storedReceipt)
+		}
+		return false
+	}
+
+	readCandidates, _ := s.findCandidates(query)
+	for afterId := query.AfterId; ; {
+		ids := readCandidates(afterId, memorySearchChunkSize)
+		if len(ids) == 0 || fill(ids) {
+			return page, nil
+		}
+		afterId = ids[len(ids)-1]
+	}
+}
+
+// Reads at most count ids of receipts that could match a query that come after the id, in order
+type candidateReader func(id string, count int) []string
+
+// Returns a reader of the receipts that could match the query according to the most selective index that applies to it,
+// or a reader of every id and false if none do
+func (s *MemoryStore) findCandidates(query Query) (candidateReader, bool) {
+	readCandidates, indexed, candidateCount := candidateReader(s.idIndex.after), false, 0
+	consider := func(count int, read candidateReader) {
+		if !indexed || count < candidateCount {
+			readCandidates, indexed, candidateCount = read, true, count
+		}
+	}
+	if query.Retailer != "" {
+		retailerIndex, retailer := s.retailerIndex, query.Retailer
+		if query.RetailerIgnoreCase {
+			retailerIndex, retailer = s.foldedRetailerIndex, foldCase(retailer)
+		}
+		var keys []string
+		if query.RetailerMatch == PrefixRetailer {
+			if upperBound, bounded := prefixUpperBound(retailer); bounded {
+				keys = retailerIndex.keyRange(&retailer, &upperBound)
+				if len(keys) > 0 && keys[len(keys)-1] == upperBound { // the range includes its last key, which does not start with the prefix
+					keys = keys[:len(keys)-1]
+				}
+			} else {
+				keys = retailerIndex.keyRange(&retailer, nil)
+			}
+		} else {
+			keys = retailerIndex.keyRange(&retailer, &retailer)
+		}
+		consider(retailerIndex.count(keys), func(id string, count int) []string { return retailerIndex.after(keys, id, count) })
+	}
+	if query.PurchaseDateFrom != nil || query.PurchaseDateTo != nil {
+		keys := s.purchaseDateIndex.keyRange(query.PurchaseDateFrom, query.PurchaseDateTo)
+		consider(s.purchaseDateIndex.count(keys), func(id string, count int) []string { return s.purchaseDateIndex.after(keys, id, count) })
+	}
+	if query.TotalMin != nil || query.TotalMax != nil {
+		keys := s.totalIndex.keyRange(query.TotalMin, query.TotalMax)
+		consider(s.totalIndex.count(keys), func(id string, count int) []string { return s.totalIndex.after(keys, id, count) })
+	}
+	if query.PointsMin != nil || query.PointsMax != nil {
+		keys := s.pointsIndex.keyRange(query.PointsMin, query.PointsMax)
+		consider(s.pointsIndex.count(keys), func(id string, count int) []string { return s.pointsIndex.after(keys, id, count) })
+	}
+	if query.PurchaseTimeFrom != nil || query.PurchaseTimeTo != nil {
+		from, to := query.PurchaseTimeFrom, query.PurchaseTimeTo
+		var keys []time.Time
+		if from != nil && to != nil && from.Compare(*to) > 0 { // the window wraps past midnight
+			keys = append(s.purchaseTimeIndex.keyRange(from, nil), s.purchaseTimeIndex.keyRange(nil, to)...)
+		} else {
+			keys = s.purchaseTimeIndex.keyRange(from, to)
+		}
+		consider(s.purchaseTimeIndex.count(keys), func(id string, count int) []string { return s.purchaseTimeIndex.after(keys, id, count) })
+	}
+	if query.ItemDescription != "" {
+		runs := textSearchRuns(query.ItemDescription)
+		consider(s.itemTextIndex.count(runs), func(id string, count int) []string { return s.itemTextIndex.after(runs, id, count) })
+	}
+	return readCandidates, indexed
+}
+
+func (s *MemoryStore) Replace(storedReceipt StoredReceipt) (StoredReceipt, StoredReceipt, error) {
+	return s.replace(storedReceipt, systime.Now().UTC())
+}
+
+// Replaces the receipt, recording the replaced version as revised at the given time, so the file store can replay a replacement with the time it originally happened
+func (s *MemoryStore) replace(storedReceipt StoredReceipt, revisedAt systime.Time) (StoredReceipt, StoredReceipt, error) {
+	id := storedReceipt.Receipt.Id
+	if id == "" {
+		return StoredReceipt{}, StoredReceipt{}, ErrEmptyReceiptId
+	}
+	shard := s.getShard(id)
+	shard.Lock()
+	defer shard.Unlock()
+	previous, containsKey := shard.get(id)
+	if !containsKey {
+		return StoredReceipt{}, StoredReceipt{}, fmt.Errorf("%w given \"%s\"", ErrReceiptNotFound, id)
+	}
+	storedReceipt.Version = previous.EffectiveVersion() + 1
+	storedReceipt.IdempotencyKey = previous.IdempotencyKey
+	storedReceipt.UserId = previous.UserId
+	s.addRevision(id, ReceiptRevision{StoredReceipt: previous, RevisedAt: revisedAt})
+	s.putLocked(shard, storedReceipt)
+	return previous, storedReceipt, nil
+}
+
+func (s *MemoryStore) Delete(id string) (StoredReceipt, error) {
+	return s.delete(id, systime.Now().UTC())
+}
+
+// Deletes the receipt, recording it as revised at the given time
+func (s *MemoryStore) delete(id string, revisedAt systime.Time) (StoredReceipt, error) {
+	shard := s.getShard(id)
+	shard.Lock()
+	defer shard.Unlock()
+	storedReceipt, containsKey := shard.get(id)
+	if !containsKey {
+		return StoredReceipt{}, fmt.Errorf("%w given \"%s\"", ErrReceiptNotFound, id)
+	}
+	delete(shard.receiptMap, id)
+	delete(shard.pointsMap, id)
+	s.reindex(&storedReceipt, nil)
+	s.addRevision(id, ReceiptRevision{StoredReceipt: storedReceipt, Deleted: true, RevisedAt: revisedAt})
+	return storedReceipt, nil
+}
+
+func (s *MemoryStore) History(id string) ([]ReceiptRevision, error) {
+	s.historyMutex.RLock()
+	revisions := append([]ReceiptRevision{}, s.histories[id]...)
+	s.historyMutex.RUnlock()
+	if len(revisions) > 0 {
+		return revisions, nil
+	}
+	shard := s.getShard(id)
+	shard.RLock()
+	defer shard.RUnlock()
+	if _, containsKey := shard.get(id); !containsKey {
+		return nil, fmt.Errorf("%w given \"%s\"", ErrReceiptNotFound, id)
+	}
+	return revisions, nil
+}
+
+// Adds a revision to the end of a receipt's history, also used to restore histories from the file store's snapshot
+func (s *MemoryStore) addRevision(id string, revision ReceiptRevision) {
+	s.historyMutex.Lock()
+	defer s.historyMutex.Unlock()
+	s.histories[id] = append(s.histories[id], revision)
+}
+
+// Returns a copy of the history of every amended or deleted receipt
+func (s *MemoryStore) allHistories() map[string][]ReceiptRevision {
+	s.historyMutex.RLock()
+	defer s.historyMutex.RUnlock()
+	histories := make(map[string][]ReceiptRevision, len(s.histories))
+	for id, revisions := range s.histories {
+		histories[id] = append([]ReceiptRevision{}, revisions...)
+	}
+	return histories
+}
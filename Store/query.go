@@ -0,0 +1,139 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	time "go-receipt-processor/Time"
+	"strings"
+)
+
+var (
+	ErrUnknownRetailerMatch = errors.New("unknown retailer match")
+)
+
+// How the retailer of a Query is compared with the retailer of each receipt
+type RetailerMatch string
+
+const (
+	ExactRetailer  RetailerMatch = "exact"
+	PrefixRetailer RetailerMatch = "prefix"
+)
+
+// Parses a retailer match, an empty string is an exact match
+func ParseRetailerMatch(str string) (RetailerMatch, error) {
+	switch match := RetailerMatch(str); match {
+	case "":
+		return ExactRetailer, nil
+	case ExactRetailer, PrefixRetailer:
+		return match, nil
+	}
+	return "", fmt.Errorf("%w given \"%s\" ... expected %s or %s", ErrUnknownRetailerMatch, str, ExactRetailer, PrefixRetailer)
+}
+
+// Filters for searching stored receipts, every filter that is set must match. Ranges include both of their ends, and a nil end leaves that side of the range open.
+type Query struct {
+	Retailer           string // empty matches every retailer
+	RetailerMatch      RetailerMatch
+	RetailerIgnoreCase bool
+
+	PurchaseDateFrom *date.Date
+	PurchaseDateTo   *date.Date
+	// A window of the day the purchase time is in, as it was written on the receipt. A window whose start is after its end wraps past midnight, i.e. 22:00 to 02:00.
+	PurchaseTimeFrom *time.Time
+	PurchaseTimeTo   *time.Time
+	Currency         money.Currency // empty matches every currency
+	TotalMin         *money.Money   // compared in each receipt's own currency, so only meaningful along with a currency
+	TotalMax         *money.Money
+	PointsMin        *int64
+	PointsMax        *int64
+
+	ItemDescription string // matches receipts with an item whose description contains it, ignoring case
+
+	// Receipts are returned ordered by id, starting after this id, so the last id of one page is where the next page starts
+	AfterId string
+	// The most receipts returned at once, zero or less returns every match
+	Limit int
+}
+
+// A single page of search results
+type Page struct {
+	Receipts []StoredReceipt
+	// The id the next page starts after, or empty when there are no more receipts
+	NextAfterId string
+}
+
+// Folds the case of a retailer or description the same way for every store, so the same receipts match a case-insensitive search whichever store they are in
+func foldCase(str string) string {
+	return strings.ToLower(str)
+}
+
+func (q Query) matchesRetailer(retailer string) bool {
+	wanted := q.Retailer
+	if q.RetailerIgnoreCase {
+		retailer, wanted = foldCase(retailer), foldCase(wanted)
+	}
+	if q.RetailerMatch == PrefixRetailer {
+		return strings.HasPrefix(retailer, wanted)
+	}
+	return retailer == wanted
+}
+
+// Reports whether the time falls within the window, wrapping past midnight when the window starts after it ends
+func inTimeWindow(t time.Time, from *time.Time, to *time.Time) bool {
+	afterFrom := from == nil || t.Compare(*from) >= 0
+	beforeTo := to == nil || t.Compare(*to) <= 0
+	if from != nil && to != nil && from.Compare(*to) > 0 {
+		return afterFrom || beforeTo
+	}
+	return afterFrom && beforeTo
+}
+
+// Reports whether the stored receipt matches every filter of the query, ignoring where the page starts and its limit
+func (q Query) Matches(storedReceipt StoredReceipt) bool {
+	r := storedReceipt.Receipt
+	switch {
+	case q.Retailer != "" && !q.matchesRetailer(r.Retailer):
+		return false
+	case q.PurchaseDateFrom != nil && r.PurchaseDate.Compare(*q.PurchaseDateFrom) < 0:
+		return false
+	case q.PurchaseDateTo != nil && r.PurchaseDate.Compare(*q.PurchaseDateTo) > 0:
+		return false
+	case !inTimeWindow(r.PurchaseTime, q.PurchaseTimeFrom, q.PurchaseTimeTo):
+		return false
+	case q.Currency != "" && r.Currency != q.Currency:
+		return false
+	case q.TotalMin != nil && r.Total < *q.TotalMin:
+		return false
+	case q.TotalMax != nil && r.Total > *q.TotalMax:
+		return false
+	case q.PointsMin != nil && storedReceipt.Points < *q.PointsMin:
+		return false
+	case q.PointsMax != nil && storedReceipt.Points > *q.PointsMax:
+		return false
+	}
+	if q.ItemDescription == "" {
+		return true
+	}
+	wanted := foldCase(q.ItemDescription)
+	for _, item := range r.Items {
+		if strings.Contains(foldCase(item.ShortDescription), wanted) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the smallest string greater than every string that starts with the prefix, or false if there is none ( i.e. the prefix is empty or only 0xff bytes ).
+// Lets a prefix match be answered with a range over a sorted index.
+func prefixUpperBound(prefix string) (string, bool) {
+	bound := []byte(prefix)
+	for i := len(bound) - 1; i >= 0; i-- {
+		if bound[i] < 0xff {
+			bound[i]++
+			return string(bound[:i+1]), true
+		}
+	}
+	return "", false
+}
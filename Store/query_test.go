@@ -0,0 +1,268 @@
+package store
+
+import (
+	"fmt"
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	receipt "go-receipt-processor/Receipt"
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	utils "go-receipt-processor/TestingUtils"
+	time "go-receipt-processor/Time"
+	"strings"
+	"testing"
+)
+
+var searchedReceipts []StoredReceipt = []StoredReceipt{
+	newSearchedReceipt("r1", "Target", date.Date{Year: 2022, Month: 1, Day: 1}, time.Time{Hour: 13, Minute: 1}, 649, 12, "Mountain Dew 12PK"),
+	newSearchedReceipt("r2", "target", date.Date{Year: 2022, Month: 1, Day: 15}, time.Time{Hour: 23, Minute: 30}, 1225, 40, "Emils Cheese Pizza"),
+	newSearchedReceipt("r3", "Target Express", date.Date{Year: 2022, Month: 2, Day: 1}, time.Time{Hour: 1, Minute: 15, Second: 30}, 126, 75, "Knorr Creamy Chicken"),
+	newSearchedReceipt("r4", "Walgreens", date.Date{Year: 2022, Month: 3, Day: 20}, time.Time{Hour: 14, Minute: 33}, 900, 109, "Gatorade"),
+	newSearchedReceipt("r5", "\u00dcber Markt", date.Date{Year: 2023, Month: 1, Day: 1}, time.Time{Hour: 9}, 335, 5, "DORITOS Nacho Cheese"),
+}
+
+func newSearchedReceipt(id string, retailer string, purchaseDate date.Date, purchaseTime time.Time, total money.Money, points int64, description string) StoredReceipt {
+	return StoredReceipt{
+		Receipt: receipt.Receipt{Id: id, Retailer: retailer, PurchaseDate: purchaseDate, PurchaseTime: purchaseTime, Total: total, Currency: money.USD,
+			Items: []receiptitem.ReceiptItem{{ShortDescription: description, Price: total}}},
+		Points:         points,
+		RulesetVersion: 1,
+	}
+}
+
+func pointerTo[T any](value T) *T {
+	return &value
+}
+
+// The ids of each page of a search, following the pages until there are none left
+func searchAllPages(t *testing.T, receiptStore ReceiptStore, query Query) [][]string {
+	pages := [][]string{}
+	for {
+		page, err := receiptStore.Search(query)
+		if err != nil {
+			t.Fatalf("search ( %+v ): unexpected error ( %v )", query, err)
+		}
+		ids := []string{}
+		for _, storedReceipt := range page.Receipts {
+			ids = append(ids, storedReceipt.Receipt.Id)
+		}
+		pages = append(pages, ids)
+		if page.NextAfterId == "" {
+			return pages
+		}
+		query.AfterId = page.NextAfterId
+	}
+}
+
+// Runs the same searches against any ReceiptStore implementation
+func testSearch(t *testing.T, receiptStore ReceiptStore) {
+	for _, storedReceipt := range searchedReceipts {
+		if err := receiptStore.Put(storedReceipt); err != nil {
+			t.Fatalf("put ( %+v ): unexpected error ( %v )", storedReceipt, err)
+		}
+	}
+
+	var testCases []utils.CreationTestingData[Query, [][]string] = []utils.CreationTestingData[Query, [][]string]{
+		{Argument: Query{}, ExpectedResult: [][]string{{"r1", "r2", "r3", "r4", "r5"}}},
+		{Argument: Query{Limit: 2}, ExpectedResult: [][]string{{"r1", "r2"}, {"r3", "r4"}, {"r5"}}},
+		{Argument: Query{Limit: 5}, ExpectedResult: [][]string{{"r1", "r2", "r3", "r4", "r5"}}},
+		{Argument: Query{AfterId: "r3"}, ExpectedResult: [][]string{{"r4", "r5"}}},
+
+		{Argument: Query{Retailer: "Target"}, ExpectedResult: [][]string{{"r1"}}},
+		{Argument: Query{Retailer: "target", RetailerIgnoreCase: true}, ExpectedResult: [][]string{{"r1", "r2"}}},
+		{Argument: Query{Retailer: "Target", RetailerMatch: PrefixRetailer}, ExpectedResult: [][]string{{"r1", "r3"}}},
+		{Argument: Query{Retailer: "TARGET", RetailerMatch: PrefixRetailer, RetailerIgnoreCase: true, Limit: 2}, ExpectedResult: [][]string{{"r1", "r2"}, {"r3"}}},
+		{Argument: Query{Retailer: "\u00fcber", RetailerMatch: PrefixRetailer, RetailerIgnoreCase: true}, ExpectedResult: [][]string{{"r5"}}},
+		{Argument: Query{Retailer: "Costco"}, ExpectedResult: [][]string{{}}},
+
+		{Argument: Query{PurchaseDateFrom: &date.Date{Year: 2022, Month: 1, Day: 15}, PurchaseDateTo: &date.Date{Year: 2022, Month: 3, Day: 20}}, ExpectedResult: [][]string{{"r2", "r3", "r4"}}},
+		{Argument: Query{PurchaseDateFrom: &date.Date{Year: 2022, Month: 2, Day: 2}}, ExpectedResult: [][]string{{"r4", "r5"}}},
+		{Argument: Query{PurchaseDateTo: &date.Date{Year: 2021, Month: 12, Day: 31}}, ExpectedResult: [][]string{{}}},
+		{Argument: Query{PurchaseTimeFrom: &time.Time{Hour: 13}, PurchaseTimeTo: &time.Time{Hour: 14, Minute: 33}}, ExpectedResult: [][]string{{"r1", "r4"}}},
+		{ //Tests a window that wraps past midnight, including a time with seconds
+			Argument:       Query{PurchaseTimeFrom: &time.Time{Hour: 22}, PurchaseTimeTo: &time.Time{Hour: 1, Minute: 15, Second: 30}},
+			ExpectedResult: [][]string{{"r2", "r3"}},
+		},
+		{Argument: Query{PurchaseTimeFrom: &time.Time{Hour: 1, Minute: 15, Second: 31}, PurchaseTimeTo: &time.Time{Hour: 9}}, ExpectedResult: [][]string{{"r5"}}},
+		{Argument: Query{TotalMin: pointerTo(money.Money(335)), TotalMax: pointerTo(money.Money(900))}, ExpectedResult: [][]string{{"r1", "r4", "r5"}}},
+		{Argument: Query{Currency: money.USD, TotalMin: pointerTo(money.Money(900))}, ExpectedResult: [][]string{{"r2", "r4"}}},
+		{Argument: Query{Currency: money.CAD}, ExpectedResult: [][]string{{}}},
+		{Argument: Query{PointsMin: pointerTo(int64(40))}, ExpectedResult: [][]string{{"r2", "r3", "r4"}}},
+		{Argument: Query{PointsMax: pointerTo(int64(12))}, ExpectedResult: [][]string{{"r1", "r5"}}},
+		{Argument: Query{ItemDescription: "cheese"}, ExpectedResult: [][]string{{"r2", "r5"}}},
+		{Argument: Query{ItemDescription: "cheese", Retailer: "target", RetailerIgnoreCase: true, PurchaseDateFrom: &date.Date{Year: 2022, Month: 1, Day: 1}}, ExpectedResult: [][]string{{"r2"}}},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("search", searchAllPages(t, receiptStore, testCase.Argument), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	// a receipt that is changed or removed is found by its new values, and not by its old ones
+	changed := searchedReceipts[0]
+	changed.Receipt.Retailer = "Costco"
+	receiptStore.Put(changed)
+	receiptStore.Delete("r2")
+	var changedTestCases []utils.CreationTestingData[Query, [][]string] = []utils.CreationTestingData[Query, [][]string]{
+		{Argument: Query{Retailer: "target", RetailerIgnoreCase: true}, ExpectedResult: [][]string{{}}},
+		{Argument: Query{Retailer: "Costco"}, ExpectedResult: [][]string{{"r1"}}},
+		{Argument: Query{PurchaseDateTo: &date.Date{Year: 2022, Month: 1, Day: 31}}, ExpectedResult: [][]string{{"r1"}}},
+	}
+	for _, testCase := range changedTestCases {
+		errCheck := testCase.CheckTestCase("search after changes", searchAllPages(t, receiptStore, testCase.Argument), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_MemoryStoreSearch(t *testing.T) {
+	testSearch(t, NewMemoryStore())
+}
+
+// Searches on an indexed filter should only check the receipts in the most selective index that applies
+func Test_MemoryStoreSearchUsesIndexes(t *testing.T) {
+	memoryStore := NewMemoryStore()
+	for _, storedReceipt := range searchedReceipts {
+		memoryStore.Put(storedReceipt)
+	}
+	var testCases []utils.CreationTestingData[Query, []string] = []utils.CreationTestingData[Query, []string]{
+		{Argument: Query{TotalMax: pointerTo(money.Money(335))}, ExpectedResult: []string{"r3", "r5"}},
+		{Argument: Query{TotalMin: pointerTo(money.Money(900)), TotalMax: pointerTo(money.Money(1225))}, ExpectedResult: []string{"r2", "r4"}},
+		{Argument: Query{PointsMin: pointerTo(int64(40))}, ExpectedResult: []string{"r2", "r3", "r4"}},
+		{Argument: Query{Retailer: "Walgreens", PointsMin: pointerTo(int64(40))}, ExpectedResult: []string{"r4"}},
+		{Argument: Query{PurchaseDateFrom: &date.Date{Year: 2023, Month: 1, Day: 1}, PointsMax: pointerTo(int64(40))}, ExpectedResult: []string{"r5"}},
+		{Argument: Query{PurchaseTimeFrom: &time.Time{Hour: 22}, PurchaseTimeTo: &time.Time{Hour: 1, Minute: 15, Second: 30}}, ExpectedResult: []string{"r2", "r3"}},
+		{Argument: Query{PurchaseTimeFrom: &time.Time{Hour: 13}, PurchaseTimeTo: &time.Time{Hour: 13, Minute: 1}, PointsMin: pointerTo(int64(40))}, ExpectedResult: []string{"r1"}},
+		{Argument: Query{ItemDescription: "CHEESE"}, ExpectedResult: []string{"r2", "r5"}},
+		{Argument: Query{ItemDescription: "ees"}, ExpectedResult: []string{"r2", "r5"}},
+		{Argument: Query{ItemDescription: "cheese pizza"}, ExpectedResult: []string{"r2"}},
+		{Argument: Query{ItemDescription: "cheeses"}, ExpectedResult: []string{}},
+		{Argument: Query{Currency: money.USD}, ExpectedResult: []string{"r1", "r2", "r3", "r4", "r5"}}, // not indexed, so paged through by id
+	}
+	for _, testCase := range testCases {
+		readCandidates, _ := memoryStore.findCandidates(testCase.Argument)
+		candidates := readCandidates("", len(searchedReceipts)+1)
+		errCheck := testCase.CheckTestCase("find candidates", candidates, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+// Pages through more receipts than are read from the id index at a time
+func Test_MemoryStoreSearchPagesThroughIds(t *testing.T) {
+	memoryStore := NewMemoryStore()
+	receiptCount := memorySearchChunkSize*2 + 100
+	for i := 0; i < receiptCount; i++ {
+		memoryStore.Put(newSearchedReceipt(fmt.Sprintf("r%04d", i), "Target", date.Date{Year: 2022, Month: 1, Day: 1}, time.Time{Hour: 13}, money.Money(i), int64(i), "Gatorade"))
+	}
+	memoryStore.Delete("r0300")
+
+	var testCases []utils.CreationTestingData[Query, []int] = []utils.CreationTestingData[Query, []int]{
+		{Argument: Query{Limit: 250}, ExpectedResult: []int{250, 250, receiptCount - 501}},
+		{Argument: Query{Limit: receiptCount, AfterId: "r0099"}, ExpectedResult: []int{receiptCount - 101}},
+		{Argument: Query{Currency: money.USD, Limit: receiptCount}, ExpectedResult: []int{receiptCount - 1}},
+		{Argument: Query{TotalMin: pointerTo(money.Money(100)), TotalMax: pointerTo(money.Money(399)), Limit: 250}, ExpectedResult: []int{250, 49}},
+		{Argument: Query{PointsMin: pointerTo(int64(100)), Limit: 250, AfterId: "r0199"}, ExpectedResult: []int{250, receiptCount - 451}},
+		{Argument: Query{ItemDescription: "gator", Limit: 250}, ExpectedResult: []int{250, 250, receiptCount - 501}},
+		{Argument: Query{PurchaseTimeFrom: &time.Time{Hour: 13}, Limit: 250}, ExpectedResult: []int{250, 250, receiptCount - 501}},
+	}
+	for _, testCase := range testCases {
+		pageSizes := []int{}
+		previousId := testCase.Argument.AfterId
+		for _, page := range searchAllPages(t, memoryStore, testCase.Argument) {
+			pageSizes = append(pageSizes, len(page))
+			for _, id := range page {
+				if id <= previousId || id == "r0300" {
+					t.Fatalf("search ( %+v ): expected ids in order without the deleted receipt got ( %s ) after ( %s )", testCase.Argument, id, previousId)
+				}
+				previousId = id
+			}
+		}
+		errCheck := testCase.CheckTestCase("search page sizes", pageSizes, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_FileStoreSearch(t *testing.T) {
+	fileStore := openTestFileStore(t, t.TempDir())
+	defer fileStore.Close()
+	testSearch(t, fileStore)
+}
+
+func Test_SQLStoreSearch(t *testing.T) {
+	sqlStore, db := openTestSQLStore(t, t.TempDir()+"/receipts.db")
+	defer db.Close()
+	testSearch(t, sqlStore)
+}
+
+func Test_ParseRetailerMatch(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, RetailerMatch] = []utils.CreationTestingData[string, RetailerMatch]{
+		{Argument: "", ExpectedResult: ExactRetailer},
+		{Argument: "exact", ExpectedResult: ExactRetailer},
+		{Argument: "prefix", ExpectedResult: PrefixRetailer},
+		{Argument: "suffix", ExpectedResult: "", ExpectedErr: ErrUnknownRetailerMatch},
+	}
+	for _, testCase := range testCases {
+		result, err := ParseRetailerMatch(testCase.Argument)
+		errCheck := testCase.CheckTestCase("parse retailer match", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_PrefixUpperBound(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, string] = []utils.CreationTestingData[string, string]{
+		{Argument: "target", ExpectedResult: "targeu"},
+		{Argument: "a\xff", ExpectedResult: "b"},
+		{Argument: "\xff\xff", ExpectedResult: ""},
+		{Argument: "", ExpectedResult: ""},
+	}
+	for _, testCase := range testCases {
+		result, _ := prefixUpperBound(testCase.Argument)
+		errCheck := testCase.CheckTestCase("prefix upper bound", result, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+// Searches on a single indexed filter should be answered from its index rather than a scan of every receipt
+func Test_SQLStoreSearchUsesIndexes(t *testing.T) {
+	_, db := openTestSQLStore(t, t.TempDir()+"/receipts.db")
+	defer db.Close()
+	var testCases []utils.CreationTestingData[Query, string] = []utils.CreationTestingData[Query, string]{
+		{Argument: Query{Retailer: "Target"}, ExpectedResult: "receipts_retailer"},
+		{Argument: Query{Retailer: "tar", RetailerMatch: PrefixRetailer, RetailerIgnoreCase: true}, ExpectedResult: "receipts_retailer_folded"},
+		{Argument: Query{PurchaseDateFrom: &date.Date{Year: 2022, Month: 1, Day: 1}}, ExpectedResult: "receipts_purchase_date"},
+		{Argument: Query{PointsMin: pointerTo(int64(100))}, ExpectedResult: "receipts_points"},
+		{Argument: Query{TotalMax: pointerTo(money.Money(100))}, ExpectedResult: "receipts_total_cents"},
+	}
+	for _, testCase := range testCases {
+		conditions, args := buildSearchConditions(testCase.Argument)
+		conditions = conditions[1:] // without the page start, which every query has
+		args = args[1:]
+		rows, err := db.Query(`EXPLAIN QUERY PLAN SELECT id FROM receipts WHERE `+strings.Join(conditions, ` AND `), args...)
+		if err != nil {
+			t.Fatalf("explain search ( %+v ): unexpected error ( %v )", testCase.Argument, err)
+		}
+		usedIndex := ""
+		for rows.Next() {
+			var id, parent, unused int
+			var detail string
+			rows.Scan(&id, &parent, &unused, &detail)
+			if _, after, found := strings.Cut(detail, "USING INDEX "); found {
+				usedIndex, _, _ = strings.Cut(after, " ")
+			}
+		}
+		rows.Close()
+		errCheck := testCase.CheckTestCase("search index", usedIndex, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
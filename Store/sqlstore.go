@@ -0,0 +1,663 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	money "go-receipt-processor/Money"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	time "go-receipt-processor/Time"
+	"strings"
+	"sync"
+	systime "time"
+)
+
+var (
+	ErrMigratingSchema = errors.New("migrating sql schema")
+	ErrQueryingStore   = errors.New("querying sql store")
+)
+
+// A single versioned change to the sql schema. Migrations are applied in order and each one is only ever applied once per database.
+type migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+// Every schema change made to the sql store, in order. Existing migrations should never be edited, any further change to the schema gets a new version appended to the end.
+var migrations = []migration{
+	{
+		Version:     1,
+		Description: "create receipts and receipt items",
+		Statements: []string{
+			`CREATE TABLE receipts (
+				id TEXT PRIMARY KEY,
+				retailer TEXT NOT NULL,
+				purchase_date TEXT NOT NULL,
+				purchase_time TEXT NOT NULL,
+				total REAL NOT NULL,
+				points INTEGER NOT NULL
+			)`,
+			`CREATE TABLE receipt_items (
+				receipt_id TEXT NOT NULL REFERENCES receipts(id),
+				position INTEGER NOT NULL,
+				short_description TEXT NOT NULL,
+				price REAL NOT NULL,
+				PRIMARY KEY (receipt_id, position)
+			)`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "index receipts by retailer and purchase date",
+		Statements: []string{
+			`CREATE INDEX receipts_retailer ON receipts (retailer)`,
+			`CREATE INDEX receipts_purchase_date ON receipts (purchase_date, purchase_time)`,
+		},
+	},
+	{
+		Version:     3,
+		Description: "record the points ruleset version each receipt was scored with",
+		Statements: []string{
+			// receipts stored before this migration were all scored with the original rules, version 1
+			`ALTER TABLE receipts ADD COLUMN ruleset_version INTEGER NOT NULL DEFAULT 1`,
+		},
+	},
+	{
+		Version:     4,
+		Description: "store totals and prices as whole cents instead of floating point",
+		Statements: []string{
+			`ALTER TABLE receipts ADD COLUMN total_cents INTEGER NOT NULL DEFAULT 0`,
+			`UPDATE receipts SET total_cents = CAST(ROUND(total * 100) AS INTEGER)`,
+			`ALTER TABLE receipts DROP COLUMN total`,
+			`ALTER TABLE receipt_items ADD COLUMN price_cents INTEGER NOT NULL DEFAULT 0`,
+			`UPDATE receipt_items SET price_cents = CAST(ROUND(price * 100) AS INTEGER)`,
+			`ALTER TABLE receipt_items DROP COLUMN price`,
+		},
+	},
+	{
+		Version:     5,
+		Description: "record the currency of each receipt, with totals and prices in its minor unit",
+		Statements: []string{
+			// receipts stored before this migration were all in dollars
+			`ALTER TABLE receipts ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD'`,
+		},
+	},
+	{
+		Version:     6,
+		Description: "record the exchange rate each receipt's amounts were converted with before being scored",
+		Statements: []string{
+			// all three are null for receipts that were scored in their own currency
+			`ALTER TABLE receipts ADD COLUMN exchange_rate TEXT`,
+			`ALTER TABLE receipts ADD COLUMN exchange_rate_currency TEXT`,
+			`ALTER TABLE receipts ADD COLUMN exchange_rate_date TEXT`,
+		},
+	},
+	{
+		Version:     7,
+		Description: "record the time zone each receipt's purchase date and time were written in, and the zone of the store",
+		Statements: []string{
+			// an empty zone means none was given, which is how every receipt before this migration was submitted
+			`ALTER TABLE receipts ADD COLUMN time_zone TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE receipts ADD COLUMN store_time_zone TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		Version:     8,
+		Description: "record the content hash and idempotency key of each receipt so resubmissions can be recognized",
+		Statements: []string{
+			// receipts stored before this migration have no hash and no key, so they are never matched as duplicates
+			`ALTER TABLE receipts ADD COLUMN content_hash TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE receipts ADD COLUMN idempotency_key TEXT`,
+			`CREATE INDEX receipts_content_hash ON receipts (content_hash)`,
+			`CREATE UNIQUE INDEX receipts_idempotency_key ON receipts (idempotency_key)`,
+		},
+	},
+	{
+		Version:     9,
+		Description: "index the fields receipts can be searched by",
+		Statements: []string{
+			// sqlite's lower only folds ascii letters, receipts stored from now on have their case folded before they are written so every letter is folded
+			`ALTER TABLE receipts ADD COLUMN retailer_folded TEXT NOT NULL DEFAULT ''`,
+			`UPDATE receipts SET retailer_folded = lower(retailer)`,
+			`CREATE INDEX receipts_retailer_folded ON receipts (retailer_folded)`,
+			`CREATE INDEX receipts_purchase_time ON receipts (purchase_time)`,
+			`CREATE INDEX receipts_total_cents ON receipts (total_cents)`,
+			`CREATE INDEX receipts_points ON receipts (points)`,
+			`ALTER TABLE receipt_items ADD COLUMN short_description_folded TEXT NOT NULL DEFAULT ''`,
+			`UPDATE receipt_items SET short_description_folded = lower(short_description)`,
+		},
+	},
+	{
+		Version:     10,
+		Description: "number the versions of each receipt and keep the earlier versions of amended and deleted receipts",
+		Statements: []string{
+			// receipts stored before this migration have never been amended, so are all on their first version
+			`ALTER TABLE receipts ADD COLUMN version INTEGER NOT NULL DEFAULT 1`,
+			// each revision is kept whole as json, since it is only ever read back as a whole and never searched
+			`CREATE TABLE receipt_revisions (
+				receipt_id TEXT NOT NULL,
+				stored_receipt TEXT NOT NULL,
+				deleted INTEGER NOT NULL,
+				revised_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX receipt_revisions_receipt_id ON receipt_revisions (receipt_id)`,
+		},
+	},
+	{
+		Version:     11,
+		Description: "record which receipt each content hash is matched to, so only one receipt can be stored for the same content",
+		Statements: []string{
+			// amending a receipt can leave it with the same content as another, so the hash is unique here instead of in receipts, held by the first receipt stored with it
+			`CREATE TABLE receipt_content_hashes (
+				content_hash TEXT PRIMARY KEY,
+				receipt_id TEXT NOT NULL
+			)`,
+			`INSERT INTO receipt_content_hashes (content_hash, receipt_id)
+				SELECT content_hash, id FROM receipts WHERE rowid IN (SELECT MIN(rowid) FROM receipts WHERE content_hash != '' GROUP BY content_hash)`,
+			`CREATE INDEX receipt_content_hashes_receipt_id ON receipt_content_hashes (receipt_id)`,
+		},
+	},
+	{
+		Version:     12,
+		Description: "record the user who submitted each receipt, in the same write that stores it",
+		Statements: []string{
+			// receipts stored before this migration only have their user recorded in the points ledger, if they were credited at all
+			`ALTER TABLE receipts ADD COLUMN user_id TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+}
+
+// Either the database or a transaction, so receipts can be read the same way inside and outside of one
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// ReceiptStore backed by a sql database, with receipts and their line items kept in separate tables so they can be queried directly.
+// Uses "?" placeholders, so works with SQLite compatible drivers.
+// SQLite only allows a single writer at a time, so writes are serialized within the store, and the database should be opened with SQLiteDataSourceName so writes from other processes are waited on instead of failing.
+type SQLStore struct {
+	db         *sql.DB
+	writeMutex sync.Mutex // serializes write transactions
+}
+
+// Returns the data source name to open the SQLite database file at the path with.
+// Waits up to 5 seconds for a locked database instead of failing straight away, and takes the write lock when a transaction begins instead of on its first write, so a transaction never has to be abandoned part way through because another writer got in first.
+func SQLiteDataSourceName(path string) string {
+	return "file:" + path + "?_pragma=busy_timeout(5000)&_txlock=immediate"
+}
+
+// Creates a store on top of an already opened database, applying any schema migrations that have not been applied yet.
+func OpenSQLStore(db *sql.DB) (*SQLStore, error) {
+	s := &SQLStore{db: db}
+	if err := s.migrate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Returns the latest schema version that has been applied to the database, or 0 if none have.
+func (s *SQLStore) SchemaVersion() (int, error) {
+	var version sql.NullInt64
+	err := s.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	return int(version.Int64), nil
+}
+
+func (s *SQLStore) migrate() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrMigratingSchema, err)
+	}
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	currentVersion, err := s.SchemaVersion()
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrMigratingSchema, err)
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= currentVersion {
+			continue
+		}
+		if err := s.applyMigration(migration); err != nil {
+			return fmt.Errorf("%w to version %d ( %s ) ... %w", ErrMigratingSchema, migration.Version, migration.Description, err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLStore) applyMigration(migration migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, statement := range migration.Statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)`,
+		migration.Version, migration.Description, systime.Now().UTC().Format(systime.RFC3339))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLStore) Put(storedReceipt StoredReceipt) error {
+	if storedReceipt.Receipt.Id == "" {
+		return ErrEmptyReceiptId
+	}
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	defer tx.Rollback()
+
+	if err := putReceipt(tx, storedReceipt); err != nil {
+		return fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	return nil
+}
+
+func (s *SQLStore) PutIfAbsent(storedReceipt StoredReceipt) (StoredReceipt, bool, error) {
+	if storedReceipt.Receipt.Id == "" {
+		return StoredReceipt{}, false, ErrEmptyReceiptId
+	}
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return StoredReceipt{}, false, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	defer tx.Rollback()
+
+	// a matching idempotency key takes priority over a matching hash, which is matched to the first receipt stored with the content
+	var existingId string
+	err = tx.QueryRow(`SELECT id FROM (
+			SELECT id, 0 AS priority FROM receipts WHERE idempotency_key = ?
+			UNION ALL SELECT receipt_id, 1 FROM receipt_content_hashes WHERE content_hash = ? AND content_hash != ''
+		) ORDER BY priority LIMIT 1`,
+		storedReceipt.IdempotencyKey, storedReceipt.ContentHash).Scan(&existingId)
+	if err == nil {
+		tx.Rollback()
+		existing, err := s.Get(existingId)
+		return existing, err == nil, err
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return StoredReceipt{}, false, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	if err := putReceipt(tx, storedReceipt); err != nil {
+		return StoredReceipt{}, false, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return StoredReceipt{}, false, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	return storedReceipt, false, nil
+}
+
+// Writes the receipt and its items within the transaction, replacing any existing entry with the same id
+func putReceipt(tx *sql.Tx, storedReceipt StoredReceipt) error {
+	r := storedReceipt.Receipt
+	if err := deleteReceipt(tx, r.Id); err != nil {
+		return err
+	}
+	var rate, rateCurrency, rateDate sql.NullString
+	if storedReceipt.ExchangeRate != nil {
+		rate = sql.NullString{String: storedReceipt.ExchangeRate.Rate, Valid: true}
+		rateCurrency = sql.NullString{String: storedReceipt.ExchangeRate.To.Code(), Valid: true}
+		rateDate = sql.NullString{String: storedReceipt.ExchangeRate.EffectiveDate, Valid: true}
+	}
+	idempotencyKey := sql.NullString{String: storedReceipt.IdempotencyKey, Valid: storedReceipt.IdempotencyKey != ""} // null, since every receipt without a key would otherwise clash in the unique index
+	_, err := tx.Exec(`INSERT INTO receipts (id, retailer, retailer_folded, purchase_date, purchase_time, total_cents, currency, points, ruleset_version, exchange_rate, exchange_rate_currency, exchange_rate_date, time_zone, store_time_zone, content_hash, idempotency_key, user_id, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Id, r.Retailer, foldCase(r.Retailer), r.PurchaseDate, r.PurchaseTime, r.Total.Cents(), r.Currency.Code(), storedReceipt.Points, storedReceipt.RulesetVersion, rate, rateCurrency, rateDate, r.TimeZone.String(), r.StoreTimeZone.String(), storedReceipt.ContentHash, idempotencyKey, storedReceipt.UserId, storedReceipt.Version)
+	if err != nil {
+		return err
+	}
+	if storedReceipt.ContentHash != "" {
+		// the first receipt stored with the content is the one resubmissions are matched to
+		_, err = tx.Exec(`INSERT INTO receipt_content_hashes (content_hash, receipt_id) VALUES (?, ?) ON CONFLICT (content_hash) DO NOTHING`, storedReceipt.ContentHash, r.Id)
+		if err != nil {
+			return err
+		}
+	}
+	for position, item := range r.Items {
+		_, err = tx.Exec(`INSERT INTO receipt_items (receipt_id, position, short_description, short_description_folded, price_cents) VALUES (?, ?, ?, ?, ?)`,
+			r.Id, position, item.ShortDescription, foldCase(item.ShortDescription), item.Price.Cents())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteReceipt(tx *sql.Tx, id string) error {
+	if _, err := tx.Exec(`DELETE FROM receipt_items WHERE receipt_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM receipt_content_hashes WHERE receipt_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`DELETE FROM receipts WHERE id = ?`, id)
+	return err
+}
+
+// Row scanner shared by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReceipt(row scanner) (StoredReceipt, error) {
+	storedReceipt := StoredReceipt{}
+	var totalCents int64
+	var currency string
+	var rate, rateCurrency, rateDate sql.NullString
+	var timeZone, storeTimeZone string
+	var idempotencyKey sql.NullString
+	err := row.Scan(&storedReceipt.Receipt.Id, &storedReceipt.Receipt.Retailer, &storedReceipt.Receipt.PurchaseDate, &storedReceipt.Receipt.PurchaseTime, &totalCents, &currency, &storedReceipt.Points, &storedReceipt.RulesetVersion, &rate, &rateCurrency, &rateDate, &timeZone, &storeTimeZone, &storedReceipt.ContentHash, &idempotencyKey, &storedReceipt.UserId, &storedReceipt.Version)
+	if err != nil {
+		return storedReceipt, err
+	}
+	storedReceipt.Receipt.Total = money.FromCents(totalCents)
+	storedReceipt.Receipt.Currency = money.Currency(currency)
+	storedReceipt.Receipt.TimeZone = time.Zone(timeZone)
+	storedReceipt.Receipt.StoreTimeZone = time.Zone(storeTimeZone)
+	storedReceipt.IdempotencyKey = idempotencyKey.String
+	if rate.Valid {
+		storedReceipt.ExchangeRate = &exchangerate.Rate{From: storedReceipt.Receipt.Currency, To: money.Currency(rateCurrency.String), EffectiveDate: rateDate.String, Rate: rate.String}
+	}
+	return storedReceipt, nil
+}
+
+// Fills in the items of each of the receipts from the receipt_items table
+func loadItems(q queryer, storedReceipts []StoredReceipt) error {
+	for i := range storedReceipts {
+		rows, err := q.Query(`SELECT short_description, price_cents FROM receipt_items WHERE receipt_id = ? ORDER BY position`, storedReceipts[i].Receipt.Id)
+		if err != nil {
+			return err
+		}
+		items := []receiptitem.ReceiptItem{}
+		for rows.Next() {
+			item := receiptitem.ReceiptItem{}
+			var priceCents int64
+			if err := rows.Scan(&item.ShortDescription, &priceCents); err != nil {
+				rows.Close()
+				return err
+			}
+			item.Price = money.FromCents(priceCents)
+			items = append(items, item)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		storedReceipts[i].Receipt.Items = items
+	}
+	return nil
+}
+
+const selectReceiptColumns = `SELECT id, retailer, purchase_date, purchase_time, total_cents, currency, points, ruleset_version, exchange_rate, exchange_rate_currency, exchange_rate_date, time_zone, store_time_zone, content_hash, idempotency_key, user_id, version FROM receipts`
+
+func (s *SQLStore) Get(id string) (StoredReceipt, error) {
+	return getReceipt(s.db, id)
+}
+
+// Reads the receipt and its items, or returns ErrReceiptNotFound if there is none
+func getReceipt(q queryer, id string) (StoredReceipt, error) {
+	storedReceipt, err := scanReceipt(q.QueryRow(selectReceiptColumns+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return StoredReceipt{}, fmt.Errorf("%w given \"%s\"", ErrReceiptNotFound, id)
+	} else if err != nil {
+		return StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	storedReceipts := []StoredReceipt{storedReceipt}
+	if err := loadItems(q, storedReceipts); err != nil {
+		return StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	return storedReceipts[0], nil
+}
+
+func (s *SQLStore) List() ([]StoredReceipt, error) {
+	rows, err := s.db.Query(selectReceiptColumns + ` ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	storedReceipts := []StoredReceipt{}
+	for rows.Next() {
+		storedReceipt, err := scanReceipt(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+		}
+		storedReceipts = append(storedReceipts, storedReceipt)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	if err := loadItems(s.db, storedReceipts); err != nil {
+		return nil, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	return storedReceipts, nil
+}
+
+// Builds the where clause and arguments for the query's filters and where its page starts
+func buildSearchConditions(query Query) ([]string, []any) {
+	conditions := []string{`id > ?`}
+	args := []any{query.AfterId}
+	if query.Retailer != "" {
+		column, retailer := `retailer`, query.Retailer
+		if query.RetailerIgnoreCase {
+			column, retailer = `retailer_folded`, foldCase(retailer)
+		}
+		if query.RetailerMatch == PrefixRetailer {
+			// a range rather than LIKE, so the index on the column can be used
+			conditions = append(conditions, column+` >= ?`)
+			args = append(args, retailer)
+			if upperBound, bounded := prefixUpperBound(retailer); bounded {
+				conditions = append(conditions, column+` < ?`)
+				args = append(args, upperBound)
+			}
+		} else {
+			conditions = append(conditions, column+` = ?`)
+			args = append(args, retailer)
+		}
+	}
+	if query.PurchaseDateFrom != nil {
+		conditions = append(conditions, `purchase_date >= ?`)
+		args = append(args, *query.PurchaseDateFrom)
+	}
+	if query.PurchaseDateTo != nil {
+		conditions = append(conditions, `purchase_date <= ?`)
+		args = append(args, *query.PurchaseDateTo)
+	}
+	// times are stored as HH:MM, or HH:MM:SS when they have seconds, which sort as text in the same order as the times
+	switch {
+	case query.PurchaseTimeFrom != nil && query.PurchaseTimeTo != nil && query.PurchaseTimeFrom.Compare(*query.PurchaseTimeTo) > 0:
+		conditions = append(conditions, `(purchase_time >= ? OR purchase_time <= ?)`)
+		args = append(args, *query.PurchaseTimeFrom, *query.PurchaseTimeTo)
+	default:
+		if query.PurchaseTimeFrom != nil {
+			conditions = append(conditions, `purchase_time >= ?`)
+			args = append(args, *query.PurchaseTimeFrom)
+		}
+		if query.PurchaseTimeTo != nil {
+			conditions = append(conditions, `purchase_time <= ?`)
+			args = append(args, *query.PurchaseTimeTo)
+		}
+	}
+	if query.Currency != "" {
+		conditions = append(conditions, `currency = ?`)
+		args = append(args, query.Currency.Code())
+	}
+	if query.TotalMin != nil {
+		conditions = append(conditions, `total_cents >= ?`)
+		args = append(args, query.TotalMin.Cents())
+	}
+	if query.TotalMax != nil {
+		conditions = append(conditions, `total_cents <= ?`)
+		args = append(args, query.TotalMax.Cents())
+	}
+	if query.PointsMin != nil {
+		conditions = append(conditions, `points >= ?`)
+		args = append(args, *query.PointsMin)
+	}
+	if query.PointsMax != nil {
+		conditions = append(conditions, `points <= ?`)
+		args = append(args, *query.PointsMax)
+	}
+	if query.ItemDescription != "" {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM receipt_items WHERE receipt_items.receipt_id = receipts.id AND instr(receipt_items.short_description_folded, ?) > 0)`)
+		args = append(args, foldCase(query.ItemDescription))
+	}
+	return conditions, args
+}
+
+func (s *SQLStore) Search(query Query) (Page, error) {
+	conditions, args := buildSearchConditions(query)
+	statement := selectReceiptColumns + ` WHERE ` + strings.Join(conditions, ` AND `) + ` ORDER BY id`
+	if query.Limit > 0 {
+		statement += ` LIMIT ?`
+		args = append(args, query.Limit+1) // one more than the page holds, to find out if there is another page
+	}
+	rows, err := s.db.Query(statement, args...)
+	if err != nil {
+		return Page{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	storedReceipts := []StoredReceipt{}
+	for rows.Next() {
+		storedReceipt, err := scanReceipt(rows)
+		if err != nil {
+			rows.Close()
+			return Page{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+		}
+		storedReceipts = append(storedReceipts, storedReceipt)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Page{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+
+	page := Page{Receipts: storedReceipts}
+	if query.Limit > 0 && len(storedReceipts) > query.Limit {
+		page.Receipts = storedReceipts[:query.Limit]
+		page.NextAfterId = page.Receipts[query.Limit-1].Receipt.Id
+	}
+	if err := loadItems(s.db, page.Receipts); err != nil {
+		return Page{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	return page, nil
+}
+
+func (s *SQLStore) Replace(storedReceipt StoredReceipt) (StoredReceipt, StoredReceipt, error) {
+	if storedReceipt.Receipt.Id == "" {
+		return StoredReceipt{}, StoredReceipt{}, ErrEmptyReceiptId
+	}
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return StoredReceipt{}, StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	defer tx.Rollback()
+
+	previous, err := getReceipt(tx, storedReceipt.Receipt.Id)
+	if err != nil {
+		return StoredReceipt{}, StoredReceipt{}, err
+	}
+	storedReceipt.Version = previous.EffectiveVersion() + 1
+	storedReceipt.IdempotencyKey = previous.IdempotencyKey
+	storedReceipt.UserId = previous.UserId
+	if err := insertRevision(tx, ReceiptRevision{StoredReceipt: previous, RevisedAt: systime.Now().UTC()}); err != nil {
+		return StoredReceipt{}, StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	if err := putReceipt(tx, storedReceipt); err != nil {
+		return StoredReceipt{}, StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return StoredReceipt{}, StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	return previous, storedReceipt, nil
+}
+
+func (s *SQLStore) Delete(id string) (StoredReceipt, error) {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	defer tx.Rollback()
+	storedReceipt, err := getReceipt(tx, id)
+	if err != nil {
+		return StoredReceipt{}, err
+	}
+	if err := insertRevision(tx, ReceiptRevision{StoredReceipt: storedReceipt, Deleted: true, RevisedAt: systime.Now().UTC()}); err != nil {
+		return StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	if err := deleteReceipt(tx, id); err != nil {
+		return StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return StoredReceipt{}, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	return storedReceipt, nil
+}
+
+func insertRevision(tx *sql.Tx, revision ReceiptRevision) error {
+	encoded, err := json.Marshal(revision.StoredReceipt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO receipt_revisions (receipt_id, stored_receipt, deleted, revised_at) VALUES (?, ?, ?, ?)`,
+		revision.Receipt.Id, string(encoded), revision.Deleted, revision.RevisedAt.Format(systime.RFC3339Nano))
+	return err
+}
+
+func (s *SQLStore) History(id string) ([]ReceiptRevision, error) {
+	rows, err := s.db.Query(`SELECT stored_receipt, deleted, revised_at FROM receipt_revisions WHERE receipt_id = ? ORDER BY rowid`, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	defer rows.Close()
+	revisions := []ReceiptRevision{}
+	for rows.Next() {
+		revision := ReceiptRevision{}
+		var encoded, revisedAt string
+		if err := rows.Scan(&encoded, &revision.Deleted, &revisedAt); err != nil {
+			return nil, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+		}
+		if err := json.Unmarshal([]byte(encoded), &revision.StoredReceipt); err != nil {
+			return nil, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+		}
+		if revision.RevisedAt, err = systime.Parse(systime.RFC3339Nano, revisedAt); err != nil {
+			return nil, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w ... %w", ErrQueryingStore, err)
+	}
+	if len(revisions) > 0 {
+		return revisions, nil
+	}
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"database/sql"
+	money "go-receipt-processor/Money"
+	receipt "go-receipt-processor/Receipt"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func openTestSQLStore(t *testing.T, path string) (*SQLStore, *sql.DB) {
+	db, err := sql.Open("sqlite", SQLiteDataSourceName(path))
+	if err != nil {
+		t.Fatalf("open sqlite ( %s ): unexpected error ( %v )", path, err)
+	}
+	sqlStore, err := OpenSQLStore(db)
+	if err != nil {
+		db.Close()
+		t.Fatalf("open sql store ( %s ): unexpected error ( %v )", path, err)
+	}
+	return sqlStore, db
+}
+
+func Test_SQLStore(t *testing.T) {
+	sqlStore, db := openTestSQLStore(t, filepath.Join(t.TempDir(), "receipts.db"))
+	defer db.Close()
+	testReceiptStore(t, sqlStore)
+}
+
+func Test_SQLStoreConcurrentAccess(t *testing.T) {
+	sqlStore, db := openTestSQLStore(t, filepath.Join(t.TempDir(), "receipts.db"))
+	defer db.Close()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*20)
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				id := strconv.Itoa(worker) + "-" + strconv.Itoa(i)
+				if err := sqlStore.Put(StoredReceipt{Receipt: receipt.Receipt{Id: id}, Points: int64(i)}); err != nil {
+					errs <- err
+					continue
+				}
+				sqlStore.Get(id)
+				sqlStore.List()
+				if i%2 == 0 {
+					if _, err := sqlStore.Delete(id); err != nil {
+						errs <- err
+					}
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent access: unexpected error ( %v )", err)
+	}
+	listed, _ := sqlStore.List()
+	if len(listed) != 8*10 {
+		t.Fatalf("concurrent access: expected %d stored receipts got %d", 8*10, len(listed))
+	}
+}
+
+func Test_SQLStoreConcurrentPutIfAbsent(t *testing.T) {
+	sqlStore, db := openTestSQLStore(t, filepath.Join(t.TempDir(), "receipts.db"))
+	defer db.Close()
+	testConcurrentPutIfAbsent(t, sqlStore, 8, 20)
+}
+
+func Test_SQLStoreMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "receipts.db")
+	sqlStore, db := openTestSQLStore(t, path)
+	version, err := sqlStore.SchemaVersion()
+	if err != nil || version != migrations[len(migrations)-1].Version {
+		t.Fatalf("schema version: expected ( %d ) got ( %d ) with error ( %v )", migrations[len(migrations)-1].Version, version, err)
+	}
+	sqlStore.Put(storedReceipts[0])
+	db.Close()
+
+	// reopening an already migrated database should neither fail nor reapply any migrations
+	sqlStore, db = openTestSQLStore(t, path)
+	defer db.Close()
+	var appliedCount int
+	db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&appliedCount)
+	if appliedCount != len(migrations) {
+		t.Fatalf("reopen: expected %d applied migrations got %d", len(migrations), appliedCount)
+	}
+	if _, err := sqlStore.Get(storedReceipts[0].Receipt.Id); err != nil {
+		t.Fatalf("reopen: expected receipt to still be stored ... %v", err)
+	}
+}
+
+func Test_SQLStoreUpgradesOlderSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "receipts.db")
+	db, _ := sql.Open("sqlite", path)
+	defer db.Close()
+	oldStore := &SQLStore{db: db}
+	oldStore.db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, description TEXT NOT NULL, applied_at TEXT NOT NULL)`)
+	if err := oldStore.applyMigration(migrations[0]); err != nil {
+		t.Fatalf("apply first migration: unexpected error ( %v )", err)
+	}
+
+	sqlStore, err := OpenSQLStore(db)
+	if err != nil {
+		t.Fatalf("upgrade: unexpected error ( %v )", err)
+	}
+	version, _ := sqlStore.SchemaVersion()
+	if version != migrations[len(migrations)-1].Version {
+		t.Fatalf("upgrade: expected schema version ( %d ) got ( %d )", migrations[len(migrations)-1].Version, version)
+	}
+}
+
+// Analysts query the tables directly, so the layout of a stored receipt is part of the store's contract
+func Test_SQLStoreTables(t *testing.T) {
+	sqlStore, db := openTestSQLStore(t, filepath.Join(t.TempDir(), "receipts.db"))
+	defer db.Close()
+	for _, storedReceipt := range storedReceipts {
+		sqlStore.Put(storedReceipt)
+	}
+	sqlStore.Put(storedReceipts[0]) // replacing a receipt should not duplicate its items
+
+	var retailer, purchaseDate, purchaseTime string
+	var points int64
+	db.QueryRow(`SELECT retailer, purchase_date, purchase_time, points FROM receipts WHERE id = 'b'`).Scan(&retailer, &purchaseDate, &purchaseTime, &points)
+	if retailer != "Target" || purchaseDate != "2022-01-01" || purchaseTime != "13:01" || points != 28 {
+		t.Fatalf("receipts table: unexpected row ( %s, %s, %s, %d )", retailer, purchaseDate, purchaseTime, points)
+	}
+	var totalCents int64
+	db.QueryRow(`SELECT total_cents FROM receipts WHERE id = 'b'`).Scan(&totalCents)
+	if totalCents != storedReceipts[0].Receipt.Total.Cents() {
+		t.Fatalf("receipts table: expected total_cents ( %d ) got ( %d )", storedReceipts[0].Receipt.Total.Cents(), totalCents)
+	}
+	var itemCount int
+	db.QueryRow(`SELECT COUNT(*) FROM receipt_items WHERE receipt_id = 'b'`).Scan(&itemCount)
+	if itemCount != len(storedReceipts[0].Receipt.Items) {
+		t.Fatalf("receipt_items table: expected %d items got %d", len(storedReceipts[0].Receipt.Items), itemCount)
+	}
+}
+
+// Receipts stored before the ruleset version was recorded were scored with the original rules
+func Test_SQLStoreMigratesRulesetVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "receipts.db")
+	db, _ := sql.Open("sqlite", path)
+	defer db.Close()
+	oldStore := &SQLStore{db: db}
+	oldStore.db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, description TEXT NOT NULL, applied_at TEXT NOT NULL)`)
+	oldStore.applyMigration(migrations[0])
+	oldStore.applyMigration(migrations[1])
+	db.Exec(`INSERT INTO receipts (id, retailer, purchase_date, purchase_time, total, points) VALUES ('old', 'Target', '2022-01-01', '13:01', 1.00, 6)`)
+
+	sqlStore, err := OpenSQLStore(db)
+	if err != nil {
+		t.Fatalf("migrate: unexpected error ( %v )", err)
+	}
+	storedReceipt, err := sqlStore.Get("old")
+	if err != nil || storedReceipt.RulesetVersion != 1 {
+		t.Fatalf("migrate: expected existing receipt to be given ruleset version ( 1 ) got ( %d ) with error ( %v )", storedReceipt.RulesetVersion, err)
+	}
+}
+
+// Totals and prices stored as floating point before they were stored as cents should be converted exactly
+func Test_SQLStoreMigratesAmountsToCents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "receipts.db")
+	db, _ := sql.Open("sqlite", path)
+	defer db.Close()
+	oldStore := &SQLStore{db: db}
+	oldStore.db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, description TEXT NOT NULL, applied_at TEXT NOT NULL)`)
+	for _, migration := range migrations[:3] {
+		oldStore.applyMigration(migration)
+	}
+	db.Exec(`INSERT INTO receipts (id, retailer, purchase_date, purchase_time, total, points) VALUES ('old', 'Target', '2022-01-01', '13:01', 35.35, 6)`)
+	db.Exec(`INSERT INTO receipt_items (receipt_id, position, short_description, price) VALUES ('old', 0, 'Gatorade', 35.35)`)
+
+	sqlStore, err := OpenSQLStore(db)
+	if err != nil {
+		t.Fatalf("migrate: unexpected error ( %v )", err)
+	}
+	storedReceipt, err := sqlStore.Get("old")
+	if err != nil || storedReceipt.Receipt.Total.Cents() != 3535 || len(storedReceipt.Receipt.Items) != 1 || storedReceipt.Receipt.Items[0].Price.Cents() != 3535 {
+		t.Fatalf("migrate: expected total and price of ( 3535 ) cents got %+v with error ( %v )", storedReceipt.Receipt, err)
+	}
+	if storedReceipt.Receipt.Currency != money.USD {
+		t.Fatalf("migrate: expected existing receipt to be given currency ( USD ) got ( %s )", storedReceipt.Receipt.Currency)
+	}
+}
+
+// Receipts stored with the same content before only one was allowed should be matched to the first of them
+func Test_SQLStoreMigratesContentHashes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "receipts.db")
+	db, _ := sql.Open("sqlite", path)
+	defer db.Close()
+	oldStore := &SQLStore{db: db}
+	oldStore.db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, description TEXT NOT NULL, applied_at TEXT NOT NULL)`)
+	for _, migration := range migrations[:10] {
+		oldStore.applyMigration(migration)
+	}
+	for _, id := range []string{"first", "second", "unhashed"} {
+		contentHash := "hash-1"
+		if id == "unhashed" {
+			contentHash = ""
+		}
+		db.Exec(`INSERT INTO receipts (id, retailer, purchase_date, purchase_time, total_cents, points, content_hash) VALUES (?, 'Target', '2022-01-01', '13:01', 100, 6, ?)`, id, contentHash)
+	}
+
+	sqlStore, err := OpenSQLStore(db)
+	if err != nil {
+		t.Fatalf("migrate: unexpected error ( %v )", err)
+	}
+	result, duplicate, err := sqlStore.PutIfAbsent(newSubmittedReceipt("new", "hash-1", ""))
+	if err != nil || !duplicate || result.Receipt.Id != "first" {
+		t.Fatalf("put if absent ( new ): expected receipt ( first ) as a duplicate got ( %s ) duplicate ( %t ) error ( %v )", result.Receipt.Id, duplicate, err)
+	}
+	var hashCount int
+	db.QueryRow(`SELECT COUNT(*) FROM receipt_content_hashes`).Scan(&hashCount)
+	if hashCount != 1 {
+		t.Fatalf("receipt_content_hashes table: expected 1 content hash got %d", hashCount)
+	}
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"errors"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
+	receipt "go-receipt-processor/Receipt"
+	systime "time"
+)
+
+var (
+	ErrReceiptNotFound = errors.New("no receipt found for id")
+	ErrEmptyReceiptId  = errors.New("attempting to store receipt with an empty id")
+)
+
+// A receipt that has already been parsed and validated, along with the number of points it was awarded, the version of the points rules that awarded them,
+// and the exchange rate its amounts were converted with before being scored, if they were.
+type StoredReceipt struct {
+	Receipt        receipt.Receipt
+	Points         int64
+	RulesetVersion int
+	ExchangeRate   *exchangerate.Rate
+	ContentHash    string // the receipt's ContentHash, used to recognize the same receipt being submitted again
+	IdempotencyKey string // the key the client submitted the receipt with, if it gave one
+	UserId         string // the user who submitted the receipt, empty when it was submitted without one
+	Version        int    // 1 when the receipt is first stored, counting up each time it is amended
+}
+
+// The version of the receipt, where receipts stored before versions were recorded count as the first
+func (s StoredReceipt) EffectiveVersion() int {
+	if s.Version == 0 {
+		return 1
+	}
+	return s.Version
+}
+
+// An earlier version of a receipt, kept in its history when the receipt was amended or deleted
+type ReceiptRevision struct {
+	StoredReceipt
+	Deleted   bool         // the version was deleted, rather than replaced by a newer one
+	RevisedAt systime.Time // when the version was replaced or deleted
+}
+
+// Backing storage for processed receipts. Implementations are keyed by the receipt's Id and are expected to be safe to swap out behind the API server.
+type ReceiptStore interface {
+	// Stores the receipt and its points, replacing any existing entry with the same id.
+	Put(storedReceipt StoredReceipt) error
+	// Stores the receipt unless one with the same idempotency key, or failing that the same content hash, is already stored, in which case nothing is stored and the existing receipt is returned along with true.
+	// An empty key or hash never matches. The check and the store happen atomically, so concurrent submissions of the same receipt store it only once.
+	PutIfAbsent(storedReceipt StoredReceipt) (StoredReceipt, bool, error)
+	// Returns the stored receipt for the id, or ErrReceiptNotFound if there is none.
+	Get(id string) (StoredReceipt, error)
+	// Returns every stored receipt, ordered by id.
+	List() ([]StoredReceipt, error)
+	// Returns a page of the stored receipts that match the query, ordered by id.
+	Search(query Query) (Page, error)
+	// Replaces the stored receipt with the same id by a corrected version, keeping the replaced version in the receipt's history, or returns ErrReceiptNotFound if there is none.
+	// The new version is numbered one after the replaced one and keeps its idempotency key and user, so retries of the original submission still find the receipt. Returns the replaced version followed by the new one.
+	Replace(storedReceipt StoredReceipt) (StoredReceipt, StoredReceipt, error)
+	// Removes the stored receipt for the id, keeping it in the receipt's history, and returns it, or returns ErrReceiptNotFound if there is none.
+	Delete(id string) (StoredReceipt, error)
+	// Returns the earlier versions of the receipt, oldest first, ending with the deleted version if the receipt was deleted. Returns ErrReceiptNotFound if the id was never stored.
+	History(id string) ([]ReceiptRevision, error)
+}
@@ -0,0 +1,299 @@
+package store
+
+import (
+	"errors"
+	date "go-receipt-processor/Date"
+	money "go-receipt-processor/Money"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
+	receipt "go-receipt-processor/Receipt"
+	receiptitem "go-receipt-processor/Receipt/ReceiptItem"
+	utils "go-receipt-processor/TestingUtils"
+	time "go-receipt-processor/Time"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var storedReceipts []StoredReceipt = []StoredReceipt{
+	{
+		Receipt: receipt.Receipt{Id: "b", Retailer: "Target", PurchaseDate: date.Date{Year: 2022, Month: 01, Day: 01}, PurchaseTime: time.Time{Hour: 13, Minute: 01}, Total: 3535, Currency: money.USD,
+			Items: []receiptitem.ReceiptItem{
+				{ShortDescription: "Mountain Dew 12PK", Price: 649},
+				{ShortDescription: "Emils Cheese Pizza", Price: 1225},
+				{ShortDescription: "Knorr Creamy Chicken", Price: 126},
+				{ShortDescription: "Doritos Nacho Cheese", Price: 335},
+				{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: 1200}}},
+		Points:         28,
+		RulesetVersion: 1,
+	},
+	{
+		Receipt: receipt.Receipt{Id: "a", Retailer: "M&M Corner Market", PurchaseDate: date.Date{Year: 2022, Month: 03, Day: 20}, PurchaseTime: time.Time{Hour: 14, Minute: 33, Second: 15}, Total: 900, Currency: money.CAD, TimeZone: time.UTC, StoreTimeZone: "America/Toronto",
+			Items: []receiptitem.ReceiptItem{
+				{ShortDescription: "Gatorade", Price: 225},
+				{ShortDescription: "Gatorade", Price: 225},
+				{ShortDescription: "Gatorade", Price: 225},
+				{ShortDescription: "Gatorade", Price: 225},
+			}},
+		Points:         109,
+		RulesetVersion: 2,
+		ExchangeRate:   &exchangerate.Rate{From: money.CAD, To: money.USD, EffectiveDate: "2022-03-01", Rate: "0.79"},
+	},
+}
+
+// Runs the same put / get / list / delete checks against any ReceiptStore implementation
+func testReceiptStore(t *testing.T, receiptStore ReceiptStore) {
+	for _, storedReceipt := range storedReceipts {
+		if err := receiptStore.Put(storedReceipt); err != nil {
+			t.Fatalf("put ( %+v ): unexpected error ( %v )", storedReceipt, err)
+		}
+	}
+	if err := receiptStore.Put(StoredReceipt{}); !errors.Is(err, ErrEmptyReceiptId) {
+		t.Fatalf("put ( empty id ): expected error ( %v ) got error ( %v )", ErrEmptyReceiptId, err)
+	}
+
+	var testCases []utils.CreationTestingData[string, StoredReceipt] = []utils.CreationTestingData[string, StoredReceipt]{
+		{Argument: "a", ExpectedResult: storedReceipts[1]},
+		{Argument: "b", ExpectedResult: storedReceipts[0]},
+		{Argument: "c", ExpectedResult: StoredReceipt{}, ExpectedErr: ErrReceiptNotFound},
+		{Argument: "", ExpectedResult: StoredReceipt{}, ExpectedErr: ErrReceiptNotFound},
+	}
+	for _, testCase := range testCases {
+		result, err := receiptStore.Get(testCase.Argument)
+		errCheck := testCase.CheckTestCase("get", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	listed, err := receiptStore.List()
+	if err != nil {
+		t.Fatalf("list: unexpected error ( %v )", err)
+	}
+	if !cmp.Equal(listed, []StoredReceipt{storedReceipts[1], storedReceipts[0]}) {
+		t.Fatalf("list: expected receipts ordered by id, got ( %+v )", listed)
+	}
+
+	if _, err := receiptStore.Delete("a"); err != nil {
+		t.Fatalf("delete ( a ): unexpected error ( %v )", err)
+	}
+	if _, err := receiptStore.Get("a"); err == nil {
+		t.Fatalf("get ( a ): expected receipt to be deleted")
+	}
+	if _, err := receiptStore.Delete("a"); err == nil {
+		t.Fatalf("delete ( a ): expected error when deleting a receipt twice")
+	}
+	listed, _ = receiptStore.List()
+	if !cmp.Equal(listed, []StoredReceipt{storedReceipts[0]}) {
+		t.Fatalf("list: expected only the remaining receipt, got ( %+v )", listed)
+	}
+
+	testPutIfAbsent(t, receiptStore)
+	testReplaceAndHistory(t, receiptStore)
+}
+
+// The id of the receipt PutIfAbsent returned, and whether it was an existing one
+type putIfAbsentOutcome struct {
+	Id        string
+	Duplicate bool
+}
+
+func newSubmittedReceipt(id string, contentHash string, idempotencyKey string) StoredReceipt {
+	return StoredReceipt{Receipt: receipt.Receipt{Id: id, Retailer: "Target", PurchaseDate: date.Date{Year: 2022, Month: 01, Day: 01}, PurchaseTime: time.Time{Hour: 13, Minute: 01}, Currency: money.USD, Items: []receiptitem.ReceiptItem{}}, Points: 6, RulesetVersion: 1, ContentHash: contentHash, IdempotencyKey: idempotencyKey}
+}
+
+func newUserReceipt(id string, contentHash string, idempotencyKey string, userId string) StoredReceipt {
+	storedReceipt := newSubmittedReceipt(id, contentHash, idempotencyKey)
+	storedReceipt.UserId = userId
+	return storedReceipt
+}
+
+// Submits receipts in order, each checked against everything submitted before it
+func testPutIfAbsent(t *testing.T, receiptStore ReceiptStore) {
+	var testCases []utils.CreationTestingData[StoredReceipt, putIfAbsentOutcome] = []utils.CreationTestingData[StoredReceipt, putIfAbsentOutcome]{
+		{Argument: newSubmittedReceipt("c", "hash-1", "key-1"), ExpectedResult: putIfAbsentOutcome{Id: "c"}},
+		{Argument: newSubmittedReceipt("d", "hash-1", ""), ExpectedResult: putIfAbsentOutcome{Id: "c", Duplicate: true}},
+		{Argument: newSubmittedReceipt("e", "hash-2", "key-1"), ExpectedResult: putIfAbsentOutcome{Id: "c", Duplicate: true}}, // the key matches even though the content does not
+		{Argument: newUserReceipt("e", "hash-2", "key-2", "alice"), ExpectedResult: putIfAbsentOutcome{Id: "e"}},
+		{Argument: newSubmittedReceipt("f", "hash-1", "key-2"), ExpectedResult: putIfAbsentOutcome{Id: "e", Duplicate: true}}, // the key is checked before the hash
+		{Argument: newSubmittedReceipt("g", "", ""), ExpectedResult: putIfAbsentOutcome{Id: "g"}},
+		{Argument: newSubmittedReceipt("h", "", ""), ExpectedResult: putIfAbsentOutcome{Id: "h"}}, // empty hashes and keys never match
+		{Argument: StoredReceipt{}, ExpectedResult: putIfAbsentOutcome{}, ExpectedErr: ErrEmptyReceiptId},
+	}
+	for _, testCase := range testCases {
+		result, duplicate, err := receiptStore.PutIfAbsent(testCase.Argument)
+		errCheck := testCase.CheckTestCase("put if absent", putIfAbsentOutcome{Id: result.Receipt.Id, Duplicate: duplicate}, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+	if stored, err := receiptStore.Get("c"); err != nil || !cmp.Equal(stored, newSubmittedReceipt("c", "hash-1", "key-1")) {
+		t.Fatalf("get ( c ): expected the receipt with its hash and key got ( %+v ) error ( %v )", stored, err)
+	}
+	// the user is stored in the same write as the receipt, so a duplicate is always returned with the user who submitted the original
+	if stored, duplicate, err := receiptStore.PutIfAbsent(newUserReceipt("k", "hash-2", "", "bob")); err != nil || !duplicate || stored.UserId != "alice" {
+		t.Fatalf("put if absent ( k ): expected receipt ( e ) submitted by ( alice ) as a duplicate got ( %+v ) duplicate ( %t ) error ( %v )", stored, duplicate, err)
+	}
+	if _, err := receiptStore.Get("d"); !errors.Is(err, ErrReceiptNotFound) {
+		t.Fatalf("get ( d ): expected the duplicate not to be stored got error ( %v )", err)
+	}
+
+	// once the original is deleted the same content can be stored again
+	receiptStore.Delete("c")
+	result, duplicate, err := receiptStore.PutIfAbsent(newSubmittedReceipt("i", "hash-1", "key-1"))
+	if err != nil || duplicate || result.Receipt.Id != "i" {
+		t.Fatalf("put if absent ( i ): expected a new receipt after the original was deleted got ( %s ) duplicate ( %t ) error ( %v )", result.Receipt.Id, duplicate, err)
+	}
+}
+
+// The version of each revision in a receipt's history, and whether it was deleted
+type revisionOutcome struct {
+	Version int
+	Deleted bool
+}
+
+func getRevisionOutcomes(t *testing.T, receiptStore ReceiptStore, id string) ([]revisionOutcome, error) {
+	revisions, err := receiptStore.History(id)
+	outcomes := []revisionOutcome{}
+	for _, revision := range revisions {
+		if revision.Receipt.Id != id || revision.RevisedAt.IsZero() {
+			t.Fatalf("history ( %s ): expected every revision to be of the receipt and have the time it was revised got ( %+v )", id, revision)
+		}
+		outcomes = append(outcomes, revisionOutcome{Version: revision.EffectiveVersion(), Deleted: revision.Deleted})
+	}
+	return outcomes, err
+}
+
+// Amends and deletes the receipts left by testPutIfAbsent, checking each one's history
+func testReplaceAndHistory(t *testing.T, receiptStore ReceiptStore) {
+	amended := newSubmittedReceipt("e", "hash-3", "")
+	amended.Receipt.Retailer = "Walgreens"
+	previous, replacement, err := receiptStore.Replace(amended)
+	if err != nil || previous.ContentHash != "hash-2" || replacement.Version != 2 || replacement.IdempotencyKey != "key-2" || replacement.UserId != "alice" {
+		t.Fatalf("replace ( e ): expected version 2 keeping key ( key-2 ) and user ( alice ) replacing hash ( hash-2 ) got ( %+v ) replacing ( %+v ) error ( %v )", replacement, previous, err)
+	}
+	if stored, err := receiptStore.Get("e"); err != nil || !cmp.Equal(stored, replacement) {
+		t.Fatalf("get ( e ): expected the amended receipt ( %+v ) got ( %+v ) error ( %v )", replacement, stored, err)
+	}
+	if _, replacement, _ = receiptStore.Replace(amended); replacement.Version != 3 {
+		t.Fatalf("replace ( e ): expected version 3 got %d", replacement.Version)
+	}
+	// retries of the original submission still find the amended receipt by its key
+	if result, duplicate, err := receiptStore.PutIfAbsent(newSubmittedReceipt("j", "hash-2", "key-2")); err != nil || !duplicate || result.Receipt.Id != "e" {
+		t.Fatalf("put if absent ( j ): expected receipt ( e ) as a duplicate got ( %s ) duplicate ( %t ) error ( %v )", result.Receipt.Id, duplicate, err)
+	}
+	if _, _, err := receiptStore.Replace(newSubmittedReceipt("missing", "", "")); !errors.Is(err, ErrReceiptNotFound) {
+		t.Fatalf("replace ( missing ): expected error ( %v ) got error ( %v )", ErrReceiptNotFound, err)
+	}
+	if _, _, err := receiptStore.Replace(StoredReceipt{}); !errors.Is(err, ErrEmptyReceiptId) {
+		t.Fatalf("replace ( empty id ): expected error ( %v ) got error ( %v )", ErrEmptyReceiptId, err)
+	}
+	if deleted, err := receiptStore.Delete("e"); err != nil || deleted.Version != 3 {
+		t.Fatalf("delete ( e ): expected version 3 to be deleted got ( %+v ) error ( %v )", deleted, err)
+	}
+
+	var testCases []utils.CreationTestingData[string, []revisionOutcome] = []utils.CreationTestingData[string, []revisionOutcome]{
+		{Argument: "e", ExpectedResult: []revisionOutcome{{Version: 1}, {Version: 2}, {Version: 3, Deleted: true}}},
+		{Argument: "a", ExpectedResult: []revisionOutcome{{Version: 1, Deleted: true}}},
+		{Argument: "b", ExpectedResult: []revisionOutcome{}},
+		{Argument: "missing", ExpectedResult: []revisionOutcome{}, ExpectedErr: ErrReceiptNotFound},
+	}
+	for _, testCase := range testCases {
+		result, err := getRevisionOutcomes(t, receiptStore, testCase.Argument)
+		errCheck := testCase.CheckTestCase("history", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+	if revisions, _ := receiptStore.History("a"); len(revisions) != 1 || !cmp.Equal(revisions[0].StoredReceipt, storedReceipts[1]) {
+		t.Fatalf("history ( a ): expected the deleted receipt ( %+v ) got ( %+v )", storedReceipts[1], revisions)
+	}
+}
+
+func Test_MemoryStore(t *testing.T) {
+	testReceiptStore(t, NewMemoryStore())
+}
+
+func Test_MemoryStoreConcurrentAccess(t *testing.T) {
+	receiptStore := NewMemoryStore()
+	var wg sync.WaitGroup
+	for worker := 0; worker < 16; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				id := strconv.Itoa(worker) + "-" + strconv.Itoa(i)
+				receiptStore.Put(StoredReceipt{Receipt: receipt.Receipt{Id: id}, Points: int64(i)})
+				receiptStore.Get(id)
+				receiptStore.List()
+				if i%2 == 0 {
+					receiptStore.Delete(id)
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+	listed, _ := receiptStore.List()
+	if len(listed) != 16*50 {
+		t.Fatalf("concurrent access: expected %d stored receipts got %d", 16*50, len(listed))
+	}
+}
+
+// Each worker keeps moving its own receipts between retailers while searching, so the indexes are written for many shards at once
+func Test_MemoryStoreConcurrentIndexing(t *testing.T) {
+	receiptStore := NewMemoryStore()
+	retailers := []string{"Target", "Walgreens", "Costco"}
+	var wg sync.WaitGroup
+	for worker := 0; worker < 16; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				id := strconv.Itoa(worker) + "-" + strconv.Itoa(i)
+				receiptStore.Put(StoredReceipt{Receipt: receipt.Receipt{Id: id, Retailer: retailers[0]}})
+				for _, retailer := range retailers[1:] {
+					receiptStore.Replace(StoredReceipt{Receipt: receipt.Receipt{Id: id, Retailer: retailer}})
+					receiptStore.Search(Query{Retailer: retailer})
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+	for i, retailer := range retailers {
+		page, _ := receiptStore.Search(Query{Retailer: retailer})
+		expected := 0
+		if i == len(retailers)-1 {
+			expected = 16 * 50
+		}
+		if len(page.Receipts) != expected {
+			t.Fatalf("search ( %s ): expected %d receipts got %d", retailer, expected, len(page.Receipts))
+		}
+		if count := receiptStore.retailerIndex.count([]string{retailer}); count != expected {
+			t.Fatalf("retailer index ( %s ): expected %d ids got %d", retailer, expected, count)
+		}
+	}
+}
+
+// Has every worker submit the same receipts at once, checking each one is only stored by whichever worker got there first
+func testConcurrentPutIfAbsent(t *testing.T, receiptStore ReceiptStore, workers int, receipts int) {
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < receipts; i++ {
+				receiptStore.PutIfAbsent(newSubmittedReceipt(strconv.Itoa(worker)+"-"+strconv.Itoa(i), "hash-"+strconv.Itoa(i), ""))
+			}
+		}(worker)
+	}
+	wg.Wait()
+	listed, _ := receiptStore.List()
+	if len(listed) != receipts {
+		t.Fatalf("concurrent put if absent: expected each of the %d receipts stored once got %d stored", receipts, len(listed))
+	}
+}
+
+func Test_MemoryStoreConcurrentPutIfAbsent(t *testing.T) {
+	testConcurrentPutIfAbsent(t, NewMemoryStore(), 16, 100)
+}
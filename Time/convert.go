@@ -0,0 +1,31 @@
+package time
+
+import (
+	date "go-receipt-processor/Date"
+	systime "time"
+)
+
+// Returns the time on the clock in the moment's own location, dropping anything smaller than a second
+func FromStandard(moment systime.Time) Time {
+	return Time{Hour: uint8(moment.Hour()), Minute: uint8(moment.Minute()), Second: uint8(moment.Second())}
+}
+
+// Returns the date and time on the clock in the given zone at the moment, i.e. 2023-10-15 01:30 UTC is 2023-10-14 20:30 in America/Chicago.
+// The zero zone is treated as UTC.
+func FromStandardIn(moment systime.Time, zone Zone) (date.Date, Time) {
+	local := moment.In(zone.Location())
+	return date.FromStandard(local), FromStandard(local)
+}
+
+// Returns the moment the date and time refer to in the given zone, treating the zero zone as UTC
+func ToStandard(d date.Date, t Time, zone Zone) systime.Time {
+	return systime.Date(int(d.Year), systime.Month(d.Month), int(d.Day), int(t.Hour), int(t.Minute), int(t.Second), 0, zone.Location())
+}
+
+// Returns the zone of a standard library location, which must either be an IANA zone or a fixed offset, since the server's local zone means something different on every machine
+func ZoneFromLocation(location *systime.Location) (Zone, error) {
+	if location == nil {
+		return UTC, nil
+	}
+	return ParseZone(location.String())
+}
@@ -0,0 +1,77 @@
+package time
+
+import (
+	date "go-receipt-processor/Date"
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+	systime "time"
+)
+
+func Test_FromStandard(t *testing.T) {
+	var testCases []utils.CreationTestingData[systime.Time, Time] = []utils.CreationTestingData[systime.Time, Time]{
+		{Argument: systime.Date(2023, 10, 15, 15, 30, 0, 0, systime.UTC), ExpectedResult: Time{Hour: 15, Minute: 30}},
+		{Argument: systime.Date(2023, 10, 15, 15, 30, 12, 999999999, systime.UTC), ExpectedResult: Time{Hour: 15, Minute: 30, Second: 12}},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("time from standard", FromStandard(testCase.Argument), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+type standardArguments struct {
+	Date date.Date
+	Time Time
+	Zone Zone
+}
+
+func Test_ToStandard(t *testing.T) {
+	var testCases []utils.CreationTestingData[standardArguments, systime.Time] = []utils.CreationTestingData[standardArguments, systime.Time]{
+		{
+			Argument:       standardArguments{Date: date.Date{Year: 2023, Month: 10, Day: 15}, Time: Time{Hour: 15, Minute: 30, Second: 12}, Zone: ""},
+			ExpectedResult: systime.Date(2023, 10, 15, 15, 30, 12, 0, systime.UTC),
+		},
+		{
+			Argument:       standardArguments{Date: date.Date{Year: 2023, Month: 10, Day: 15}, Time: Time{Hour: 15, Minute: 30}, Zone: "America/Chicago"},
+			ExpectedResult: systime.Date(2023, 10, 15, 20, 30, 0, 0, systime.UTC),
+		},
+		{
+			Argument:       standardArguments{Date: date.Date{Year: 2023, Month: 10, Day: 15}, Time: Time{Hour: 1, Minute: 0}, Zone: "+05:30"},
+			ExpectedResult: systime.Date(2023, 10, 14, 19, 30, 0, 0, systime.UTC),
+		},
+	}
+	for _, testCase := range testCases {
+		result := ToStandard(testCase.Argument.Date, testCase.Argument.Time, testCase.Argument.Zone).UTC()
+		errCheck := testCase.CheckTestCase("to standard", result, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+		//converting back into the same zone gives the date and time that were started with
+		resultDate, resultTime := FromStandardIn(result, testCase.Argument.Zone)
+		if resultDate != testCase.Argument.Date || resultTime != testCase.Argument.Time {
+			t.Fatalf("from standard in ( %v, %s ): expected ( %s %s ) got ( %s %s )", result, testCase.Argument.Zone, testCase.Argument.Date, testCase.Argument.Time, resultDate, resultTime)
+		}
+	}
+}
+
+func Test_ZoneFromLocation(t *testing.T) {
+	chicago, err := systime.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("load location: unexpected error ( %v )", err)
+	}
+	var testCases []utils.CreationTestingData[*systime.Location, Zone] = []utils.CreationTestingData[*systime.Location, Zone]{
+		{Argument: chicago, ExpectedResult: "America/Chicago"},
+		{Argument: systime.UTC, ExpectedResult: UTC},
+		{Argument: nil, ExpectedResult: UTC},
+		{Argument: systime.FixedZone("-07:00", -7*60*60), ExpectedResult: "-07:00"},
+		{Argument: systime.Local, ExpectedResult: "", ExpectedErr: ErrInvalidTimeZone},
+	}
+	for _, testCase := range testCases {
+		result, err := ZoneFromLocation(testCase.Argument)
+		errCheck := testCase.CheckTestCase("zone from location", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package time
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	systime "time"
+)
+
+var (
+	ErrScanningTime = errors.New("scanning time")
+)
+
+// Times are written as "HH:MM" strings in json, or "HH:MM:SS" when they have seconds
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// Reads an "HH:MM" or "HH:MM:SS" string, or the {"Hour", "Minute"} object times were written as before they had their own json format, so previously persisted receipts can still be read
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		type legacyTime Time // has none of Time's methods, so is decoded field by field
+		var legacy legacyTime
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return fmt.Errorf("%w from %s ... %w", ErrParsingTime, data, err)
+		}
+		*t = Time(legacy)
+		return nil
+	}
+	var timeString string
+	if err := json.Unmarshal(data, &timeString); err != nil {
+		return fmt.Errorf("%w from %s ... %w", ErrParsingTime, data, err)
+	}
+	return t.UnmarshalText([]byte(timeString))
+}
+
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// Reads an "HH:MM" or "HH:MM:SS" string, rejecting times that do not exist
+func (t *Time) UnmarshalText(text []byte) error {
+	parsed, err := ParseTime(string(text), true)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// Stores the time as "HH:MM" or "HH:MM:SS" text, which sorts in the same order as the times
+func (t Time) Value() (driver.Value, error) {
+	return t.String(), nil
+}
+
+// Reads a time stored as text, or as a timestamp by drivers that convert time columns themselves.
+// Stored times are not validated again, so rows written by older versions are still readable.
+func (t *Time) Scan(src any) error {
+	switch value := src.(type) {
+	case string:
+		return t.scanString(value)
+	case []byte:
+		return t.scanString(string(value))
+	case systime.Time:
+		*t = FromStandard(value)
+		return nil
+	default:
+		return fmt.Errorf("%w given a %T ( times can only be read from text or timestamps )", ErrScanningTime, src)
+	}
+}
+
+func (t *Time) scanString(timeString string) error {
+	parsed, err := ParseTime(timeString, false)
+	if err != nil {
+		return fmt.Errorf("%w ... %w", ErrScanningTime, err)
+	}
+	*t = parsed
+	return nil
+}
@@ -0,0 +1,86 @@
+package time
+
+import (
+	"encoding/json"
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+	systime "time"
+)
+
+func Test_TimeMarshalJSON(t *testing.T) {
+	var testCases []utils.CreationTestingData[Time, string] = []utils.CreationTestingData[Time, string]{
+		{Argument: Time{Hour: 9, Minute: 5}, ExpectedResult: `"09:05"`},
+		{Argument: Time{Hour: 15, Minute: 30, Second: 12}, ExpectedResult: `"15:30:12"`},
+	}
+	for _, testCase := range testCases {
+		result, err := json.Marshal(testCase.Argument)
+		errCheck := testCase.CheckTestCase("marshal time json", string(result), err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_TimeUnmarshalJSON(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, Time] = []utils.CreationTestingData[string, Time]{
+		{Argument: `"09:05"`, ExpectedResult: Time{Hour: 9, Minute: 5}},
+		{Argument: `"15:30:12"`, ExpectedResult: Time{Hour: 15, Minute: 30, Second: 12}},
+		{Argument: `null`, ExpectedResult: Time{}},
+		//Tests that times persisted before they had their own json format can still be read
+		{Argument: `{"Hour": 14, "Minute": 33}`, ExpectedResult: Time{Hour: 14, Minute: 33}},
+		//Tests invalid times will correctly throw an error
+		{Argument: `"24:00"`, ExpectedResult: Time{}, ExpectedErr: ErrInvalidTime},
+		{Argument: `"3:30 PM"`, ExpectedResult: Time{}, ExpectedErr: ErrInvalidTimeSyntax},
+		{Argument: `1530`, ExpectedResult: Time{}, ExpectedErr: ErrParsingTime},
+	}
+	for _, testCase := range testCases {
+		var result Time
+		err := json.Unmarshal([]byte(testCase.Argument), &result)
+		errCheck := testCase.CheckTestCase("unmarshal time json", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+func Test_TimeText(t *testing.T) {
+	var testCases []utils.CreationTestingData[Time, string] = []utils.CreationTestingData[Time, string]{
+		{Argument: Time{Hour: 9, Minute: 5}, ExpectedResult: "09:05"},
+		{Argument: Time{Hour: 23, Minute: 59, Second: 59}, ExpectedResult: "23:59:59"},
+	}
+	for _, testCase := range testCases {
+		text, err := testCase.Argument.MarshalText()
+		errCheck := testCase.CheckTestCase("marshal time text", string(text), err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+		var decoded Time
+		if err := decoded.UnmarshalText(text); err != nil || decoded != testCase.Argument {
+			t.Fatalf("unmarshal time text ( %s ): expected ( %+v ) got ( %+v ) with error ( %v )", text, testCase.Argument, decoded, err)
+		}
+	}
+}
+
+func Test_TimeScan(t *testing.T) {
+	var testCases []utils.CreationTestingData[any, Time] = []utils.CreationTestingData[any, Time]{
+		{Argument: "15:30", ExpectedResult: Time{Hour: 15, Minute: 30}},
+		{Argument: []byte("15:30:12"), ExpectedResult: Time{Hour: 15, Minute: 30, Second: 12}},
+		{Argument: systime.Date(2023, 10, 15, 15, 30, 12, 0, systime.UTC), ExpectedResult: Time{Hour: 15, Minute: 30, Second: 12}},
+		{Argument: "3:30 PM", ExpectedResult: Time{}, ExpectedErr: ErrScanningTime},
+		{Argument: nil, ExpectedResult: Time{}, ExpectedErr: ErrScanningTime},
+		{Argument: 1530.0, ExpectedResult: Time{}, ExpectedErr: ErrScanningTime},
+	}
+	for _, testCase := range testCases {
+		var result Time
+		err := result.Scan(testCase.Argument)
+		errCheck := testCase.CheckTestCase("scan time", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	value, err := Time{Hour: 15, Minute: 30, Second: 12}.Value()
+	if err != nil || value != "15:30:12" {
+		t.Fatalf("time value: expected ( 15:30:12 ) got ( %v ) with error ( %v )", value, err)
+	}
+}
@@ -0,0 +1,140 @@
+package time
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownLayout = errors.New("unknown time layout")
+
+	layoutDefinitions = map[Layout]layoutDefinition{
+		LayoutHourMinute:             {pattern: regexp.MustCompile(`^(\d{1,2}):(\d{2})$`), build: build24Hour},
+		LayoutHourMinuteSecond:       {pattern: regexp.MustCompile(`^(\d{1,2}):(\d{2}):(\d{2})$`), build: build24Hour},
+		LayoutTwelveHourMinute:       {pattern: regexp.MustCompile(`^(\d{1,2}):(\d{2}) ?([AaPp])\.?[Mm]\.?$`), build: build12Hour},
+		LayoutTwelveHourMinuteSecond: {pattern: regexp.MustCompile(`^(\d{1,2}):(\d{2}):(\d{2}) ?([AaPp])\.?[Mm]\.?$`), build: build12Hour},
+	}
+)
+
+// Names a way of writing a time that can be parsed, written the same way it is given in configuration
+type Layout string
+
+const (
+	LayoutHourMinute             Layout = "HH:MM"
+	LayoutHourMinuteSecond       Layout = "HH:MM:SS"
+	LayoutTwelveHourMinute       Layout = "h:MM AM"    // i.e. "3:30 PM", "3:30pm" or "3:30 p.m."
+	LayoutTwelveHourMinuteSecond Layout = "h:MM:SS AM" // i.e. "3:30:12 PM"
+)
+
+type layoutDefinition struct {
+	pattern *regexp.Regexp
+	build   func(match []string) (Time, error)
+}
+
+// The layouts times are accepted in, tried in order until one matches
+type Formats struct {
+	Layouts []Layout
+}
+
+// Accepts every layout
+var DefaultFormats = Formats{Layouts: []Layout{LayoutHourMinute, LayoutHourMinuteSecond, LayoutTwelveHourMinute, LayoutTwelveHourMinuteSecond}}
+
+func ParseLayout(layoutString string) (Layout, error) {
+	layout := Layout(strings.Trim(layoutString, " "))
+	if _, isKnown := layoutDefinitions[layout]; !isKnown {
+		return "", fmt.Errorf("%w given \"%s\" ( valid layouts are %s )", ErrUnknownLayout, layoutString, describeLayouts(DefaultFormats.Layouts))
+	}
+	return layout, nil
+}
+
+// Parses a comma separated list of layouts, i.e. "HH:MM, h:MM AM"
+func ParseFormats(layoutsString string) (Formats, error) {
+	formats := Formats{}
+	for _, layoutString := range strings.Split(layoutsString, ",") {
+		layout, err := ParseLayout(layoutString)
+		if err != nil {
+			return Formats{}, err
+		}
+		formats.Layouts = append(formats.Layouts, layout)
+	}
+	return formats, nil
+}
+
+func describeLayouts(layouts []Layout) string {
+	names := make([]string, 0, len(layouts))
+	for _, layout := range layouts {
+		names = append(names, string(layout))
+	}
+	return strings.Join(names, ", ")
+}
+
+func parseTimeComponent(component string, componentErr error) (uint8, error) {
+	value, err := strconv.ParseUint(component, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%w given %s ... %w", componentErr, component, err)
+	}
+	return uint8(value), nil
+}
+
+func build24Hour(match []string) (Time, error) {
+	time := Time{}
+	hour, hourErr := parseTimeComponent(match[1], ErrParsingHour)
+	time.Hour = hour
+	minute, minuteErr := parseTimeComponent(match[2], ErrParsingMinute)
+	time.Minute = minute
+	var secondErr error = nil
+	if len(match) > 3 {
+		time.Second, secondErr = parseTimeComponent(match[3], ErrParsingSecond)
+	}
+	if err := errors.Join(hourErr, minuteErr, secondErr); err != nil {
+		return time, fmt.Errorf("%w from %s ... %w", ErrParsingTime, match[0], err)
+	}
+	return time, nil
+}
+
+// Converts a 12 hour time into the 24 hour time it is stored as, where 12 AM is midnight and 12 PM is noon
+func build12Hour(match []string) (Time, error) {
+	meridiem := strings.ToUpper(match[len(match)-1])
+	time, err := build24Hour(match[:len(match)-1])
+	if err != nil {
+		return time, err
+	}
+	if time.Hour < 1 || time.Hour > 12 {
+		return time, fmt.Errorf("%w %w given \"%s\" ( valid values range inclusively from 1 to 12 for a 12 hour time )", ErrInvalidTime, ErrInvalidHour, match[0])
+	}
+	time.Hour = time.Hour % 12
+	if meridiem == "P" {
+		time.Hour += 12
+	}
+	return time, nil
+}
+
+// Parses the time using the first layout that matches it, storing it as a 24 hour time
+func (f Formats) Parse(timeString string, validateResults bool) (Time, error) {
+	trimmed := strings.Trim(timeString, " ")
+	if trimmed == "" {
+		return Time{}, ErrEmptyTimeString
+	}
+	for _, layout := range f.Layouts {
+		definition, isKnown := layoutDefinitions[layout]
+		if !isKnown {
+			continue
+		}
+		match := definition.pattern.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+		time, err := definition.build(match)
+		if err != nil {
+			return time, err
+		}
+		if validateResults {
+			return time, time.IsValid()
+		}
+		return time, nil
+	}
+	return Time{}, fmt.Errorf("%w given \"%s\" ( accepted formats are %s )", ErrInvalidTimeSyntax, timeString, describeLayouts(f.Layouts))
+}
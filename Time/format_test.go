@@ -0,0 +1,57 @@
+package time
+
+import (
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+)
+
+func Test_FormatsParse(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, Time] = []utils.CreationTestingData[string, Time]{
+		{Argument: "15:30", ExpectedResult: Time{Hour: 15, Minute: 30}},
+		{Argument: "5:30", ExpectedResult: Time{Hour: 5, Minute: 30}},
+		{Argument: "15:30:12", ExpectedResult: Time{Hour: 15, Minute: 30, Second: 12}},
+		{Argument: "3:30 PM", ExpectedResult: Time{Hour: 15, Minute: 30}},
+		{Argument: "3:30pm", ExpectedResult: Time{Hour: 15, Minute: 30}},
+		{Argument: "03:30 a.m.", ExpectedResult: Time{Hour: 3, Minute: 30}},
+		{Argument: "3:30:12 PM", ExpectedResult: Time{Hour: 15, Minute: 30, Second: 12}},
+		//Tests midnight and noon, which are written as 12 in 12 hour times
+		{Argument: "12:05 AM", ExpectedResult: Time{Hour: 0, Minute: 5}},
+		{Argument: "12:05 PM", ExpectedResult: Time{Hour: 12, Minute: 5}},
+		//Tests that invalid times will correctly throw an error
+		{Argument: "", ExpectedResult: Time{}, ExpectedErr: ErrEmptyTimeString},
+		{Argument: "0:30 AM", ExpectedResult: Time{Hour: 0, Minute: 30}, ExpectedErr: ErrInvalidHour},
+		{Argument: "13:30 PM", ExpectedResult: Time{Hour: 13, Minute: 30}, ExpectedErr: ErrInvalidHour},
+		{Argument: "15:30:60", ExpectedResult: Time{Hour: 15, Minute: 30, Second: 60}, ExpectedErr: ErrInvalidSecond},
+		{Argument: "24:00", ExpectedResult: Time{Hour: 24, Minute: 0}, ExpectedErr: ErrInvalidHour},
+		{Argument: "3:30 XM", ExpectedResult: Time{}, ExpectedErr: ErrInvalidTimeSyntax},
+		{Argument: "15:30:1", ExpectedResult: Time{}, ExpectedErr: ErrInvalidTimeSyntax},
+		{Argument: "1530", ExpectedResult: Time{}, ExpectedErr: ErrInvalidTimeSyntax},
+	}
+	for _, testCase := range testCases {
+		result, err := DefaultFormats.Parse(testCase.Argument, true)
+		errCheck := testCase.CheckTestCase("parse time with formats", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+
+	onlyTwentyFourHour := Formats{Layouts: []Layout{LayoutHourMinute}}
+	if _, err := onlyTwentyFourHour.Parse("3:30 PM", true); err == nil {
+		t.Fatalf("parse time with formats: expected \"3:30 PM\" to be rejected when only %s is accepted", LayoutHourMinute)
+	}
+}
+
+func Test_ParseFormats(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, Formats] = []utils.CreationTestingData[string, Formats]{
+		{Argument: "HH:MM", ExpectedResult: Formats{Layouts: []Layout{LayoutHourMinute}}},
+		{Argument: "HH:MM, h:MM AM", ExpectedResult: Formats{Layouts: []Layout{LayoutHourMinute, LayoutTwelveHourMinute}}},
+		{Argument: "HH-MM", ExpectedResult: Formats{}, ExpectedErr: ErrUnknownLayout},
+	}
+	for _, testCase := range testCases {
+		result, err := ParseFormats(testCase.Argument)
+		errCheck := testCase.CheckTestCase("parse formats", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
@@ -14,63 +14,99 @@ var (
 	ErrParsingTime   = errors.New("parsing time")
 	ErrParsingHour   = errors.New("parsing hour")
 	ErrParsingMinute = errors.New("parsing minute")
+	ErrParsingSecond = errors.New("parsing second")
 
 	ErrInvalidTimeSyntax = errors.New("invalid time syntax")
 
 	ErrInvalidTime   = errors.New("invalid time")
 	ErrInvalidHour   = errors.New("invalid hour")
 	ErrInvalidMinute = errors.New("invalid minute")
+	ErrInvalidSecond = errors.New("invalid second")
 )
 
 type Time struct { //assuming 24 hour time
 	Hour   uint8
 	Minute uint8
+	Second uint8
 }
 
 func (t *Time) Equals(other Time) bool {
-	return (t.Hour == other.Hour && t.Minute == other.Minute)
+	return (t.Hour == other.Hour && t.Minute == other.Minute && t.Second == other.Second)
+}
+
+// Returns -1 if the time is earlier in the day than the other time, 1 if it is later, and 0 if they are the same
+func (t Time) Compare(other Time) int {
+	seconds := int(t.Hour)*3600 + int(t.Minute)*60 + int(t.Second)
+	otherSeconds := int(other.Hour)*3600 + int(other.Minute)*60 + int(other.Second)
+	switch {
+	case seconds < otherSeconds:
+		return -1
+	case seconds > otherSeconds:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Formats the time as HH:MM, or HH:MM:SS when it has seconds, the same formats it is parsed from
+func (t Time) String() string {
+	if t.Second != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	}
+	return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
 }
 
 func (t Time) IsValid() error {
 	isHourValid := t.Hour < 24
 	isMinuteValid := t.Minute < 60
+	isSecondValid := t.Second < 60
 
-	if isHourValid && isMinuteValid {
+	if isHourValid && isMinuteValid && isSecondValid {
 		return nil
+	} else if isHourValid && isMinuteValid && !isSecondValid {
+		return fmt.Errorf("%w %w values provided: %+v ( valid values range inclusively from 0 to 59 )", ErrInvalidTime, ErrInvalidSecond, t)
 	} else if isHourValid && !isMinuteValid {
-		return fmt.Errorf("%w invalid minute values provided: %+v ( valid values range inclusively from 0 to 59 )", ErrInvalidTime, t)
+		return fmt.Errorf("%w %w values provided: %+v ( valid values range inclusively from 0 to 59 )", ErrInvalidTime, ErrInvalidMinute, t)
 	} else if !isHourValid && isMinuteValid {
-		return fmt.Errorf("%w invalid hour values provided: %+v ( valid values range inclusively from 0 to 23 )", ErrInvalidTime, t)
+		return fmt.Errorf("%w %w values provided: %+v ( valid values range inclusively from 0 to 23 )", ErrInvalidTime, ErrInvalidHour, t)
 	} else {
-		return fmt.Errorf("%w invalid hour and minute values provided: %+v ( valid hour values range inclusively from 0 to 23 and valid minute values range inclusively from 0 to 59 )", ErrInvalidTime, t)
+		return fmt.Errorf("%w %w and %w values provided: %+v ( valid hour values range inclusively from 0 to 23 and valid minute values range inclusively from 0 to 59 )", ErrInvalidTime, ErrInvalidHour, ErrInvalidMinute, t)
 	}
 }
 
-func inspectSyntaxAndSplitTimeString(timeString string) (string, string, error) {
+// Splits the time into its hour, minute and second, with the second left empty when the time is written as HH:MM
+func inspectSyntaxAndSplitTimeString(timeString string) (string, string, string, error) {
 	timeWithNoSpaces := strings.ReplaceAll(timeString, " ", "")
-	if len(timeWithNoSpaces) > 5 { // format should be 5 characters, HH:MM
-		return "", "", fmt.Errorf("%w given \"%s\", too many characters ( valid format is HH:MM )", ErrInvalidTimeSyntax, timeWithNoSpaces)
+	if len(timeWithNoSpaces) > 8 { // format should be at most 8 characters, HH:MM:SS
+		return "", "", "", fmt.Errorf("%w given \"%s\", too many characters ( valid format is HH:MM or HH:MM:SS )", ErrInvalidTimeSyntax, timeWithNoSpaces)
 	}
-	if strings.Count(timeWithNoSpaces, ":") != 1 { // format should only have 1 colon
-		return "", "", fmt.Errorf("%w given \"%s\", there should be exactly 1 colon ( valid format is HH:MM )", ErrInvalidTimeSyntax, timeWithNoSpaces)
+	colonCount := strings.Count(timeWithNoSpaces, ":")
+	if colonCount != 1 && colonCount != 2 { // format should have 1 colon, or 2 when there are seconds
+		return "", "", "", fmt.Errorf("%w given \"%s\", there should be 1 or 2 colons ( valid format is HH:MM or HH:MM:SS )", ErrInvalidTimeSyntax, timeWithNoSpaces)
 	}
 	splitStrings := strings.Split(timeWithNoSpaces, ":")
 
 	for _, splitStr := range splitStrings {
 		isNumeric := regexp.MustCompile(`^\d+$`).MatchString(splitStr)
 		if !isNumeric {
-			return "", "", fmt.Errorf("%w given \"%s\", only postive numeric characters are allowed ( valid format is HH:MM )", ErrInvalidTimeSyntax, timeWithNoSpaces)
+			return "", "", "", fmt.Errorf("%w given \"%s\", only postive numeric characters are allowed ( valid format is HH:MM or HH:MM:SS )", ErrInvalidTimeSyntax, timeWithNoSpaces)
 		}
 	}
 	hourLength := len(splitStrings[0])
 	minuteLength := len(splitStrings[1])
+	secondString := ""
+	if colonCount == 2 {
+		secondString = splitStrings[2]
+	}
 	switch {
 	case hourLength == 0 || hourLength > 2: // allows for hour to only be 1 digit long without throwing an error
-		return "", "", fmt.Errorf("%w given \"%s\", invalid number of digits for hour ( valid format is HH:MM )", ErrInvalidTimeSyntax, timeWithNoSpaces)
+		return "", "", "", fmt.Errorf("%w given \"%s\", invalid number of digits for hour ( valid format is HH:MM or HH:MM:SS )", ErrInvalidTimeSyntax, timeWithNoSpaces)
 	case minuteLength != 2:
-		return "", "", fmt.Errorf("%w given \"%s\", invalid number of digits for minute ( valid format is HH:MM )", ErrInvalidTimeSyntax, timeWithNoSpaces)
+		return "", "", "", fmt.Errorf("%w given \"%s\", invalid number of digits for minute ( valid format is HH:MM or HH:MM:SS )", ErrInvalidTimeSyntax, timeWithNoSpaces)
+	case colonCount == 2 && len(secondString) != 2:
+		return "", "", "", fmt.Errorf("%w given \"%s\", invalid number of digits for second ( valid format is HH:MM or HH:MM:SS )", ErrInvalidTimeSyntax, timeWithNoSpaces)
 	default:
-		return splitStrings[0], splitStrings[1], nil
+		return splitStrings[0], splitStrings[1], secondString, nil
 	}
 
 }
@@ -81,7 +117,7 @@ func ParseTime(timeString string, validateResults bool) (Time, error) {
 		return time, ErrEmptyTimeString
 	}
 
-	hourString, minuteString, syntaxErr := inspectSyntaxAndSplitTimeString(timeString)
+	hourString, minuteString, secondString, syntaxErr := inspectSyntaxAndSplitTimeString(timeString)
 	if syntaxErr != nil {
 		return time, syntaxErr
 	}
@@ -91,7 +127,7 @@ func ParseTime(timeString string, validateResults bool) (Time, error) {
 	if err == nil {
 		time.Hour = uint8(hourValue)
 	} else {
-		parsingHourErr = fmt.Errorf("%w given %s ... %s", ErrParsingHour, hourString, err.Error())
+		parsingHourErr = fmt.Errorf("%w given %s ... %w", ErrParsingHour, hourString, err)
 	}
 
 	var parsingMinuteErr error = nil
@@ -99,11 +135,21 @@ func ParseTime(timeString string, validateResults bool) (Time, error) {
 	if err == nil {
 		time.Minute = uint8(minuteValue)
 	} else {
-		parsingMinuteErr = fmt.Errorf("%w given %s ... %s", ErrParsingMinute, minuteString, err.Error())
+		parsingMinuteErr = fmt.Errorf("%w given %s ... %w", ErrParsingMinute, minuteString, err)
+	}
+
+	var parsingSecondErr error = nil
+	if secondString != "" {
+		secondValue, err := strconv.ParseUint(secondString, 10, 8)
+		if err == nil {
+			time.Second = uint8(secondValue)
+		} else {
+			parsingSecondErr = fmt.Errorf("%w given %s ... %w", ErrParsingSecond, secondString, err)
+		}
 	}
 
-	if parsingHourErr != nil || parsingMinuteErr != nil {
-		return time, fmt.Errorf("%w from %s ... %s", ErrParsingTime, timeString, errors.Join(parsingHourErr, parsingMinuteErr).Error())
+	if parsingHourErr != nil || parsingMinuteErr != nil || parsingSecondErr != nil {
+		return time, fmt.Errorf("%w from %s ... %w", ErrParsingTime, timeString, errors.Join(parsingHourErr, parsingMinuteErr, parsingSecondErr))
 	}
 	if validateResults {
 		return time, time.IsValid()
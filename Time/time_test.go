@@ -23,6 +23,10 @@ func Test_ParseTime(t *testing.T) {
 		{Argument: "12:60", ExpectedResult: Time{Hour: 12, Minute: 60}, ExpectedErr: ErrInvalidTime}, //Tests minute range ( 0 <= minute < 60)
 		{Argument: "18:61", ExpectedResult: Time{Hour: 18, Minute: 61}, ExpectedErr: ErrInvalidTime}, //Tests minute range ( 0 <= minute < 60)
 		{Argument: "13:99", ExpectedResult: Time{Hour: 13, Minute: 99}, ExpectedErr: ErrInvalidTime}, //Tests minute range ( 0 <= hour <= 60)
+		//Tests times with seconds
+		{Argument: "15:30:12", ExpectedResult: Time{Hour: 15, Minute: 30, Second: 12}, ExpectedErr: nil},
+		{Argument: "15:30:60", ExpectedResult: Time{Hour: 15, Minute: 30, Second: 60}, ExpectedErr: ErrInvalidSecond},
+		{Argument: "15:30:1", ExpectedResult: Time{}, ExpectedErr: ErrInvalidTimeSyntax},
 		//Tests inputs that have an invalid syntax will correctly throw an error
 		{Argument: "asdaf", ExpectedResult: Time{}, ExpectedErr: ErrInvalidTimeSyntax}, //Tests if alphabetic characters are given in input
 		{Argument: "a2s55", ExpectedResult: Time{}, ExpectedErr: ErrInvalidTimeSyntax}, //Tests if alpha-numeric characers are given in input
@@ -40,3 +44,18 @@ func Test_ParseTime(t *testing.T) {
 		}
 	}
 }
+
+func Test_Compare(t *testing.T) {
+	var testCases []utils.CreationTestingData[[2]Time, int] = []utils.CreationTestingData[[2]Time, int]{
+		{Argument: [2]Time{{Hour: 14, Minute: 33}, {Hour: 14, Minute: 33}}, ExpectedResult: 0},
+		{Argument: [2]Time{{Hour: 13, Minute: 59, Second: 59}, {Hour: 14}}, ExpectedResult: -1},
+		{Argument: [2]Time{{Hour: 14, Minute: 33, Second: 1}, {Hour: 14, Minute: 33}}, ExpectedResult: 1},
+		{Argument: [2]Time{{Hour: 9, Minute: 50}, {Hour: 10, Minute: 5}}, ExpectedResult: -1},
+	}
+	for _, testCase := range testCases {
+		errCheck := testCase.CheckTestCase("compare times", testCase.Argument[0].Compare(testCase.Argument[1]), nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
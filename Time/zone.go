@@ -0,0 +1,108 @@
+package time
+
+import (
+	"errors"
+	"fmt"
+	date "go-receipt-processor/Date"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	systime "time"
+)
+
+var (
+	ErrInvalidTimeZone = errors.New("invalid time zone")
+
+	utcOffsetRegex = regexp.MustCompile(`^(?:UTC|GMT)?([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+	// locations are loaded from the zoneinfo database once per zone, rather than every time a receipt is converted
+	locationCache sync.Map
+)
+
+// The zone a date and time are written in, either an IANA zone name ( i.e. "America/Chicago" ) or a fixed UTC offset ( i.e. "+05:30" ).
+// The zero value means no zone was given, and dates and times in it are never converted.
+type Zone string
+
+const UTC Zone = "UTC"
+
+// Parses either an IANA zone name, using the system's zoneinfo database, or a UTC offset written as "+05:30", "-0700", "+5", "UTC+1" or "Z".
+// Offsets are normalized to the form "+05:30".
+func ParseZone(zoneString string) (Zone, error) {
+	trimmed := strings.Trim(zoneString, " ")
+	switch strings.ToUpper(trimmed) {
+	case "":
+		return "", fmt.Errorf("%w given an empty string", ErrInvalidTimeZone)
+	case "Z", "UTC", "GMT":
+		return UTC, nil
+	case "LOCAL":
+		return "", fmt.Errorf("%w given \"%s\", the server's local zone can not be used ( valid zones are IANA names like America/Chicago or UTC offsets like -05:00 )", ErrInvalidTimeZone, zoneString)
+	}
+
+	if match := utcOffsetRegex.FindStringSubmatch(strings.ToUpper(trimmed)); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes, _ := strconv.Atoi("0" + match[3])
+		if hours > 14 || minutes > 59 {
+			return "", fmt.Errorf("%w given \"%s\", offsets range from -14:00 to +14:00", ErrInvalidTimeZone, zoneString)
+		}
+		return Zone(fmt.Sprintf("%s%02d:%02d", match[1], hours, minutes)), nil
+	}
+
+	if _, err := Zone(trimmed).location(); err != nil {
+		return "", fmt.Errorf("%w given \"%s\" ( valid zones are IANA names like America/Chicago or UTC offsets like -05:00 ) ... %w", ErrInvalidTimeZone, zoneString, err)
+	}
+	return Zone(trimmed), nil
+}
+
+func (z Zone) String() string {
+	return string(z)
+}
+
+func (z Zone) IsZero() bool {
+	return z == ""
+}
+
+func (z Zone) location() (*systime.Location, error) {
+	if cached, isCached := locationCache.Load(z); isCached {
+		return cached.(*systime.Location), nil
+	}
+	var location *systime.Location
+	if match := utcOffsetRegex.FindStringSubmatch(string(z)); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes, _ := strconv.Atoi("0" + match[3])
+		offset := hours*60*60 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+		location = systime.FixedZone(string(z), offset)
+	} else {
+		loaded, err := systime.LoadLocation(string(z))
+		if err != nil {
+			return nil, err
+		}
+		location = loaded
+	}
+	locationCache.Store(z, location)
+	return location, nil
+}
+
+// Returns the location used by the standard library for the zone, which is UTC for the zero value
+func (z Zone) Location() *systime.Location {
+	if z.IsZero() {
+		return systime.UTC
+	}
+	location, err := z.location()
+	if err != nil {
+		return systime.UTC // zones are checked when they are parsed, so this only happens if the zoneinfo database changes underneath the server
+	}
+	return location
+}
+
+// Converts a date and time written in one zone into the same moment written in another, i.e. 2023-10-15 01:30 in UTC is 2023-10-14 20:30 in America/Chicago.
+// Nothing is converted if either zone is the zero value, since there is no way to know how far apart they are.
+func Convert(d date.Date, t Time, from Zone, to Zone) (date.Date, Time) {
+	if from.IsZero() || to.IsZero() || from == to {
+		return d, t
+	}
+	return FromStandardIn(ToStandard(d, t, from), to)
+}
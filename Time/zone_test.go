@@ -0,0 +1,80 @@
+package time
+
+import (
+	date "go-receipt-processor/Date"
+	utils "go-receipt-processor/TestingUtils"
+	"testing"
+)
+
+func Test_ParseZone(t *testing.T) {
+	var testCases []utils.CreationTestingData[string, Zone] = []utils.CreationTestingData[string, Zone]{
+		{Argument: "America/Chicago", ExpectedResult: "America/Chicago", ExpectedErr: nil},
+		{Argument: " Europe/Berlin ", ExpectedResult: "Europe/Berlin", ExpectedErr: nil},
+		{Argument: "UTC", ExpectedResult: UTC, ExpectedErr: nil},
+		{Argument: "z", ExpectedResult: UTC, ExpectedErr: nil},
+		//Tests the ways a UTC offset can be written are all normalized to the same form
+		{Argument: "+05:30", ExpectedResult: "+05:30", ExpectedErr: nil},
+		{Argument: "+0530", ExpectedResult: "+05:30", ExpectedErr: nil},
+		{Argument: "-7", ExpectedResult: "-07:00", ExpectedErr: nil},
+		{Argument: "UTC+1", ExpectedResult: "+01:00", ExpectedErr: nil},
+		{Argument: "gmt-03:00", ExpectedResult: "-03:00", ExpectedErr: nil},
+		//Tests invalid zones will correctly throw an error
+		{Argument: "", ExpectedResult: "", ExpectedErr: ErrInvalidTimeZone},
+		{Argument: "Local", ExpectedResult: "", ExpectedErr: ErrInvalidTimeZone},
+		{Argument: "Mars/Olympus_Mons", ExpectedResult: "", ExpectedErr: ErrInvalidTimeZone},
+		{Argument: "+15:00", ExpectedResult: "", ExpectedErr: ErrInvalidTimeZone},
+		{Argument: "+05:60", ExpectedResult: "", ExpectedErr: ErrInvalidTimeZone},
+		{Argument: "+05:3", ExpectedResult: "", ExpectedErr: ErrInvalidTimeZone},
+	}
+	for _, testCase := range testCases {
+		result, err := ParseZone(testCase.Argument)
+		errCheck := testCase.CheckTestCase("parse zone", result, err, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
+
+type convertArguments struct {
+	Date date.Date
+	Time Time
+	From Zone
+	To   Zone
+}
+
+type convertResult struct {
+	Date date.Date
+	Time Time
+}
+
+func Test_Convert(t *testing.T) {
+	var testCases []utils.CreationTestingData[convertArguments, convertResult] = []utils.CreationTestingData[convertArguments, convertResult]{
+		{
+			Argument:       convertArguments{Date: date.Date{Year: 2023, Month: 10, Day: 15}, Time: Time{Hour: 1, Minute: 30}, From: UTC, To: "America/Chicago"},
+			ExpectedResult: convertResult{Date: date.Date{Year: 2023, Month: 10, Day: 14}, Time: Time{Hour: 20, Minute: 30}}, //daylight saving time, 5 hours behind
+		},
+		{
+			Argument:       convertArguments{Date: date.Date{Year: 2023, Month: 12, Day: 15}, Time: Time{Hour: 1, Minute: 30}, From: UTC, To: "America/Chicago"},
+			ExpectedResult: convertResult{Date: date.Date{Year: 2023, Month: 12, Day: 14}, Time: Time{Hour: 19, Minute: 30}}, //standard time, 6 hours behind
+		},
+		{
+			Argument:       convertArguments{Date: date.Date{Year: 2023, Month: 12, Day: 31}, Time: Time{Hour: 22, Minute: 0}, From: "-05:00", To: "+05:30"},
+			ExpectedResult: convertResult{Date: date.Date{Year: 2024, Month: 1, Day: 1}, Time: Time{Hour: 8, Minute: 30}},
+		},
+		{
+			Argument:       convertArguments{Date: date.Date{Year: 2024, Month: 3, Day: 1}, Time: Time{Hour: 0, Minute: 15}, From: "Europe/Berlin", To: UTC},
+			ExpectedResult: convertResult{Date: date.Date{Year: 2024, Month: 2, Day: 29}, Time: Time{Hour: 23, Minute: 15}},
+		},
+		{ //Tests that nothing is converted when a zone is missing
+			Argument:       convertArguments{Date: date.Date{Year: 2023, Month: 10, Day: 15}, Time: Time{Hour: 1, Minute: 30}, From: "", To: "America/Chicago"},
+			ExpectedResult: convertResult{Date: date.Date{Year: 2023, Month: 10, Day: 15}, Time: Time{Hour: 1, Minute: 30}},
+		},
+	}
+	for _, testCase := range testCases {
+		resultDate, resultTime := Convert(testCase.Argument.Date, testCase.Argument.Time, testCase.Argument.From, testCase.Argument.To)
+		errCheck := testCase.CheckTestCase("convert between zones", convertResult{Date: resultDate, Time: resultTime}, nil, false)
+		if errCheck != nil {
+			t.Fatalf("%s", errCheck.Error())
+		}
+	}
+}
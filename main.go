@@ -1,11 +1,124 @@
 package main
 
 import (
+	"database/sql"
+	"flag"
 	api "go-receipt-processor/API"
+	audit "go-receipt-processor/Audit"
+	cli "go-receipt-processor/CLI"
+	date "go-receipt-processor/Date"
+	ledger "go-receipt-processor/Ledger"
+	exchangerate "go-receipt-processor/Money/ExchangeRate"
+	points "go-receipt-processor/Points"
+	receipt "go-receipt-processor/Receipt"
+	store "go-receipt-processor/Store"
+	time "go-receipt-processor/Time"
+	"log"
 	"net/http"
+	"os"
+
+	_ "modernc.org/sqlite"
 )
 
 func main() {
-	server := api.NewServer()
+	if len(os.Args) > 1 && os.Args[1] == "score" {
+		os.Exit(cli.RunScore(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "verify-audit" {
+		os.Exit(cli.RunVerifyAudit(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	dataDirectory := flag.String("data-dir", "", "directory processed receipts are persisted to, if empty receipts are only kept in memory")
+	sqliteFile := flag.String("sqlite-file", "", "sqlite database file processed receipts are stored in, takes priority over -data-dir")
+	rulesetPath := flag.String("ruleset", "", "json or yaml ruleset file, or a directory with one file per ruleset version, containing the rules receipts are scored with. If empty the built-in rules are used")
+	exchangeRatesPath := flag.String("exchange-rates", "", "json or yaml file of dated exchange rates used to convert receipts into a single base currency before they are scored. If empty receipts are scored in their own currency")
+	dateFormats := flag.String("date-formats", "", "comma separated layouts purchase dates are accepted in, from YYYY-MM-DD, MM/DD/YYYY, DD/MM/YYYY, DD.MM.YYYY and Mon DD YYYY. If empty every layout is accepted")
+	dateOrder := flag.String("date-order", "", "month-first or day-first, used to read dates like 03/04/2023 that match more than one layout. If empty those dates are rejected as ambiguous")
+	timeFormats := flag.String("time-formats", "", "comma separated layouts purchase times are accepted in, from HH:MM, HH:MM:SS, h:MM AM and h:MM:SS AM. If empty every layout is accepted")
+	auditLogPath := flag.String("audit-log", "", "file every change to the stored receipts is appended to, with who made it and when. If empty the audit log is only kept in memory")
+	ledgerPath := flag.String("ledger-file", "", "file the points credited to, redeemed by and adjusted for each user are appended to. If empty the ledger is only kept in memory")
+	batchWorkers := flag.Int("batch-workers", 0, "how many receipts from a single batch are processed at once. If 0 the number of CPUs is used")
+	flag.Parse()
+
+	options := []api.ServerOption{}
+	if *rulesetPath != "" {
+		rulesets, err := points.LoadRulesetHistory(*rulesetPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, api.WithRulesets(rulesets))
+	}
+	if *exchangeRatesPath != "" {
+		exchangeRates, err := exchangerate.LoadTable(*exchangeRatesPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, api.WithExchangeRates(exchangeRates))
+	}
+	formats, err := parseFormats(*dateFormats, *dateOrder, *timeFormats)
+	if err != nil {
+		log.Fatal(err)
+	}
+	options = append(options, api.WithFormats(formats), api.WithBatchWorkers(*batchWorkers))
+	switch {
+	case *sqliteFile != "":
+		db, err := sql.Open("sqlite", store.SQLiteDataSourceName(*sqliteFile))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+		sqlStore, err := store.OpenSQLStore(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, api.WithStore(sqlStore))
+	case *dataDirectory != "":
+		fileStore, err := store.OpenFileStore(*dataDirectory)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer fileStore.Close()
+		options = append(options, api.WithStore(fileStore))
+	}
+	if *auditLogPath != "" {
+		auditLog, err := audit.OpenFileLog(*auditLogPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer auditLog.Close()
+		options = append(options, api.WithAuditLog(auditLog))
+	}
+	if *ledgerPath != "" {
+		pointsLedger, err := ledger.OpenFileLedger(*ledgerPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer pointsLedger.Close()
+		options = append(options, api.WithLedger(pointsLedger))
+	}
+	server := api.NewServer(options...)
 	http.ListenAndServe(":8080", server)
 }
+
+// Builds the layouts receipts are accepted in from the command line flags, using every layout for any flag that is left empty
+func parseFormats(dateFormats string, dateOrder string, timeFormats string) (receipt.Formats, error) {
+	formats := receipt.DefaultFormats
+	order, err := date.ParseDayOrder(dateOrder)
+	if err != nil {
+		return formats, err
+	}
+	formats.Date.Order = order
+	if dateFormats != "" {
+		formats.Date, err = date.ParseFormats(dateFormats, order)
+		if err != nil {
+			return formats, err
+		}
+	}
+	if timeFormats != "" {
+		formats.Time, err = time.ParseFormats(timeFormats)
+		if err != nil {
+			return formats, err
+		}
+	}
+	return formats, nil
+}